@@ -4,22 +4,122 @@ package asm
 import (
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/llir/llvm/asm/internal/ast"
+	"github.com/llir/llvm/asm/internal/astx"
 	"github.com/llir/llvm/asm/internal/irx"
 	"github.com/llir/llvm/asm/internal/lexer"
 	"github.com/llir/llvm/asm/internal/parser"
 	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/sem"
 	"github.com/pkg/errors"
 )
 
+// ParseOptions specifies the options used when parsing LLVM IR assembly.
+//
+// Parsing proceeds as a pipeline of stages; the LLVM IR assembly is parsed and
+// translated to an LLVM IR module, which is optionally checked for semantic
+// errors (see Check) and then handed to each user-provided pass in turn (see
+// Passes).
+type ParseOptions struct {
+	// FileName specifies the file name used in diagnostics. It defaults to the
+	// path of the file when parsing using ParseFileOptions.
+	FileName string
+	// LazyFuncBodies specifies whether to defer the parsing and translation of
+	// function bodies until first accessed.
+	//
+	// The byte range of each function body is recorded during parsing, and the
+	// basic blocks of the function are materialized on first access (see
+	// ir.Function.Materialize). Errors and warnings within function bodies are
+	// thus reported at materialization, subject to the same parse options.
+	//
+	// Printing, walking (see irutil.Walk), cloning and name lookup materialize
+	// function bodies implicitly. Code which reads the Blocks field of a
+	// function directly sees an empty body until the function is materialized.
+	LazyFuncBodies bool
+	// RetainComments specifies whether to retain the comments and blank lines
+	// of the LLVM IR assembly file, so that they are output when printing the
+	// module (see ir.Module.Comments).
+	//
+	// Comments are attached to the succeeding top-level entity, basic block,
+	// instruction or terminator, or to the preceding one if on the same line as
+	// its end.
+	RetainComments bool
+	// KeepPositions specifies whether to keep the source positions of the
+	// entities of the module (see ir.Module.Positions); i.e. the line and column
+	// of the first token of each top-level entity, basic block, instruction and
+	// terminator.
+	KeepPositions bool
+	// Parallelism specifies the maximum number of function bodies translated
+	// concurrently. Function bodies are translated sequentially if less than 2.
+	// The parsed module is identical regardless of the degree of parallelism.
+	Parallelism int
+	// Check specifies whether to perform static semantic analysis of the parsed
+	// module (see sem.Check).
+	Check bool
+	// MaxErrors specifies the maximum number of errors reported. All errors are
+	// reported if 0.
+	MaxErrors int
+	// Strictness specifies how unsupported constructs are handled.
+	Strictness Strictness
+	// Warn is invoked for each warning encountered during parsing; warnings are
+	// discarded if nil.
+	Warn func(err error)
+	// Passes specifies additional passes to run on the parsed module, in order.
+	// Parsing stops at the first pass which returns an error.
+	Passes []Pass
+}
+
+// A Pass is a user-provided stage of the parse pipeline, which is run on the
+// parsed LLVM IR module.
+type Pass func(m *ir.Module) error
+
+// Strictness specifies how unsupported constructs are handled during parsing.
+type Strictness uint8
+
+// Strictness levels.
+const (
+	// StrictnessSkip skips unsupported instructions (e.g. fence, va_arg) within
+	// function bodies, reporting them as warnings. Other unsupported constructs
+	// are reported as errors.
+	StrictnessSkip Strictness = iota
+	// StrictnessWarn skips function bodies containing unsupported constructs,
+	// reporting them as warnings. The functions are translated as function
+	// declarations.
+	StrictnessWarn
+	// StrictnessError reports unsupported constructs as errors.
+	StrictnessError
+)
+
+// ErrorList represents a list of errors encountered during parsing.
+type ErrorList []error
+
+// Error returns a string representation of the list of errors.
+func (es ErrorList) Error() string {
+	var errs []string
+	for _, e := range es {
+		errs = append(errs, e.Error())
+	}
+	return strings.Join(errs, "; ")
+}
+
 // ParseFile parses the given LLVM IR assembly file into an LLVM IR module.
 func ParseFile(path string) (*ir.Module, error) {
+	return ParseFileOptions(path, ParseOptions{})
+}
+
+// ParseFileOptions parses the given LLVM IR assembly file into an LLVM IR
+// module, based on the given parse options.
+func ParseFileOptions(path string, opts ParseOptions) (*ir.Module, error) {
 	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return ParseBytes(buf)
+	if len(opts.FileName) == 0 {
+		opts.FileName = path
+	}
+	return ParseBytesOptions(buf, opts)
 }
 
 // Parse parses the given LLVM IR assembly file into an LLVM IR module, reading
@@ -35,14 +135,60 @@ func Parse(r io.Reader) (*ir.Module, error) {
 // ParseBytes parses the given LLVM IR assembly file into an LLVM IR module,
 // reading from b.
 func ParseBytes(b []byte) (*ir.Module, error) {
-	module, err := parseBytes(b)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
+	return ParseBytesOptions(b, ParseOptions{})
+}
+
+// ParseBytesOptions parses the given LLVM IR assembly file into an LLVM IR
+// module, reading from b, based on the given parse options.
+func ParseBytesOptions(b []byte, opts ParseOptions) (*ir.Module, error) {
 	// Translate the AST of the module to an equivalent LLVM IR module.
-	m, err := irx.Translate(module)
+	gen := irx.NewModule()
+	gen.Parallelism = opts.Parallelism
+	gen.SkipUnsupported = opts.Strictness == StrictnessWarn
+	gen.SkipUnsupportedInsts = opts.Strictness == StrictnessSkip
+	var sc *commentScan
+	var materialized func(f *ir.Function)
+	if opts.RetainComments || opts.KeepPositions {
+		if s, ok := scanComments(b); ok {
+			s.retainComments = opts.RetainComments
+			s.keepPositions = opts.KeepPositions
+			s.fileName = opts.FileName
+			sc = s
+			materialized = sc.materialized
+		}
+	}
+	var err error
+	if opts.LazyFuncBodies {
+		err = translateLazy(b, gen, opts, materialized)
+	} else {
+		err = translate(b, gen)
+	}
+	opts.warn(gen.Warnings())
 	if err != nil {
-		return nil, errors.WithStack(err)
+		if errs := gen.Errors(); len(errs) > 0 {
+			return nil, opts.errs(errs)
+		}
+		return nil, opts.errs([]error{err})
+	}
+	m := gen.Module
+	// Attach comments and source positions.
+	if sc != nil {
+		sc.attach(m)
+	}
+	// Perform static semantic analysis.
+	if opts.Check {
+		if err := sem.Check(m); err != nil {
+			if errs, ok := err.(sem.ErrorList); ok {
+				return nil, opts.errs(errs)
+			}
+			return nil, opts.errs([]error{err})
+		}
+	}
+	// Run user-provided passes.
+	for _, pass := range opts.Passes {
+		if err := pass(m); err != nil {
+			return nil, opts.errs([]error{err})
+		}
 	}
 	return m, nil
 }
@@ -53,9 +199,42 @@ func ParseString(s string) (*ir.Module, error) {
 	return ParseBytes([]byte(s))
 }
 
+// translate parses the given LLVM IR assembly file, reading from b, and
+// translates it to LLVM IR, emitting code to gen.
+func translate(b []byte, gen *irx.Module) (err error) {
+	module, err := parseBytes(b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// Report unsupported constructs outside of function bodies as errors.
+	defer func() {
+		if e := recover(); e != nil {
+			unsupported, ok := e.(*irx.UnsupportedError)
+			if !ok {
+				panic(e)
+			}
+			err = errors.WithStack(unsupported)
+		}
+	}()
+	if err := gen.TranslateModule(module); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 // parseBytes parses the given LLVM IR assembly file into an AST, reading from
 // b.
 func parseBytes(b []byte) (*ast.Module, error) {
+	m, err := parseModule(b)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return astx.FixModule(m), nil
+}
+
+// parseModule parses the given LLVM IR assembly file into an AST, reading from
+// b. Dummy values of the AST are left unresolved.
+func parseModule(b []byte) (*ast.Module, error) {
 	l := lexer.NewLexer(b)
 	p := parser.NewParser()
 	module, err := p.Parse(l)
@@ -68,3 +247,42 @@ func parseBytes(b []byte) (*ast.Module, error) {
 	}
 	return m, nil
 }
+
+// ### [ Helper functions ] ####################################################
+
+// errs returns an error based on the given list of errors, as limited by the
+// maximum error count of the parse options. Errors are prefixed by the file
+// name of the parse options, if present.
+func (opts ParseOptions) errs(errs []error) error {
+	if opts.MaxErrors > 0 && len(errs) > opts.MaxErrors {
+		errs = errs[:opts.MaxErrors]
+	}
+	if len(errs) == 1 {
+		return opts.wrap(errs[0])
+	}
+	var es ErrorList
+	for _, err := range errs {
+		es = append(es, opts.wrap(err))
+	}
+	return es
+}
+
+// warn reports the given warnings through the warning handler of the parse
+// options, if present.
+func (opts ParseOptions) warn(warns []error) {
+	if opts.Warn == nil {
+		return
+	}
+	for _, warn := range warns {
+		opts.Warn(opts.wrap(warn))
+	}
+}
+
+// wrap prefixes the given error by the file name of the parse options, if
+// present.
+func (opts ParseOptions) wrap(err error) error {
+	if len(opts.FileName) == 0 {
+		return err
+	}
+	return errors.Wrap(err, opts.FileName)
+}
@@ -0,0 +1,163 @@
+package asm_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestParseParallel(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.ll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		want, err := asm.ParseFile(path)
+		if err != nil {
+			t.Errorf("%q: unable to parse file; %v", path, err)
+			continue
+		}
+		for _, parallelism := range []int{2, 4, 64} {
+			opts := asm.ParseOptions{Parallelism: parallelism}
+			m, err := asm.ParseFileOptions(path, opts)
+			if err != nil {
+				t.Errorf("%q: unable to parse file in parallel; %v", path, err)
+				continue
+			}
+			if got := m.String(); got != want.String() {
+				t.Errorf("%q: module mismatch with parallelism %d; expected `%v`, got `%v`", path, parallelism, want, got)
+			}
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	const unsupported = `
+define void @f() {
+	fence seq_cst
+	ret void
+}
+
+define void @g() {
+	ret void
+}
+`
+	// Strictness; unsupported instructions are skipped by default.
+	for _, lazy := range []bool{false, true} {
+		var warns []error
+		opts := asm.ParseOptions{
+			LazyFuncBodies: lazy,
+			Warn: func(err error) {
+				warns = append(warns, err)
+			},
+		}
+		m, err := asm.ParseBytesOptions([]byte(unsupported), opts)
+		if err != nil {
+			t.Fatalf("unable to parse module (lazy=%v); %v", lazy, err)
+		}
+		if err := m.Funcs[0].Materialize(); err != nil {
+			t.Fatalf("unable to materialize function (lazy=%v); %v", lazy, err)
+		}
+		if got, want := m.Funcs[0].String(), "define void @f() {\n; <label>:0\n\tret void\n}"; got != want {
+			t.Errorf("function mismatch (lazy=%v); expected %q, got %q", lazy, want, got)
+		}
+		if len(warns) != 1 {
+			t.Errorf("number of warnings mismatch (lazy=%v); expected 1, got %d", lazy, len(warns))
+		}
+	}
+	for _, lazy := range []bool{false, true} {
+		opts := asm.ParseOptions{FileName: "unsupported.ll", Strictness: asm.StrictnessError, LazyFuncBodies: lazy}
+		m, err := asm.ParseBytesOptions([]byte(unsupported), opts)
+		if lazy && err == nil {
+			err = m.Funcs[0].Materialize()
+		}
+		if err == nil {
+			t.Errorf("expected error for unsupported instruction (lazy=%v), got nil", lazy)
+		} else if got, want := err.Error(), "unsupported.ll: "; !strings.HasPrefix(got, want) {
+			t.Errorf("error prefix mismatch (lazy=%v); expected %q, got %q", lazy, want, got)
+		}
+	}
+	for _, lazy := range []bool{false, true} {
+		var warns []error
+		opts := asm.ParseOptions{
+			LazyFuncBodies: lazy,
+			Strictness:     asm.StrictnessWarn,
+			Warn: func(err error) {
+				warns = append(warns, err)
+			},
+		}
+		m, err := asm.ParseBytesOptions([]byte(unsupported), opts)
+		if err != nil {
+			t.Fatalf("unable to parse module (lazy=%v); %v", lazy, err)
+		}
+		for _, f := range m.Funcs {
+			if err := f.Materialize(); err != nil {
+				t.Fatalf("unable to materialize function (lazy=%v); %v", lazy, err)
+			}
+		}
+		if len(warns) != 1 {
+			t.Errorf("number of warnings mismatch (lazy=%v); expected 1, got %d", lazy, len(warns))
+		}
+		if got := len(m.Funcs[0].Blocks); got != 0 {
+			t.Errorf("number of basic blocks of skipped function mismatch (lazy=%v); expected 0, got %d", lazy, got)
+		}
+		if got := len(m.Funcs[1].Blocks); got != 1 {
+			t.Errorf("number of basic blocks mismatch (lazy=%v); expected 1, got %d", lazy, got)
+		}
+	}
+
+	// Maximum error count.
+	const invalid = `
+@x = global [2 x i32] [i32 1, i32 2, i32 3]
+@y = global [2 x i32] [i32 1, i32 2, i32 3]
+@z = global [2 x i32] [i32 1, i32 2, i32 3]
+`
+	_, err := asm.ParseBytesOptions([]byte(invalid), asm.ParseOptions{})
+	if errs, ok := err.(asm.ErrorList); !ok || len(errs) != 3 {
+		t.Errorf("expected list of 3 errors, got %v", err)
+	}
+	_, err = asm.ParseBytesOptions([]byte(invalid), asm.ParseOptions{MaxErrors: 1})
+	if _, ok := err.(asm.ErrorList); ok || err == nil {
+		t.Errorf("expected single error, got %v", err)
+	}
+
+	// Semantic analysis.
+	const semInvalid = `
+@"foo bar" = global i32 0
+`
+	if _, err := asm.ParseBytesOptions([]byte(semInvalid), asm.ParseOptions{}); err != nil {
+		t.Errorf("unable to parse module; %v", err)
+	}
+	if _, err := asm.ParseBytesOptions([]byte(semInvalid), asm.ParseOptions{Check: true}); err == nil {
+		t.Errorf("expected semantic error, got nil")
+	}
+
+	// Passes.
+	var order []string
+	opts := asm.ParseOptions{
+		Passes: []asm.Pass{
+			func(m *ir.Module) error {
+				order = append(order, "first")
+				return nil
+			},
+			func(m *ir.Module) error {
+				order = append(order, "second")
+				return errors.New("failure")
+			},
+			func(m *ir.Module) error {
+				order = append(order, "third")
+				return nil
+			},
+		},
+	}
+	if _, err := asm.ParseBytesOptions([]byte(unsupported[:0]), opts); err == nil {
+		t.Errorf("expected pass error, got nil")
+	}
+	if got, want := strings.Join(order, ","), "first,second"; got != want {
+		t.Errorf("pass order mismatch; expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,432 @@
+package asm
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/llir/llvm/asm/internal/token"
+	"github.com/llir/llvm/ir"
+)
+
+// A commentScan records the comments, blank lines and source positions of an
+// LLVM IR assembly file, in order of the entities to which they are attached.
+type commentScan struct {
+	// Comments of the module.
+	module *ir.Comments
+	// Comments of top-level entities, in source order.
+	entities []*entityComments
+	// retainComments specifies whether to attach comments to the module.
+	retainComments bool
+	// keepPositions specifies whether to attach source positions to the module.
+	keepPositions bool
+	// fileName is the file name of source positions.
+	fileName string
+
+	// mu prevents races on the comment map of the module when attaching the
+	// comments of lazily parsed function bodies.
+	mu sync.Mutex
+	// m is the module to which comments are attached.
+	m *ir.Module
+	// bodies maps from functions with lazily parsed function bodies to the
+	// comments of their function bodies.
+	bodies map[*ir.Function][]*entityComments
+}
+
+// entityComments records the comments and source position of an entity.
+type entityComments struct {
+	// Entity kind.
+	kind entityKind
+	// Line and column (in bytes) of the first token of the entity, starting at
+	// 1.
+	line, column int
+	// Comments attached to the entity.
+	comments *ir.Comments
+	// Comments of the labels, instructions and terminators of the function
+	// body, in source order; only used for function definitions.
+	body []*entityComments
+}
+
+// entityKind specifies the kind of an entity to which comments are attached.
+type entityKind uint8
+
+// Entity kinds.
+const (
+	kindNone entityKind = iota
+	// Top-level entities.
+	kindOther // source filename, target specifiers, attribute groups, etc.
+	kindTypeDef
+	kindGlobal
+	kindFunc
+	kindNamedMetadata
+	kindMetadata
+	// Function body statements.
+	kindLabel
+	kindInst
+	kindTerm
+	// Unsupported instruction (e.g. fence, va_arg), which is not present in
+	// translated function bodies.
+	kindUnsupported
+)
+
+// Token types used to classify entities.
+var (
+	tokGlobalIdent  = token.TokMap.Type("global_ident")
+	tokLocalIdent   = token.TokMap.Type("local_ident")
+	tokLabelIdent   = token.TokMap.Type("label_ident")
+	tokMetadataName = token.TokMap.Type("metadata_name")
+	tokMetadataID   = token.TokMap.Type("metadata_id")
+)
+
+// Token types of terminator keywords.
+var tokTerms = map[token.Type]bool{
+	token.TokMap.Type("ret"):         true,
+	token.TokMap.Type("br"):          true,
+	token.TokMap.Type("switch"):      true,
+	token.TokMap.Type("indirectbr"):  true,
+	token.TokMap.Type("invoke"):      true,
+	token.TokMap.Type("resume"):      true,
+	token.TokMap.Type("catchswitch"): true,
+	token.TokMap.Type("catchret"):    true,
+	token.TokMap.Type("cleanupret"):  true,
+	token.TokMap.Type("unreachable"): true,
+}
+
+// Token types of unsupported instruction keywords (see ast.InstUnsupported).
+var tokUnsupportedInsts = map[token.Type]bool{
+	token.TokMap.Type("fence"):      true,
+	token.TokMap.Type("cmpxchg"):    true,
+	token.TokMap.Type("atomicrmw"):  true,
+	token.TokMap.Type("va_arg"):     true,
+	token.TokMap.Type("landingpad"): true,
+	token.TokMap.Type("catchpad"):   true,
+	token.TokMap.Type("cleanuppad"): true,
+}
+
+// scanComments records the comments and blank lines of the given LLVM IR
+// assembly file. Comments are attached to the succeeding top-level entity,
+// label, instruction or terminator, or to the preceding one if on the same line
+// as its end. Comments within an entity are attached to the succeeding entity.
+// The boolean return value indicates success.
+func scanComments(src []byte) (*commentScan, bool) {
+	toks, ok := tokenize(src)
+	if !ok {
+		return nil, false
+	}
+	bodies, ok := scanFuncBodies(toks)
+	if !ok {
+		return nil, false
+	}
+	sc := &commentScan{module: &ir.Comments{}}
+	var (
+		// Pending comment lines, to be attached to the succeeding entity.
+		pending []string
+		// Comments of the entity ending at the previous token; or nil if not
+		// present.
+		cur *ir.Comments
+		// Current function definition.
+		fn *entityComments
+		// Index of the succeeding function body.
+		bodyIdx int
+		// Within function body.
+		inBody bool
+		// Nesting depth of brackets.
+		depth int
+		// Top-level entity encountered.
+		seenEntity bool
+		// End offset and line of the previous token; or -1 if not present.
+		prevEnd  = -1
+		prevLine = -1
+	)
+	for i, tok := range toks {
+		// Classify token.
+		kind := kindNone
+		isOpen := bodyIdx < len(bodies) && tok.Offset == bodies[bodyIdx].lbrace
+		isClose := inBody && tok.Offset+len(tok.Lit) == bodies[bodyIdx].end && tok.Type == tokRBrace
+		switch {
+		case tok.Type == token.EOF:
+		case depth == 0 && isTopLevel(toks, i):
+			kind = topLevelKind(tok.Type)
+		case inBody && depth == 1 && tok.Line > prevLine && !isClose:
+			kind = bodyKind(toks, i)
+		}
+		// Record comments preceding the token.
+		start := 0
+		if prevEnd >= 0 {
+			start = prevEnd
+		}
+		isEOF := tok.Type == token.EOF
+		sameLine, lines := splitGap(string(src[start:tok.Offset]), prevEnd >= 0, isEOF)
+		if kind == kindNone && !isClose && !isEOF {
+			// Comments within an entity are attached to the succeeding entity;
+			// ignore blank lines.
+			if len(sameLine) > 0 {
+				pending = append(pending, sameLine)
+			}
+			for _, line := range lines {
+				if len(line) > 0 {
+					pending = append(pending, line)
+				}
+			}
+		} else {
+			if len(sameLine) > 0 {
+				if cur != nil {
+					cur.Trailing = sameLine
+				} else {
+					pending = append(pending, sameLine)
+				}
+			}
+			pending = append(pending, lines...)
+		}
+		switch {
+		case isEOF:
+			// Trim trailing blank lines.
+			for len(pending) > 0 && len(pending[len(pending)-1]) == 0 {
+				pending = pending[:len(pending)-1]
+			}
+			sc.module.Footer = pending
+			pending = nil
+		case isClose:
+			fn.comments.Footer = pending
+			pending = nil
+			cur = fn.comments
+			inBody = false
+			bodyIdx++
+		case kind == kindOther:
+			if !seenEntity {
+				sc.module.Leading = append(sc.module.Leading, pending...)
+				pending = nil
+			}
+			cur = nil
+		case kind != kindNone:
+			column := tok.Offset - bytes.LastIndexByte(src[:tok.Offset], '\n')
+			e := &entityComments{kind: kind, line: tok.Line, column: column, comments: &ir.Comments{Leading: pending}}
+			pending = nil
+			cur = e.comments
+			switch kind {
+			case kindLabel, kindInst, kindTerm, kindUnsupported:
+				fn.body = append(fn.body, e)
+			default:
+				sc.entities = append(sc.entities, e)
+				seenEntity = true
+				if kind == kindFunc {
+					fn = e
+				}
+			}
+		}
+		if isOpen {
+			inBody = true
+			cur = nil
+		}
+		// Track nesting depth of brackets.
+		switch tok.Type {
+		case tokLBrace, tokLParen, tokLBrack:
+			depth++
+		case tokRBrace, tokRParen, tokRBrack:
+			depth--
+		}
+		prevEnd = tok.Offset + len(tok.Lit)
+		prevLine = tok.Line + strings.Count(string(tok.Lit), "\n")
+	}
+	return sc, true
+}
+
+// attach attaches the recorded comments and source positions to the
+// corresponding entities of the given module, as specified by sc. The comments
+// and source positions of lazily parsed function bodies are attached on
+// materialization (see materialized).
+func (sc *commentScan) attach(m *ir.Module) {
+	sc.m = m
+	sc.bodies = make(map[*ir.Function][]*entityComments)
+	if sc.retainComments {
+		m.Comments = map[interface{}]*ir.Comments{m: sc.module}
+	}
+	if sc.keepPositions {
+		m.Positions = make(map[interface{}]ir.Position)
+	}
+	var ntypes, nglobals, nfuncs, nnamed, nmetadata int
+	for _, e := range sc.entities {
+		switch e.kind {
+		case kindTypeDef:
+			if ntypes < len(m.Types) {
+				sc.attachEntity(m.Types[ntypes], e)
+			}
+			ntypes++
+		case kindGlobal:
+			if nglobals < len(m.Globals) {
+				sc.attachEntity(m.Globals[nglobals], e)
+			}
+			nglobals++
+		case kindFunc:
+			if nfuncs < len(m.Funcs) {
+				f := m.Funcs[nfuncs]
+				sc.attachEntity(f, e)
+				if f.IsMaterializable() {
+					sc.bodies[f] = e.body
+				} else {
+					sc.attachBody(f, e.body)
+				}
+			}
+			nfuncs++
+		case kindNamedMetadata:
+			if nnamed < len(m.NamedMetadata) {
+				sc.attachEntity(m.NamedMetadata[nnamed], e)
+			}
+			nnamed++
+		case kindMetadata:
+			if nmetadata < len(m.Metadata) {
+				sc.attachEntity(m.Metadata[nmetadata], e)
+			}
+			nmetadata++
+		}
+	}
+}
+
+// materialized attaches the recorded comments and source positions of the body
+// of the given lazily parsed function, after its materialization.
+func (sc *commentScan) materialized(f *ir.Function) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	body, ok := sc.bodies[f]
+	if !ok {
+		return
+	}
+	delete(sc.bodies, f)
+	sc.attachBody(f, body)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// attachEntity attaches the recorded comments and source position of e to the
+// given entity of the module.
+func (sc *commentScan) attachEntity(v interface{}, e *entityComments) {
+	if sc.m.Comments != nil {
+		sc.m.Comments[v] = e.comments
+	}
+	sc.attachPosition(v, e)
+}
+
+// attachPosition attaches the recorded source position of e to the given
+// entity of the module.
+func (sc *commentScan) attachPosition(v interface{}, e *entityComments) {
+	if sc.m.Positions != nil {
+		sc.m.Positions[v] = ir.Position{FileName: sc.fileName, Line: e.line, Column: e.column}
+	}
+}
+
+// attachBody attaches the recorded comments and source positions of the given
+// function body statements to the corresponding basic blocks, instructions and
+// terminators of f. Basic blocks without labels are positioned at their first
+// statement. Unsupported instructions are not present in translated function
+// bodies; their comments are attached to the succeeding statement.
+func (sc *commentScan) attachBody(f *ir.Function, body []*entityComments) {
+	blockIdx, instIdx := -1, 0
+	newBlock := true
+	// Comments of skipped unsupported instructions.
+	var skipped []string
+	for _, e := range body {
+		if e.kind == kindUnsupported {
+			skipped = append(skipped, e.comments.Leading...)
+			if len(e.comments.Trailing) > 0 {
+				skipped = append(skipped, e.comments.Trailing)
+			}
+			continue
+		}
+		if len(skipped) > 0 {
+			e.comments.Leading = append(skipped, e.comments.Leading...)
+			skipped = nil
+		}
+		if e.kind == kindLabel || newBlock {
+			blockIdx++
+			instIdx = 0
+			newBlock = false
+			if blockIdx < len(f.Blocks) && e.kind != kindLabel {
+				sc.attachPosition(f.Blocks[blockIdx], e)
+			}
+		}
+		if blockIdx >= len(f.Blocks) {
+			return
+		}
+		block := f.Blocks[blockIdx]
+		switch e.kind {
+		case kindLabel:
+			sc.attachEntity(block, e)
+		case kindInst:
+			if instIdx >= len(block.Insts) {
+				continue
+			}
+			sc.attachEntity(block.Insts[instIdx], e)
+			instIdx++
+		case kindTerm:
+			sc.attachEntity(block.Term, e)
+			newBlock = true
+		}
+	}
+}
+
+// topLevelKind returns the kind of the top-level entity starting with a token
+// of the given type.
+func topLevelKind(typ token.Type) entityKind {
+	switch typ {
+	case tokDefine, tokDeclare:
+		return kindFunc
+	case tokLocalIdent:
+		return kindTypeDef
+	case tokGlobalIdent:
+		return kindGlobal
+	case tokMetadataName:
+		return kindNamedMetadata
+	case tokMetadataID:
+		return kindMetadata
+	default:
+		return kindOther
+	}
+}
+
+// bodyKind returns the kind of the function body statement starting with the
+// token at index i.
+func bodyKind(toks []*token.Token, i int) entityKind {
+	typ := toks[i].Type
+	if typ == tokLabelIdent {
+		return kindLabel
+	}
+	// Skip local identifier assignment (e.g. `%x = invoke ...`).
+	if typ == tokLocalIdent && i+2 < len(toks) && toks[i+1].Type == tokAssign {
+		typ = toks[i+2].Type
+	}
+	if tokTerms[typ] {
+		return kindTerm
+	}
+	if tokUnsupportedInsts[typ] {
+		return kindUnsupported
+	}
+	return kindInst
+}
+
+// splitGap splits the given gap between two tokens into the comment on the
+// same line as the preceding token (if present), and the succeeding comment
+// lines and blank lines. Comment lines of unnamed basic blocks (e.g. `;
+// <label>:1`), as output by the printer, are ignored.
+func splitGap(gap string, hasPrev, atEOF bool) (sameLine string, lines []string) {
+	parts := strings.Split(gap, "\n")
+	first, last := 0, len(parts)-1
+	if hasPrev {
+		sameLine = strings.TrimSpace(parts[0])
+		first = 1
+	}
+	if atEOF {
+		// Include the last line, as not followed by a token.
+		last = len(parts)
+	}
+	for i := first; i < last; i++ {
+		line := strings.TrimSpace(parts[i])
+		if strings.HasPrefix(line, "; <label>:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if strings.HasPrefix(sameLine, "; <label>:") {
+		sameLine = ""
+	}
+	return sameLine, lines
+}
@@ -0,0 +1,157 @@
+package asm_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestRetainComments(t *testing.T) {
+	const path = "testdata/comment.ll"
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := string(buf)
+	for _, lazy := range []bool{false, true} {
+		opts := asm.ParseOptions{RetainComments: true, LazyFuncBodies: lazy}
+		m, err := asm.ParseBytesOptions(buf, opts)
+		if err != nil {
+			t.Errorf("%q: unable to parse file; %v", path, err)
+			continue
+		}
+		if got := m.String(); got != want {
+			t.Errorf("%q: module mismatch (lazy=%v); expected `%v`, got `%v`", path, lazy, want, got)
+		}
+	}
+	// Comments are discarded by default.
+	m, err := asm.ParseBytes(buf)
+	if err != nil {
+		t.Fatalf("%q: unable to parse file; %v", path, err)
+	}
+	if got := m.String(); strings.Contains(got, "CHECK") {
+		t.Errorf("%q: unexpected comments in module `%v`", path, got)
+	}
+}
+
+func TestRetainCommentsSkippedInst(t *testing.T) {
+	const src = `define void @f(i32* %p) {
+	; before fence
+	fence seq_cst
+	; before store
+	store i32 1, i32* %p
+	; before ret
+	ret void
+}
+`
+	// Comments of skipped unsupported instructions are attached to the
+	// succeeding statement.
+	const want = `define void @f(i32* %p) {
+; <label>:0
+	; before fence
+	; before store
+	store i32 1, i32* %p
+	; before ret
+	ret void
+}
+`
+	for _, lazy := range []bool{false, true} {
+		opts := asm.ParseOptions{RetainComments: true, LazyFuncBodies: lazy}
+		m, err := asm.ParseBytesOptions([]byte(src), opts)
+		if err != nil {
+			t.Fatalf("unable to parse module (lazy=%v); %v", lazy, err)
+		}
+		if got := m.String(); got != want {
+			t.Errorf("module mismatch (lazy=%v); expected `%v`, got `%v`", lazy, want, got)
+		}
+	}
+}
+
+func TestKeepPositions(t *testing.T) {
+	const src = `@x = global i32 0
+
+define i32 @f(i32 %a) {
+	%b = add i32 %a, 1
+	br label %exit
+
+exit:
+	ret i32 %b
+}
+`
+	for _, lazy := range []bool{false, true} {
+		opts := asm.ParseOptions{FileName: "f.ll", KeepPositions: true, LazyFuncBodies: lazy}
+		m, err := asm.ParseBytesOptions([]byte(src), opts)
+		if err != nil {
+			t.Fatalf("unable to parse module (lazy=%v); %v", lazy, err)
+		}
+		f := m.Funcs[0]
+		if err := f.Materialize(); err != nil {
+			t.Fatalf("unable to materialize function (lazy=%v); %v", lazy, err)
+		}
+		golden := []struct {
+			entity interface{}
+			want   string
+		}{
+			{entity: m.Globals[0], want: "f.ll:1:1"},
+			{entity: f, want: "f.ll:3:1"},
+			{entity: f.Blocks[0], want: "f.ll:4:2"},
+			{entity: f.Blocks[0].Insts[0], want: "f.ll:4:2"},
+			{entity: f.Blocks[0].Term, want: "f.ll:5:2"},
+			{entity: f.Blocks[1], want: "f.ll:7:1"},
+			{entity: f.Blocks[1].Term, want: "f.ll:8:2"},
+		}
+		for _, g := range golden {
+			pos, ok := m.Positions[g.entity]
+			if !ok {
+				t.Errorf("missing source position of %v (lazy=%v)", g.entity, lazy)
+				continue
+			}
+			if got := pos.String(); got != g.want {
+				t.Errorf("source position mismatch (lazy=%v); expected %q, got %q", lazy, g.want, got)
+			}
+		}
+		if m.Comments != nil {
+			t.Errorf("unexpected comments of module (lazy=%v)", lazy)
+		}
+	}
+	// Source positions account for skipped unsupported instructions.
+	const skipped = `define void @f(i32* %p) {
+	fence seq_cst
+	store i32 1, i32* %p
+	ret void
+}
+`
+	for _, lazy := range []bool{false, true} {
+		opts := asm.ParseOptions{FileName: "f.ll", KeepPositions: true, LazyFuncBodies: lazy}
+		m, err := asm.ParseBytesOptions([]byte(skipped), opts)
+		if err != nil {
+			t.Fatalf("unable to parse module (lazy=%v); %v", lazy, err)
+		}
+		f := m.Funcs[0]
+		if err := f.Materialize(); err != nil {
+			t.Fatalf("unable to materialize function (lazy=%v); %v", lazy, err)
+		}
+		golden := []struct {
+			entity interface{}
+			want   string
+		}{
+			{entity: f.Blocks[0].Insts[0], want: "f.ll:3:2"},
+			{entity: f.Blocks[0].Term, want: "f.ll:4:2"},
+		}
+		for _, g := range golden {
+			if got := m.Positions[g.entity].String(); got != g.want {
+				t.Errorf("source position mismatch (lazy=%v); expected %q, got %q", lazy, g.want, got)
+			}
+		}
+	}
+	// Source positions are discarded by default.
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Positions != nil {
+		t.Errorf("unexpected source positions of module")
+	}
+}
@@ -73,7 +73,8 @@ func Example() {
 	//             Blocks:   nil,
 	//             Metadata: {
 	//             },
-	//             mu: sync.Mutex{},
+	//             mu:     sync.Mutex{},
+	//             locals: ir.localSymtab{},
 	//         },
 	//         &ir.Function{
 	//             Parent: &ir.Module{(CYCLIC REFERENCE)},
@@ -121,6 +122,8 @@ func Example() {
 	//                                     abs: {0x15a4e35},
 	//                                 },
 	//                             },
+	//                             NUW:      false,
+	//                             NSW:      false,
 	//                             Metadata: {
 	//                             },
 	//                         },
@@ -135,6 +138,8 @@ func Example() {
 	//                                     abs: {0x1},
 	//                                 },
 	//                             },
+	//                             NUW:      false,
+	//                             NSW:      false,
 	//                             Metadata: {
 	//                             },
 	//                         },
@@ -179,10 +184,15 @@ func Example() {
 	//             },
 	//             Metadata: {
 	//             },
-	//             mu: sync.Mutex{},
+	//             mu:     sync.Mutex{},
+	//             locals: ir.localSymtab{},
 	//         },
 	//     },
 	//     NamedMetadata: nil,
 	//     Metadata:      nil,
+	//     Comments:      {},
+	//     Positions:     {},
+	//     symtab:        ir.symtab{},
+	//     consts:        (*constant.Pool)(nil),
 	// }
 }
@@ -0,0 +1,113 @@
+package asm
+
+import (
+	"github.com/llir/llvm/asm/internal/ast"
+	"github.com/llir/llvm/asm/internal/astx"
+	"github.com/llir/llvm/asm/internal/irx"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/pkg/errors"
+)
+
+// ParseType parses the given LLVM IR type (e.g. `{ i32, %T* }`). Named types
+// are resolved by name using the type definitions of m; which may be nil if
+// no context module is required.
+func ParseType(s string, m *ir.Module) (t types.Type, err error) {
+	defer catch(&err)
+	module, err := parseModule([]byte("%0 = type " + s))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(module.Types) != 1 {
+		return nil, errors.Errorf("invalid type %q", s)
+	}
+	return newGen(m).TranslateType(module.Types[0].Def)
+}
+
+// ParseConstant parses the given LLVM IR constant, prefixed by its type (e.g.
+// `i32 42`). Named types and global identifiers are resolved by name using m;
+// which may be nil if no context module is required.
+func ParseConstant(s string, m *ir.Module) (c constant.Constant, err error) {
+	defer catch(&err)
+	module, err := parseModule([]byte("@0 = global " + s))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(module.Globals) != 1 || module.Globals[0].Init == nil {
+		return nil, errors.Errorf("invalid constant %q", s)
+	}
+	return newGen(m).TranslateConstant(module.Globals[0].Init)
+}
+
+// ParseInstruction parses the given non-branching LLVM IR instruction (e.g.
+// `%y = add i32 %x, 1`). Local identifiers are resolved by name using the
+// named function parameters, basic blocks and local variables of f; and global
+// identifiers, types and metadata IDs using the parent module of f. The context
+// function f may be nil.
+//
+// The parsed instruction is not inserted into a basic block of f.
+func ParseInstruction(s string, f *ir.Function) (inst ir.Instruction, err error) {
+	defer catch(&err)
+	module, err := parseModule([]byte("define void @0() {\n" + s + "\n\tunreachable\n}\n"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(module.Funcs) != 1 || len(module.Funcs[0].Blocks) != 1 || len(module.Funcs[0].Blocks[0].Insts) != 1 {
+		return nil, errors.Errorf("invalid instruction %q; expected a single non-branching instruction", s)
+	}
+	var m *ir.Module
+	if f != nil {
+		if err := f.Materialize(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		m = f.Parent
+	}
+	return newGen(m).TranslateInst(module.Funcs[0].Blocks[0].Insts[0], f)
+}
+
+// ParseFunction parses the given LLVM IR function definition or declaration.
+// Global identifiers, types and metadata IDs are resolved by name using m;
+// which may be nil if no context module is required.
+//
+// The parent module of the parsed function is m (or a new empty module if m is
+// nil), but the function is not appended to the functions of m.
+func ParseFunction(s string, m *ir.Module) (f *ir.Function, err error) {
+	defer catch(&err)
+	module, err := parseModule([]byte(s))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !isSingleFunc(module) {
+		return nil, errors.New("invalid function; expected a single function definition or declaration")
+	}
+	return newGen(m).TranslateFunc(astx.FixFunc(module.Funcs[0]))
+}
+
+// ### [ Helper functions ] ####################################################
+
+// newGen returns a new module generator for translating fragments of LLVM IR
+// in the context of the given module, which may be nil.
+func newGen(m *ir.Module) *irx.Module {
+	if m == nil {
+		return irx.NewModule()
+	}
+	return irx.NewModuleFrom(m)
+}
+
+// isSingleFunc reports whether the given module consists of a single function.
+func isSingleFunc(module *ast.Module) bool {
+	return len(module.Funcs) == 1 && len(module.Types) == 0 && len(module.Globals) == 0 && len(module.NamedMetadata) == 0 && len(module.Metadata) == 0
+}
+
+// catch recovers from a panic raised while parsing a fragment of LLVM IR
+// assembly, reporting it as an error.
+func catch(err *error) {
+	if e := recover(); e != nil {
+		if e, ok := e.(error); ok {
+			*err = errors.WithStack(e)
+			return
+		}
+		panic(e)
+	}
+}
@@ -0,0 +1,83 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestParseFragments(t *testing.T) {
+	const src = `
+%T = type { i32, i8* }
+
+@g = global i32 42
+
+define i32 @f(i32 %x) {
+entry:
+	%y = load i32, i32* @g
+	ret i32 %y
+}
+`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Types.
+	typ, err := asm.ParseType("{ i32, %T* }", m)
+	if err != nil {
+		t.Fatalf("unable to parse type; %v", err)
+	}
+	if got, want := typ.String(), "{ i32, %T* }"; got != want {
+		t.Errorf("type mismatch; expected %q, got %q", want, got)
+	}
+	if _, err := asm.ParseType("%U", m); err == nil {
+		t.Errorf("expected error for undefined type, got nil")
+	}
+
+	// Constants.
+	c, err := asm.ParseConstant("i32* getelementptr (i32, i32* @g, i64 1)", m)
+	if err != nil {
+		t.Fatalf("unable to parse constant; %v", err)
+	}
+	if got, want := c.Ident(), "getelementptr (i32, i32* @g, i64 1)"; got != want {
+		t.Errorf("constant mismatch; expected %q, got %q", want, got)
+	}
+
+	// Instructions.
+	f := m.Funcs[0]
+	inst, err := asm.ParseInstruction("%z = add i32 %x, %y", f)
+	if err != nil {
+		t.Fatalf("unable to parse instruction; %v", err)
+	}
+	if got, want := inst.String(), "%z = add i32 %x, %y"; got != want {
+		t.Errorf("instruction mismatch; expected %q, got %q", want, got)
+	}
+	if inst.GetParent() != nil {
+		t.Errorf("expected detached instruction, got parent %v", inst.GetParent().Ident())
+	}
+	if got := len(f.Blocks[0].Insts); got != 1 {
+		t.Errorf("number of instructions of context function mismatch; expected 1, got %d", got)
+	}
+	if _, err := asm.ParseInstruction("ret void", nil); err == nil {
+		t.Errorf("expected error for terminator, got nil")
+	}
+	if _, err := asm.ParseInstruction("%z = add i32 %x, %w", f); err == nil {
+		t.Errorf("expected error for undefined local identifier, got nil")
+	}
+
+	// Functions.
+	g, err := asm.ParseFunction("define i32 @h(i32 %a) {\n\t%b = call i32 @f(i32 %a)\n\t%c = call i32 @h(i32 %b)\n\tret i32 %c\n}", m)
+	if err != nil {
+		t.Fatalf("unable to parse function; %v", err)
+	}
+	if g.Parent != m {
+		t.Errorf("parent module mismatch")
+	}
+	if got := len(m.Funcs); got != 1 {
+		t.Errorf("number of functions of context module mismatch; expected 1, got %d", got)
+	}
+	if got, want := len(g.Blocks[0].Insts), 2; got != want {
+		t.Errorf("number of instructions mismatch; expected %d, got %d", want, got)
+	}
+}
@@ -15,6 +15,7 @@ var (
 	_ ast.Constant = &ast.StructConst{}
 	_ ast.Constant = &ast.ZeroInitializerConst{}
 	_ ast.Constant = &ast.UndefConst{}
+	_ ast.Constant = &ast.PoisonConst{}
 	// Global variable and function addresses
 	_ ast.Constant = &ast.Global{}
 	_ ast.Constant = &ast.Function{}
@@ -124,6 +125,7 @@ var (
 	_ ast.Instruction = &ast.InstFCmp{}
 	_ ast.Instruction = &ast.InstPhi{}
 	_ ast.Instruction = &ast.InstSelect{}
+	_ ast.Instruction = &ast.InstFreeze{}
 	_ ast.Instruction = &ast.InstCall{}
 )
 
@@ -195,6 +197,7 @@ var (
 	_ ast.NamedValue = &ast.InstFCmp{}
 	_ ast.NamedValue = &ast.InstPhi{}
 	_ ast.NamedValue = &ast.InstSelect{}
+	_ ast.NamedValue = &ast.InstFreeze{}
 	_ ast.NamedValue = &ast.InstCall{}
 )
 
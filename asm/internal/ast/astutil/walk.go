@@ -180,6 +180,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(*n, before, after)
 	case **ast.UndefConst:
 		w.walkBeforeAfter(*n, before, after)
+	case **ast.PoisonConst:
+		w.walkBeforeAfter(*n, before, after)
 	// Constant expressions
 	case **ast.ExprAdd:
 		w.walkBeforeAfter(*n, before, after)
@@ -355,6 +357,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(*n, before, after)
 	case **ast.InstSelect:
 		w.walkBeforeAfter(*n, before, after)
+	case **ast.InstFreeze:
+		w.walkBeforeAfter(*n, before, after)
 	case **ast.InstCall:
 		w.walkBeforeAfter(*n, before, after)
 	// Terminators
@@ -560,6 +564,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Type, before, after)
 	case *ast.UndefConst:
 		w.walkBeforeAfter(&n.Type, before, after)
+	case *ast.PoisonConst:
+		w.walkBeforeAfter(&n.Type, before, after)
 	// Constant expressions
 	case *ast.ExprAdd:
 		w.walkBeforeAfter(&n.Type, before, after)
@@ -889,6 +895,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Cond, before, after)
 		w.walkBeforeAfter(&n.X, before, after)
 		w.walkBeforeAfter(&n.Y, before, after)
+	case *ast.InstFreeze:
+		w.walkBeforeAfter(&n.X, before, after)
 	case *ast.InstCall:
 		w.walkBeforeAfter(&n.Type, before, after)
 		w.walkBeforeAfter(&n.Callee, before, after)
@@ -919,6 +927,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 	case *ast.Case:
 		w.walkBeforeAfter(&n.X, before, after)
 		w.walkBeforeAfter(&n.Target, before, after)
+	case *ast.InstUnsupported:
+		// nothing to do.
 	case *ast.TermUnreachable:
 		// nothing to do.
 
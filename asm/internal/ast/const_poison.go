@@ -0,0 +1,18 @@
+package ast
+
+// PoisonConst represents a poison constant.
+type PoisonConst struct {
+	// Constant type.
+	Type Type
+}
+
+// isValue ensures that only values can be assigned to the ast.Value interface.
+func (*PoisonConst) isValue() {}
+
+// isConstant ensures that only constants can be assigned to the ast.Constant
+// interface.
+func (*PoisonConst) isConstant() {}
+
+// isMetadataNode ensures that only metadata nodes can be assigned to the
+// ast.MetadataNode interface.
+func (*PoisonConst) isMetadataNode() {}
@@ -30,6 +30,7 @@ package ast
 // Undefined value constants
 //
 //    *ast.UndefConst
+//    *ast.PoisonConst
 //
 // Constant expressions
 //
@@ -18,6 +18,10 @@ type ExprAdd struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -74,6 +78,10 @@ type ExprSub struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -130,6 +138,10 @@ type ExprMul struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -186,6 +198,8 @@ type ExprUDiv struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// Exact.
+	Exact bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -214,6 +228,8 @@ type ExprSDiv struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// Exact.
+	Exact bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
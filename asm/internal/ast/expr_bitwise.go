@@ -18,6 +18,10 @@ type ExprShl struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -46,6 +50,8 @@ type ExprLShr struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// Exact.
+	Exact bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
@@ -74,6 +80,8 @@ type ExprAShr struct {
 	Type Type
 	// Operands.
 	X, Y Constant
+	// Exact.
+	Exact bool
 }
 
 // isValue ensures that only values can be assigned to the ast.Value interface.
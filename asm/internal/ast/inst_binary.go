@@ -18,6 +18,10 @@ type InstAdd struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -82,6 +86,10 @@ type InstSub struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -146,6 +154,10 @@ type InstMul struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -210,6 +222,8 @@ type InstUDiv struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// Exact.
+	Exact bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -242,6 +256,8 @@ type InstSDiv struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// Exact.
+	Exact bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
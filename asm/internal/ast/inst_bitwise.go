@@ -18,6 +18,10 @@ type InstShl struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// No unsigned wrap.
+	NUW bool
+	// No signed wrap.
+	NSW bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -50,6 +54,8 @@ type InstLShr struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// Exact.
+	Exact bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
@@ -82,6 +88,8 @@ type InstAShr struct {
 	Name string
 	// Operands.
 	X, Y Value
+	// Exact.
+	Exact bool
 	// Metadata attached to the instruction.
 	Metadata []*AttachedMD
 }
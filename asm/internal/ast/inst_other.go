@@ -121,6 +121,31 @@ func (inst *InstSelect) SetName(name string) {
 	inst.Name = name
 }
 
+// --- [ freeze ] --------------------------------------------------------------
+
+// InstFreeze represents a freeze instruction.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#freeze-instruction
+type InstFreeze struct {
+	// Name of the local variable associated with the instruction.
+	Name string
+	// Operand.
+	X Value
+	// Metadata attached to the instruction.
+	Metadata []*AttachedMD
+}
+
+// GetName returns the name of the value.
+func (inst *InstFreeze) GetName() string {
+	return inst.Name
+}
+
+// SetName sets the name of the value.
+func (inst *InstFreeze) SetName(name string) {
+	inst.Name = name
+}
+
 // --- [ call ] ----------------------------------------------------------------
 
 // InstCall represents a call instruction.
@@ -160,11 +185,19 @@ func (inst *InstCall) SetName(name string) {
 
 // --- [ cleanuppad ] ----------------------------------------------------------
 
+// --- [ Unsupported instructions ] --------------------------------------------
+
+// InstUnsupported represents an instruction not yet supported by the parser
+// (e.g. fence, va_arg).
+type InstUnsupported struct {
+}
+
 // isValue ensures that only values can be assigned to the ast.Value interface.
 func (*InstICmp) isValue()   {}
 func (*InstFCmp) isValue()   {}
 func (*InstPhi) isValue()    {}
 func (*InstSelect) isValue() {}
+func (*InstFreeze) isValue() {}
 func (*InstCall) isValue()   {}
 
 // isInst ensures that only instructions can be assigned to the ast.Instruction
@@ -173,4 +206,9 @@ func (*InstICmp) isInst()   {}
 func (*InstFCmp) isInst()   {}
 func (*InstPhi) isInst()    {}
 func (*InstSelect) isInst() {}
+func (*InstFreeze) isInst() {}
 func (*InstCall) isInst()   {}
+
+// isInst ensures that only instructions can be assigned to the ast.Instruction
+// interface.
+func (*InstUnsupported) isInst() {}
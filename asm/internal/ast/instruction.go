@@ -82,7 +82,12 @@ package ast
 //    *ast.InstFCmp
 //    *ast.InstPhi
 //    *ast.InstSelect
+//    *ast.InstFreeze
 //    *ast.InstCall
+//
+// Unsupported instructions
+//
+//    *ast.InstUnsupported
 type Instruction interface {
 	// isInst ensures that only instructions can be assigned to the
 	// ast.Instruction interface.
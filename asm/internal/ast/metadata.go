@@ -57,7 +57,7 @@ type NamedMetadata struct {
 	// Metadata name.
 	Name string
 	// Associated metadata; initially *ast.MetadataIDDummy and replaced with
-	// corresponding *ast.Metadata by astx.fixModule.
+	// corresponding *ast.Metadata by astx.FixModule.
 	Metadata []MetadataNode
 }
 
@@ -67,7 +67,7 @@ type AttachedMD struct {
 	Name string
 	// Metadata; may be *ast.MetadataIDDummy or *ast.Metadata during translation,
 	// *ast.MetadataIDDummy are later replaced with corresponding *ast.Metadata
-	// by astx.fixModule.
+	// by astx.FixModule.
 	Metadata MetadataNode
 }
 
@@ -24,6 +24,7 @@ var dbg = log.New(os.Stdout, "", log.Lshortfile)
 // === [ Modules ] =============================================================
 
 // NewModule returns a new module based on the given top-level declarations.
+// Dummy values within the module are resolved separately by FixModule.
 func NewModule(decls interface{}) (*ast.Module, error) {
 	var ds []TopLevelDecl
 	switch decls := decls.(type) {
@@ -55,7 +56,6 @@ func NewModule(decls interface{}) (*ast.Module, error) {
 			dbg.Printf("support for %T not yet implemented", d)
 		}
 	}
-	m = fixModule(m)
 	return m, nil
 }
 
@@ -867,6 +867,8 @@ func NewValue(typ, val interface{}) (ast.Value, error) {
 		return &ast.ZeroInitializerConst{Type: t}, nil
 	case *UndefLit:
 		return &ast.UndefConst{Type: t}, nil
+	case *PoisonLit:
+		return &ast.PoisonConst{Type: t}, nil
 
 	// Replace *ast.TypeDummy with real type; as used by incoming values of phi
 	// instructions.
@@ -947,6 +949,13 @@ func NewValue(typ, val interface{}) (ast.Value, error) {
 		}
 		val.Type = t
 		return val, nil
+	case *ast.PoisonConst:
+		// poison constant type should be of dummy type.
+		if _, ok := val.Type.(*ast.TypeDummy); !ok {
+			return nil, errors.Errorf("invalid poison constant type, expected *ast.TypeDummy, got %T", val.Type)
+		}
+		val.Type = t
+		return val, nil
 
 	// Binary expressions
 	case *ast.ExprAdd:
@@ -1389,10 +1398,19 @@ type ZeroInitializerLit struct {
 type UndefLit struct {
 }
 
+// PoisonLit represents a poison literal.
+type PoisonLit struct {
+}
+
 // --- [ Binary expressions ] --------------------------------------------------
 
-// NewAddExpr returns a new add expression based on the given type and operands.
-func NewAddExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAdd, error) {
+// NewAddExpr returns a new add expression based on the given overflow flags,
+// type and operands.
+func NewAddExpr(flags, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAdd, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1401,7 +1419,7 @@ func NewAddExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAdd, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprAdd{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprAdd{Type: &ast.TypeDummy{}, X: x, Y: y, NUW: nuw, NSW: nsw}, nil
 }
 
 // NewFAddExpr returns a new fadd expression based on the given type and
@@ -1418,8 +1436,13 @@ func NewFAddExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprFAdd, error) {
 	return &ast.ExprFAdd{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
 }
 
-// NewSubExpr returns a new sub expression based on the given type and operands.
-func NewSubExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSub, error) {
+// NewSubExpr returns a new sub expression based on the given overflow flags,
+// type and operands.
+func NewSubExpr(flags, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSub, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1428,7 +1451,7 @@ func NewSubExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSub, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprSub{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprSub{Type: &ast.TypeDummy{}, X: x, Y: y, NUW: nuw, NSW: nsw}, nil
 }
 
 // NewFSubExpr returns a new fsub expression based on the given type and
@@ -1445,8 +1468,13 @@ func NewFSubExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprFSub, error) {
 	return &ast.ExprFSub{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
 }
 
-// NewMulExpr returns a new mul expression based on the given type and operands.
-func NewMulExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprMul, error) {
+// NewMulExpr returns a new mul expression based on the given overflow flags,
+// type and operands.
+func NewMulExpr(flags, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprMul, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1455,7 +1483,7 @@ func NewMulExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprMul, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprMul{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprMul{Type: &ast.TypeDummy{}, X: x, Y: y, NUW: nuw, NSW: nsw}, nil
 }
 
 // NewFMulExpr returns a new fmul expression based on the given type and
@@ -1472,9 +1500,13 @@ func NewFMulExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprFMul, error) {
 	return &ast.ExprFMul{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
 }
 
-// NewUDivExpr returns a new udiv expression based on the given type and
-// operands.
-func NewUDivExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprUDiv, error) {
+// NewUDivExpr returns a new udiv expression based on the given exact flag, type
+// and operands.
+func NewUDivExpr(exact, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprUDiv, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1483,12 +1515,16 @@ func NewUDivExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprUDiv, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprUDiv{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprUDiv{Type: &ast.TypeDummy{}, X: x, Y: y, Exact: isExact}, nil
 }
 
-// NewSDivExpr returns a new sdiv expression based on the given type and
-// operands.
-func NewSDivExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSDiv, error) {
+// NewSDivExpr returns a new sdiv expression based on the given exact flag, type
+// and operands.
+func NewSDivExpr(exact, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSDiv, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1497,7 +1533,7 @@ func NewSDivExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprSDiv, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprSDiv{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprSDiv{Type: &ast.TypeDummy{}, X: x, Y: y, Exact: isExact}, nil
 }
 
 // NewFDivExpr returns a new fdiv expression based on the given type and
@@ -1558,8 +1594,13 @@ func NewFRemExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprFRem, error) {
 
 // --- [ Bitwise expressions ] -------------------------------------------------
 
-// NewShlExpr returns a new shl expression based on the given type and operands.
-func NewShlExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprShl, error) {
+// NewShlExpr returns a new shl expression based on the given overflow flags,
+// type and operands.
+func NewShlExpr(flags, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprShl, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1568,12 +1609,16 @@ func NewShlExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprShl, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprShl{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprShl{Type: &ast.TypeDummy{}, X: x, Y: y, NUW: nuw, NSW: nsw}, nil
 }
 
-// NewLShrExpr returns a new lshr expression based on the given type and
-// operands.
-func NewLShrExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprLShr, error) {
+// NewLShrExpr returns a new lshr expression based on the given exact flag, type
+// and operands.
+func NewLShrExpr(exact, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprLShr, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1582,12 +1627,16 @@ func NewLShrExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprLShr, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprLShr{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprLShr{Type: &ast.TypeDummy{}, X: x, Y: y, Exact: isExact}, nil
 }
 
-// NewAShrExpr returns a new ashr expression based on the given type and
-// operands.
-func NewAShrExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAShr, error) {
+// NewAShrExpr returns a new ashr expression based on the given exact flag, type
+// and operands.
+func NewAShrExpr(exact, xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAShr, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewConstant(xTyp, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1596,7 +1645,7 @@ func NewAShrExpr(xTyp, xVal, yTyp, yVal interface{}) (*ast.ExprAShr, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.ExprAShr{Type: &ast.TypeDummy{}, X: x, Y: y}, nil
+	return &ast.ExprAShr{Type: &ast.TypeDummy{}, X: x, Y: y, Exact: isExact}, nil
 }
 
 // NewAndExpr returns a new and expression based on the given type and operands.
@@ -2057,7 +2106,7 @@ func NewBasicBlock(name, insts, term interface{}) (*ast.BasicBlock, error) {
 func NewInstructionList(inst interface{}) ([]ast.Instruction, error) {
 	// TODO: Remove once all instructions in the BNF are supported.
 	if inst == nil {
-		return []ast.Instruction{}, nil
+		return []ast.Instruction{&ast.InstUnsupported{}}, nil
 	}
 	i, ok := inst.(ast.Instruction)
 	if !ok {
@@ -2074,7 +2123,7 @@ func AppendInstruction(insts, inst interface{}) ([]ast.Instruction, error) {
 	}
 	// TODO: Remove once all instructions in the BNF are supported.
 	if inst == nil {
-		return is, nil
+		return append(is, &ast.InstUnsupported{}), nil
 	}
 	i, ok := inst.(ast.Instruction)
 	if !ok {
@@ -2105,9 +2154,13 @@ func NewNamedInstruction(name, inst interface{}) (ast.Instruction, error) {
 
 // --- [ Binary instructions ] -------------------------------------------------
 
-// NewAddInst returns a new add instruction based on the given type, operands
-// and attached metadata.
-func NewAddInst(typ, xVal, yVal, mds interface{}) (*ast.InstAdd, error) {
+// NewAddInst returns a new add instruction based on the given overflow flags,
+// type, operands and attached metadata.
+func NewAddInst(flags, typ, xVal, yVal, mds interface{}) (*ast.InstAdd, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2120,7 +2173,7 @@ func NewAddInst(typ, xVal, yVal, mds interface{}) (*ast.InstAdd, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstAdd{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstAdd{X: x, Y: y, NUW: nuw, NSW: nsw, Metadata: metadata}, nil
 }
 
 // NewFAddInst returns a new fadd instruction based on the given type, operands
@@ -2141,9 +2194,13 @@ func NewFAddInst(typ, xVal, yVal, mds interface{}) (*ast.InstFAdd, error) {
 	return &ast.InstFAdd{X: x, Y: y, Metadata: metadata}, nil
 }
 
-// NewSubInst returns a new sub instruction based on the given type, operands
-// and attached metadata.
-func NewSubInst(typ, xVal, yVal, mds interface{}) (*ast.InstSub, error) {
+// NewSubInst returns a new sub instruction based on the given overflow flags,
+// type, operands and attached metadata.
+func NewSubInst(flags, typ, xVal, yVal, mds interface{}) (*ast.InstSub, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2156,7 +2213,7 @@ func NewSubInst(typ, xVal, yVal, mds interface{}) (*ast.InstSub, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstSub{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstSub{X: x, Y: y, NUW: nuw, NSW: nsw, Metadata: metadata}, nil
 }
 
 // NewFSubInst returns a new fsub instruction based on the given type, operands
@@ -2177,9 +2234,13 @@ func NewFSubInst(typ, xVal, yVal, mds interface{}) (*ast.InstFSub, error) {
 	return &ast.InstFSub{X: x, Y: y, Metadata: metadata}, nil
 }
 
-// NewMulInst returns a new mul instruction based on the given type, operands
-// and attached metadata.
-func NewMulInst(typ, xVal, yVal, mds interface{}) (*ast.InstMul, error) {
+// NewMulInst returns a new mul instruction based on the given overflow flags,
+// type, operands and attached metadata.
+func NewMulInst(flags, typ, xVal, yVal, mds interface{}) (*ast.InstMul, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2192,7 +2253,7 @@ func NewMulInst(typ, xVal, yVal, mds interface{}) (*ast.InstMul, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstMul{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstMul{X: x, Y: y, NUW: nuw, NSW: nsw, Metadata: metadata}, nil
 }
 
 // NewFMulInst returns a new fmul instruction based on the given type, operands
@@ -2213,9 +2274,13 @@ func NewFMulInst(typ, xVal, yVal, mds interface{}) (*ast.InstFMul, error) {
 	return &ast.InstFMul{X: x, Y: y, Metadata: metadata}, nil
 }
 
-// NewUDivInst returns a new udiv instruction based on the given type, operands
-// and attached metadata.
-func NewUDivInst(typ, xVal, yVal, mds interface{}) (*ast.InstUDiv, error) {
+// NewUDivInst returns a new udiv instruction based on the given exact flag, type,
+// operands and attached metadata.
+func NewUDivInst(exact, typ, xVal, yVal, mds interface{}) (*ast.InstUDiv, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2228,12 +2293,16 @@ func NewUDivInst(typ, xVal, yVal, mds interface{}) (*ast.InstUDiv, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstUDiv{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstUDiv{X: x, Y: y, Exact: isExact, Metadata: metadata}, nil
 }
 
-// NewSDivInst returns a new sdiv instruction based on the given type, operands
-// and attached metadata.
-func NewSDivInst(typ, xVal, yVal, mds interface{}) (*ast.InstSDiv, error) {
+// NewSDivInst returns a new sdiv instruction based on the given exact flag, type,
+// operands and attached metadata.
+func NewSDivInst(exact, typ, xVal, yVal, mds interface{}) (*ast.InstSDiv, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2246,7 +2315,7 @@ func NewSDivInst(typ, xVal, yVal, mds interface{}) (*ast.InstSDiv, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstSDiv{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstSDiv{X: x, Y: y, Exact: isExact, Metadata: metadata}, nil
 }
 
 // NewFDivInst returns a new fdiv instruction based on the given type, operands
@@ -2321,11 +2390,38 @@ func NewFRemInst(typ, xVal, yVal, mds interface{}) (*ast.InstFRem, error) {
 	return &ast.InstFRem{X: x, Y: y, Metadata: metadata}, nil
 }
 
+// NewOverflowFlagList returns a new overflow flag list based on the given
+// overflow flag.
+func NewOverflowFlagList(flag interface{}) ([]string, error) {
+	f, err := getTokenString(flag)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return []string{f}, nil
+}
+
+// AppendOverflowFlag appends the given overflow flag to the overflow flag list.
+func AppendOverflowFlag(flags, flag interface{}) ([]string, error) {
+	fs, ok := flags.([]string)
+	if !ok {
+		return nil, errors.Errorf("invalid overflow flag list type; expected []string, got %T", flags)
+	}
+	f, err := getTokenString(flag)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(fs, f), nil
+}
+
 // --- [ Bitwise instructions ] ------------------------------------------------
 
-// NewShlInst returns a new shl instruction based on the given type, operands
-// and attached metadata.
-func NewShlInst(typ, xVal, yVal, mds interface{}) (*ast.InstShl, error) {
+// NewShlInst returns a new shl instruction based on the given overflow flags,
+// type, operands and attached metadata.
+func NewShlInst(flags, typ, xVal, yVal, mds interface{}) (*ast.InstShl, error) {
+	nuw, nsw, err := getOverflowFlags(flags)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2338,12 +2434,16 @@ func NewShlInst(typ, xVal, yVal, mds interface{}) (*ast.InstShl, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstShl{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstShl{X: x, Y: y, NUW: nuw, NSW: nsw, Metadata: metadata}, nil
 }
 
-// NewLShrInst returns a new lshr instruction based on the given type, operands
-// and attached metadata.
-func NewLShrInst(typ, xVal, yVal, mds interface{}) (*ast.InstLShr, error) {
+// NewLShrInst returns a new lshr instruction based on the given exact flag, type,
+// operands and attached metadata.
+func NewLShrInst(exact, typ, xVal, yVal, mds interface{}) (*ast.InstLShr, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2356,12 +2456,16 @@ func NewLShrInst(typ, xVal, yVal, mds interface{}) (*ast.InstLShr, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstLShr{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstLShr{X: x, Y: y, Exact: isExact, Metadata: metadata}, nil
 }
 
-// NewAShrInst returns a new ashr instruction based on the given type, operands
-// and attached metadata.
-func NewAShrInst(typ, xVal, yVal, mds interface{}) (*ast.InstAShr, error) {
+// NewAShrInst returns a new ashr instruction based on the given exact flag, type,
+// operands and attached metadata.
+func NewAShrInst(exact, typ, xVal, yVal, mds interface{}) (*ast.InstAShr, error) {
+	isExact, err := getExact(exact)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	x, err := NewValue(typ, xVal)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -2374,7 +2478,7 @@ func NewAShrInst(typ, xVal, yVal, mds interface{}) (*ast.InstAShr, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &ast.InstAShr{X: x, Y: y, Metadata: metadata}, nil
+	return &ast.InstAShr{X: x, Y: y, Exact: isExact, Metadata: metadata}, nil
 }
 
 // NewAndInst returns a new and instruction based on the given type, operands
@@ -3030,6 +3134,20 @@ func NewSelectInst(condTyp, condVal, xTyp, xVal, yTyp, yVal, mds interface{}) (*
 	return &ast.InstSelect{Cond: cond, X: x, Y: y, Metadata: metadata}, nil
 }
 
+// NewFreezeInst returns a new freeze instruction based on the given operand
+// type and value, and attached metadata.
+func NewFreezeInst(xTyp, xVal, mds interface{}) (*ast.InstFreeze, error) {
+	x, err := NewValue(xTyp, xVal)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	metadata, err := uniqueMetadata(mds)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &ast.InstFreeze{X: x, Metadata: metadata}, nil
+}
+
 // NewCallInst returns a new call instruction based on the given return type,
 // callee name, function arguments and attached metadata.
 func NewCallInst(callconv, retTyp, callee, args, mds interface{}) (*ast.InstCall, error) {
@@ -3256,6 +3374,40 @@ func getTokenString(tok interface{}) (string, error) {
 	return string(t.Lit), nil
 }
 
+// getOverflowFlags returns the nuw and nsw flags of the given overflow flag
+// list, which is nil if no overflow flags are present.
+func getOverflowFlags(flags interface{}) (nuw, nsw bool, err error) {
+	if flags == nil {
+		return false, false, nil
+	}
+	fs, ok := flags.([]string)
+	if !ok {
+		return false, false, errors.Errorf("invalid overflow flag list type; expected []string, got %T", flags)
+	}
+	for _, f := range fs {
+		switch f {
+		case "nuw":
+			nuw = true
+		case "nsw":
+			nsw = true
+		default:
+			return false, false, errors.Errorf("support for overflow flag %q not yet implemented", f)
+		}
+	}
+	return nuw, nsw, nil
+}
+
+// getExact reports whether the given optional exact token is present.
+func getExact(exact interface{}) (bool, error) {
+	if exact == nil {
+		return false, nil
+	}
+	if _, err := getTokenString(exact); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
 // getInt64 returns the int64 representation of the given integer literal.
 func getInt64(lit interface{}) (int64, error) {
 	l, ok := lit.(*IntLit)
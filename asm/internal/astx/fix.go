@@ -31,9 +31,9 @@ import (
 
 // === [ Modules ] =============================================================
 
-// fixModule replaces dummy values within the given module with their real
+// FixModule replaces dummy values within the given module with their real
 // values.
-func fixModule(m *ast.Module) *ast.Module {
+func FixModule(m *ast.Module) *ast.Module {
 	fix := &fixer{
 		globals:  make(map[string]ast.NamedValue),
 		types:    make(map[string]*ast.NamedType),
@@ -220,6 +220,16 @@ func (fix *fixer) fixType(old ast.Type) ast.Type {
 
 // === [ Functions ] ===========================================================
 
+// FixFunc replaces dummy local identifiers within the given function with their
+// real values, leaving dummy global identifiers, types and metadata IDs
+// unresolved. It is used for function bodies parsed outside the context of
+// their module.
+func FixFunc(f *ast.Function) *ast.Function {
+	fix := &fixer{}
+	fix.fixFunc(f)
+	return f
+}
+
 // fixFunc replaces dummy values within the given function with their real
 // values.
 func (fix *fixer) fixFunc(f *ast.Function) {
@@ -58,33 +58,10 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 		}
 		return c
 	case *ast.CharArrayConst:
-		// Handle empty character array constants.
-		if len(old.Lit) == 0 {
-			typ := m.irType(old.Type)
-			t, ok := typ.(*types.ArrayType)
-			if !ok {
-				panic(errors.Errorf("invalid array type; expected *types.ArrayType, got %T", typ))
-			}
-			if t.Len != 0 {
-				panic(errors.Errorf("invalid number of array elements; expected 0, got %d", t.Len))
-			}
-			c := &constant.Array{
-				Typ:       t,
-				CharArray: true,
-			}
-			return c
-		}
-		var elems []constant.Constant
-		for i := 0; i < len(old.Lit); i++ {
-			b := int64(old.Lit[i])
-			elem := constant.NewInt(b, types.I8)
-			elems = append(elems, elem)
-		}
-		c := constant.NewArray(elems...)
+		c := constant.NewCharArrayFromString(old.Lit)
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("character array type mismatch; expected `%v`, got `%v`", want, got))
 		}
-		c.CharArray = true
 		return c
 	case *ast.StructConst:
 		var fields []constant.Constant
@@ -111,6 +88,8 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 		return constant.NewZeroInitializer(m.irType(old.Type))
 	case *ast.UndefConst:
 		return constant.NewUndef(m.irType(old.Type))
+	case *ast.PoisonConst:
+		return constant.NewPoison(m.irType(old.Type))
 
 	// Global variable and function addresses
 	case *ast.Global:
@@ -135,11 +114,21 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 			panic(fmt.Errorf("invalid function type; expected *ir.Function, got %T", v))
 		}
 		return f
+	case *ast.GlobalDummy:
+		// Dummy global identifiers remain in function bodies parsed separately
+		// from their module; resolve by name.
+		v := m.getGlobal(old.Name)
+		c, ok := v.(constant.Constant)
+		if !ok {
+			panic(fmt.Errorf("invalid global type; expected constant.Constant, got %T", v))
+		}
+		return c
 
 	// Binary expressions
 	case *ast.ExprAdd:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewAdd(x, y)
+		c.NUW, c.NSW = old.NUW, old.NSW
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("add expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -154,6 +143,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprSub:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewSub(x, y)
+		c.NUW, c.NSW = old.NUW, old.NSW
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("sub expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -168,6 +158,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprMul:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewMul(x, y)
+		c.NUW, c.NSW = old.NUW, old.NSW
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("mul expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -182,6 +173,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprUDiv:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewUDiv(x, y)
+		c.Exact = old.Exact
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("udiv expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -189,6 +181,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprSDiv:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewSDiv(x, y)
+		c.Exact = old.Exact
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("sdiv expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -226,6 +219,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprShl:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewShl(x, y)
+		c.NUW, c.NSW = old.NUW, old.NSW
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("shl expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -233,6 +227,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprLShr:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewLShr(x, y)
+		c.Exact = old.Exact
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("lshr expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -240,6 +235,7 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 	case *ast.ExprAShr:
 		x, y := m.irConstant(old.X), m.irConstant(old.Y)
 		c := constant.NewAShr(x, y)
+		c.Exact = old.Exact
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("ashr expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -318,7 +314,11 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 			index := m.irConstant(oldIndex)
 			indices = append(indices, index)
 		}
-		c := constant.NewGetElementPtr(src, indices...)
+		c, err := constant.NewGetElementPtrChecked(src, indices...)
+		if err != nil {
+			m.errs = append(m.errs, errors.Wrap(err, "invalid getelementptr expression"))
+			return constant.NewUndef(m.irType(old.Type))
+		}
 		if got, want := c.Type(), m.irType(old.Type); !got.Equal(want) {
 			m.errs = append(m.errs, errors.Errorf("getelementptr expression type mismatch; expected `%v`, got `%v`", want, got))
 		}
@@ -457,6 +457,6 @@ func (m *Module) irConstant(old ast.Constant) constant.Constant {
 		return c
 
 	default:
-		panic(fmt.Errorf("support for constant %T not yet implemented", old))
+		panic(unsupportedf("support for constant %T not yet implemented", old))
 	}
 }
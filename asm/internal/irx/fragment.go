@@ -0,0 +1,121 @@
+package irx
+
+import (
+	"github.com/llir/llvm/asm/internal/ast"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// NewModuleFrom returns a new module generator which emits code to the given
+// module. The type definitions, global identifiers and metadata of m are
+// indexed, and may thus be referred to by name from translated fragments of
+// LLVM IR (see TranslateType, TranslateConstant, TranslateInst and
+// TranslateFunc).
+func NewModuleFrom(m *ir.Module) *Module {
+	gen := &Module{
+		Module:   m,
+		types:    make(map[string]types.Type),
+		globals:  make(map[string]value.Named),
+		metadata: make(map[string]*metadata.Metadata),
+	}
+	for _, typ := range m.Types {
+		gen.types[typ.GetName()] = typ
+	}
+	for _, global := range m.Globals {
+		gen.globals[global.Name] = global
+	}
+	for _, f := range m.Funcs {
+		gen.globals[f.Name] = f
+	}
+	for _, md := range m.Metadata {
+		gen.metadata[md.ID] = md
+	}
+	return gen
+}
+
+// TranslateType translates the given type to LLVM IR. Named types are resolved
+// by name using the index of m.
+func (m *Module) TranslateType(old ast.Type) (types.Type, error) {
+	nerrs := len(m.errs)
+	typ := m.irType(old)
+	if len(m.errs) > nerrs {
+		return nil, m.errs[nerrs]
+	}
+	return typ, nil
+}
+
+// TranslateConstant translates the given constant to LLVM IR. Named types and
+// global identifiers are resolved by name using the index of m.
+func (m *Module) TranslateConstant(old ast.Constant) (constant.Constant, error) {
+	nerrs := len(m.errs)
+	c := m.irConstant(old)
+	if len(m.errs) > nerrs {
+		return nil, m.errs[nerrs]
+	}
+	return c, nil
+}
+
+// TranslateInst translates the given instruction to LLVM IR. Local identifiers
+// are resolved by name using the named function parameters, basic blocks and
+// local variables of f; and global identifiers, types and metadata IDs using
+// the index of m. The instruction is not inserted into a basic block of f.
+func (m *Module) TranslateInst(old ast.Instruction, f *ir.Function) (ir.Instruction, error) {
+	gen := m.funcGen()
+	gen.locals = make(map[string]value.Named)
+	if f != nil {
+		// Index named locals of f.
+		for _, param := range f.Params() {
+			if len(param.Name) > 0 {
+				gen.locals[param.Name] = param
+			}
+		}
+		for _, block := range f.Blocks {
+			if len(block.Name) > 0 {
+				gen.locals[block.Name] = block
+			}
+			for _, inst := range block.Insts {
+				if inst, ok := inst.(value.Named); ok && len(inst.GetName()) > 0 {
+					gen.locals[inst.GetName()] = inst
+				}
+			}
+		}
+	}
+	// Translate the instruction within a temporary basic block.
+	oldBlock := &ast.BasicBlock{
+		Insts: []ast.Instruction{old},
+		Term:  &ast.TermUnreachable{},
+	}
+	block := &ir.BasicBlock{Parent: f}
+	gen.blockBodies([]*ast.BasicBlock{oldBlock}, []*ir.BasicBlock{block})
+	if len(gen.errs) > 0 {
+		return nil, gen.errs[0]
+	}
+	if len(block.Insts) != 1 {
+		return nil, errors.Errorf("invalid number of translated instructions; expected 1, got %d", len(block.Insts))
+	}
+	inst := block.Insts[0]
+	inst.SetParent(nil)
+	return inst, nil
+}
+
+// TranslateFunc translates the given function to LLVM IR. The function is
+// added to the index of m, so that it may refer to itself, but is not appended
+// to the functions of the module.
+func (m *Module) TranslateFunc(old *ast.Function) (*ir.Function, error) {
+	if _, ok := m.globals[old.Name]; ok {
+		return nil, errors.Errorf("global identifier %q already present", old.Name)
+	}
+	f := m.newFunc(old)
+	m.globals[old.Name] = f
+	nerrs := len(m.errs)
+	m.funcDecl(old)
+	m.funcBodies([]*ast.Function{old})
+	if len(m.errs) > nerrs {
+		return nil, m.errs[nerrs]
+	}
+	return f, nil
+}
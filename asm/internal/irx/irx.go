@@ -17,6 +17,17 @@ type Module struct {
 	// Module being generated.
 	*ir.Module
 
+	// Parallelism specifies the maximum number of function bodies translated
+	// concurrently. Function bodies are translated sequentially if less than 2.
+	Parallelism int
+	// SkipUnsupported specifies whether to skip function bodies containing
+	// unsupported constructs, reporting them as warnings, rather than failing
+	// translation.
+	SkipUnsupported bool
+	// SkipUnsupportedInsts specifies whether to skip unsupported instructions
+	// (e.g. fence, va_arg) within function bodies, reporting them as warnings.
+	SkipUnsupportedInsts bool
+
 	// Per module.
 
 	// types maps from type identifiers to their corresponding LLVM IR types.
@@ -34,6 +45,8 @@ type Module struct {
 
 	// List of errors encountered during translation.
 	errs []error
+	// List of warnings encountered during translation.
+	warns []error
 }
 
 // NewModule returns a new module generator.
@@ -47,6 +60,30 @@ func NewModule() *Module {
 	}
 }
 
+// funcGen returns a new function generator of m, which shares the index of
+// type definitions, global identifiers and metadata of m, but keeps track of
+// its own local identifiers and errors.
+func (m *Module) funcGen() *Module {
+	return &Module{
+		Module:               m.Module,
+		SkipUnsupported:      m.SkipUnsupported,
+		SkipUnsupportedInsts: m.SkipUnsupportedInsts,
+		types:                m.types,
+		globals:              m.globals,
+		metadata:             m.metadata,
+	}
+}
+
+// Errors returns the list of errors encountered during translation.
+func (m *Module) Errors() []error {
+	return m.errs
+}
+
+// Warnings returns the list of warnings encountered during translation.
+func (m *Module) Warnings() []error {
+	return m.warns
+}
+
 // getType returns the type of the given type name.
 func (m *Module) getType(name string) types.Type {
 	typ, ok := m.types[name]
@@ -20,6 +20,8 @@ func (m *Module) irMetadataNode(old ast.MetadataNode) metadata.Node {
 			ID:    old.ID,
 			Nodes: nodes,
 		}
+	case *ast.MetadataIDDummy:
+		return m.getMetadata(old.ID)
 	case *ast.MetadataString:
 		return &metadata.String{
 			Val: old.Val,
@@ -36,6 +38,6 @@ func (m *Module) irMetadataNode(old ast.MetadataNode) metadata.Node {
 		}
 		return md
 	default:
-		panic(fmt.Errorf("support for metadata node %T not yet implemented", old))
+		panic(unsupportedf("support for metadata node %T not yet implemented", old))
 	}
 }
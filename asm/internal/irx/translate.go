@@ -10,6 +10,7 @@
 //    4. Fix type definitions.
 //    5. Fix globals.
 //    6. Fix functions.
+//    7. Fix function bodies; optionally in parallel.
 //
 // Per function.
 //
@@ -23,6 +24,7 @@ package irx
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/llir/llvm/asm/internal/ast"
 	"github.com/llir/llvm/internal/enc"
@@ -40,7 +42,19 @@ import (
 // module.
 func Translate(module *ast.Module) (*ir.Module, error) {
 	m := NewModule()
+	if err := m.TranslateModule(module); err != nil {
+		return nil, err
+	}
+	return m.Module, nil
+}
 
+// TranslateModule translates the AST of the given module to LLVM IR, emitting
+// code to m.
+//
+// The module generator retains its index of type definitions, global
+// identifiers and metadata after translation, and may thus be used to
+// translate function bodies parsed at a later stage (see TranslateFuncBody).
+func (m *Module) TranslateModule(module *ast.Module) error {
 	// Set target specifiers.
 	m.DataLayout = module.DataLayout
 	m.TargetTriple = module.TargetTriple
@@ -81,20 +95,7 @@ func Translate(module *ast.Module) (*ir.Module, error) {
 		if _, ok := m.globals[name]; ok {
 			panic(fmt.Errorf("global identifier %q already present; old `%v`, new `%v`", name, m.globals[name], old))
 		}
-		// Store type.
-		oldSig := m.irType(old.Sig)
-		sig, ok := oldSig.(*types.FuncType)
-		if !ok {
-			panic(fmt.Errorf("invalid function signature type, expected *types.FuncType, got %T", oldSig))
-		}
-		typ := types.NewPointer(sig)
-		f := &ir.Function{
-			Parent:   m.Module,
-			Name:     name,
-			Typ:      typ,
-			Sig:      sig,
-			Metadata: make(map[string]*metadata.Metadata),
-		}
+		f := m.newFunc(old)
 		m.Funcs = append(m.Funcs, f)
 		m.globals[name] = f
 	}
@@ -127,6 +128,9 @@ func Translate(module *ast.Module) (*ir.Module, error) {
 		m.funcDecl(f)
 	}
 
+	// Fix function bodies.
+	m.funcBodies(module.Funcs)
+
 	// Fix named metadata definitions.
 	for _, old := range module.NamedMetadata {
 		md := &metadata.Named{
@@ -150,9 +154,9 @@ func Translate(module *ast.Module) (*ir.Module, error) {
 
 	if len(m.errs) > 0 {
 		// TODO: Return a list of all errors.
-		return nil, m.errs[0]
+		return m.errs[0]
 	}
-	return m.Module, nil
+	return nil
 }
 
 // newEmptyNamedType returns an empty type definition for the given named type.
@@ -181,7 +185,7 @@ func newEmptyNamedType(old ast.Type) types.Type {
 	case *ast.NamedType:
 		return newEmptyNamedType(old.Def)
 	default:
-		panic(fmt.Errorf("support for type %T not yet implemented", old))
+		panic(unsupportedf("support for type %T not yet implemented", old))
 	}
 }
 
@@ -259,7 +263,7 @@ func (m *Module) typeDef(old *ast.NamedType) {
 		typ.Fields = d.Fields
 		typ.Opaque = d.Opaque
 	default:
-		panic(fmt.Errorf("support for type %T not yet implemented", typ))
+		panic(unsupportedf("support for type %T not yet implemented", typ))
 	}
 }
 
@@ -295,6 +299,25 @@ func (m *Module) globalDecl(old *ast.Global) {
 
 // === [ Functions ] ===========================================================
 
+// newFunc returns a new function based on the given function, with its type
+// stored.
+func (m *Module) newFunc(old *ast.Function) *ir.Function {
+	// Store type.
+	oldSig := m.irType(old.Sig)
+	sig, ok := oldSig.(*types.FuncType)
+	if !ok {
+		panic(fmt.Errorf("invalid function signature type, expected *types.FuncType, got %T", oldSig))
+	}
+	typ := types.NewPointer(sig)
+	return &ir.Function{
+		Parent:   m.Module,
+		Name:     old.Name,
+		Typ:      typ,
+		Sig:      sig,
+		Metadata: make(map[string]*metadata.Metadata),
+	}
+}
+
 // funcDecl translates the given function declaration to LLVM IR, emitting code
 // to m.
 func (m *Module) funcDecl(oldFunc *ast.Function) {
@@ -309,15 +332,145 @@ func (m *Module) funcDecl(oldFunc *ast.Function) {
 
 	// Fix attached metadata.
 	f.Metadata = m.irMetadata(oldFunc.Metadata)
+}
 
-	// Early exit if function declaration.
-	if len(oldFunc.Blocks) < 1 {
+// funcBodies translates the bodies of the given function definitions to LLVM
+// IR, emitting code to m. Function declarations are skipped, as are function
+// bodies containing unsupported constructs if m.SkipUnsupported is set.
+//
+// Function bodies are translated independently of each other, using at most
+// m.Parallelism concurrent workers. The translation result and the order of
+// reported errors are identical to that of sequential translation.
+func (m *Module) funcBodies(oldFuncs []*ast.Function) {
+	var oldDefs []*ast.Function
+	var defs []*ir.Function
+	for _, oldFunc := range oldFuncs {
+		// Skip function declarations.
+		if len(oldFunc.Blocks) < 1 {
+			continue
+		}
+		v := m.getGlobal(oldFunc.Name)
+		f, ok := v.(*ir.Function)
+		if !ok {
+			panic(fmt.Errorf("invalid function type for function %s; expected *ir.Function, got %T", enc.Global(oldFunc.Name), v))
+		}
+		oldDefs = append(oldDefs, oldFunc)
+		defs = append(defs, f)
+	}
+	// Translate each function body using a dedicated function generator.
+	gens := make([]*Module, len(defs))
+	panics := make([]interface{}, len(defs))
+	translate := func(i int) {
+		gens[i] = m.funcGen()
+		panics[i] = gens[i].tryFuncBody(oldDefs[i], defs[i])
+	}
+	if m.Parallelism < 2 || len(defs) < 2 {
+		for i := range defs {
+			translate(i)
+		}
+	} else {
+		jobs := make(chan int)
+		nworkers := m.Parallelism
+		if nworkers > len(defs) {
+			nworkers = len(defs)
+		}
+		var wg sync.WaitGroup
+		for j := 0; j < nworkers; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					translate(i)
+				}
+			}()
+		}
+		for i := range defs {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+	// Report errors and warnings in order of function definition.
+	for i, gen := range gens {
+		if panics[i] != nil {
+			gen.skipFuncBody(defs[i], panics[i])
+		}
+		m.errs = append(m.errs, gen.errs...)
+		m.warns = append(m.warns, gen.warns...)
+	}
+}
+
+// skipFuncBody handles a panic raised during the translation of the body of
+// the given function. If the panic was caused by an unsupported construct, the
+// function body is either skipped with a warning (if m.SkipUnsupported is set)
+// or reported as an error. Any other panic is propagated.
+func (m *Module) skipFuncBody(f *ir.Function, e interface{}) {
+	err, ok := e.(*UnsupportedError)
+	if !ok {
+		panic(e)
+	}
+	if !m.SkipUnsupported {
+		m.errs = append(m.errs, errors.Errorf("unable to translate body of function %s; %v", f.Ident(), err))
 		return
 	}
+	// Translate the function as a function declaration.
+	f.Blocks = nil
+	m.warns = append(m.warns, errors.Errorf("skipping body of function %s; %v", f.Ident(), err))
+}
+
+// tryFuncBody translates the body of the given function definition to LLVM IR,
+// emitting code to f. Any panic raised during translation is recovered and
+// returned, to be propagated to the caller of the worker.
+func (m *Module) tryFuncBody(oldFunc *ast.Function, f *ir.Function) (e interface{}) {
+	defer func() {
+		e = recover()
+	}()
+	m.funcBody(oldFunc, f)
+	return nil
+}
+
+// TranslateFuncBody translates the body of the given function definition to
+// LLVM IR, emitting code to f. Global identifiers, types and metadata IDs of
+// the function body are resolved by name using the index of m, and may thus be
+// dummy values of a function parsed separately from its module. Unsupported
+// constructs are handled as specified by m.SkipUnsupported and
+// m.SkipUnsupportedInsts.
+//
+// The errors and warnings encountered during translation are returned. The
+// index of m is not modified, and the bodies of distinct functions may thus be
+// translated concurrently.
+func (m *Module) TranslateFuncBody(oldFunc *ast.Function, f *ir.Function) (errs, warns []error) {
+	if len(oldFunc.Blocks) < 1 {
+		return []error{errors.Errorf("unable to translate body of function %s; missing basic blocks", f.Ident())}, nil
+	}
+	gen := m.funcGen()
+	if e := gen.tryFuncBody(oldFunc, f); e != nil {
+		gen.skipFuncBody(f, e)
+	}
+	return gen.errs, gen.warns
+}
 
+// funcBody translates the body of the given function definition to LLVM IR,
+// emitting code to f.
+func (m *Module) funcBody(oldFunc *ast.Function, f *ir.Function) {
 	// Reset locals.
 	m.locals = make(map[string]value.Named)
 
+	// Skip unsupported instructions.
+	if m.SkipUnsupportedInsts {
+		for _, old := range oldFunc.Blocks {
+			var insts []ast.Instruction
+			for _, inst := range old.Insts {
+				if _, ok := inst.(*ast.InstUnsupported); ok {
+					m.warns = append(m.warns, errors.Errorf("skipping unsupported instruction (e.g. fence, va_arg) in function %s", f.Ident()))
+					continue
+				}
+				insts = append(insts, inst)
+			}
+			old.Insts = insts
+		}
+	}
+
 	// Index function parameters.
 	for _, param := range f.Params() {
 		name := param.Name
@@ -341,10 +494,18 @@ func (m *Module) funcDecl(oldFunc *ast.Function) {
 		m.locals[name] = block
 	}
 
+	m.blockBodies(oldFunc.Blocks, f.Blocks)
+}
+
+// blockBodies translates the instructions and terminators of the given basic
+// blocks to LLVM IR, emitting code to blocks. Local identifiers are resolved
+// using m.locals, which is extended with the local variables produced by the
+// instructions.
+func (m *Module) blockBodies(oldBlocks []*ast.BasicBlock, blocks []*ir.BasicBlock) {
 	// Index local variables produced by instructions.
-	for i := 0; i < len(oldFunc.Blocks); i++ {
-		oldBlock := oldFunc.Blocks[i]
-		block := f.Blocks[i]
+	for i := 0; i < len(oldBlocks); i++ {
+		oldBlock := oldBlocks[i]
+		block := blocks[i]
 		for _, oldInst := range oldBlock.Insts {
 			var inst ir.Instruction
 			switch oldInst := oldInst.(type) {
@@ -582,14 +743,23 @@ func (m *Module) funcDecl(oldFunc *ast.Function) {
 					Parent: block,
 					Name:   oldInst.Name,
 				}
+			case *ast.InstFreeze:
+				inst = &ir.InstFreeze{
+					Parent: block,
+					Name:   oldInst.Name,
+				}
 			case *ast.InstCall:
 				inst = &ir.InstCall{
 					Parent: block,
 					Name:   oldInst.Name,
 				}
 
+			// Unsupported instructions
+			case *ast.InstUnsupported:
+				panic(unsupportedf("support for instruction (e.g. fence, va_arg) not yet implemented"))
+
 			default:
-				panic(fmt.Errorf("support for instruction %T not yet implemented", oldInst))
+				panic(unsupportedf("support for instruction %T not yet implemented", oldInst))
 			}
 			block.Insts = append(block.Insts, inst)
 
@@ -616,9 +786,9 @@ func (m *Module) funcDecl(oldFunc *ast.Function) {
 	}
 
 	// Fix basic blocks.
-	for i := 0; i < len(oldFunc.Blocks); i++ {
-		oldBlock := oldFunc.Blocks[i]
-		block := f.Blocks[i]
+	for i := 0; i < len(oldBlocks); i++ {
+		oldBlock := oldBlocks[i]
+		block := blocks[i]
 		m.basicBlock(oldBlock, block)
 	}
 }
@@ -650,6 +820,10 @@ func (m *Module) metadataNode(oldNode ast.MetadataNode) metadata.Node {
 			md.Nodes = append(md.Nodes, n)
 		}
 		return md
+	case *ast.MetadataIDDummy:
+		// Dummy metadata IDs remain in function bodies parsed separately from
+		// their module; resolve by ID.
+		return m.getMetadata(oldNode.ID)
 	case *ast.MetadataString:
 		return &metadata.String{
 			Val: oldNode.Val,
@@ -666,7 +840,7 @@ func (m *Module) metadataNode(oldNode ast.MetadataNode) metadata.Node {
 		}
 		return md
 	default:
-		panic(fmt.Errorf("support for metadata node type %T not yet implemented", oldNode))
+		panic(unsupportedf("support for metadata node type %T not yet implemented", oldNode))
 	}
 }
 
@@ -709,6 +883,8 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.NUW = oldInst.NUW
+			inst.NSW = oldInst.NSW
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstFAdd:
 			inst, ok := v.(*ir.InstFAdd)
@@ -725,6 +901,8 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.NUW = oldInst.NUW
+			inst.NSW = oldInst.NSW
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstFSub:
 			inst, ok := v.(*ir.InstFSub)
@@ -741,6 +919,8 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.NUW = oldInst.NUW
+			inst.NSW = oldInst.NSW
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstFMul:
 			inst, ok := v.(*ir.InstFMul)
@@ -757,6 +937,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.Exact = oldInst.Exact
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstSDiv:
 			inst, ok := v.(*ir.InstSDiv)
@@ -765,6 +946,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.Exact = oldInst.Exact
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstFDiv:
 			inst, ok := v.(*ir.InstFDiv)
@@ -807,6 +989,8 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.NUW = oldInst.NUW
+			inst.NSW = oldInst.NSW
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstLShr:
 			inst, ok := v.(*ir.InstLShr)
@@ -815,6 +999,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.Exact = oldInst.Exact
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstAShr:
 			inst, ok := v.(*ir.InstAShr)
@@ -823,6 +1008,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			}
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
+			inst.Exact = oldInst.Exact
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstAnd:
 			inst, ok := v.(*ir.InstAnd)
@@ -878,9 +1064,20 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			if !ok {
 				panic(fmt.Errorf("invalid instruction type; expected *ir.InstShuffleVector, got %T", v))
 			}
-			inst.X = m.irValue(oldInst.X)
+			x := m.irValue(oldInst.X)
+			xt, ok := x.Type().(*types.VectorType)
+			if !ok {
+				panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", x.Type()))
+			}
+			mask := m.irValue(oldInst.Mask)
+			mt, ok := mask.Type().(*types.VectorType)
+			if !ok {
+				panic(fmt.Errorf("invalid shuffle mask type; expected *types.VectorType, got %T", mask.Type()))
+			}
+			inst.Typ = types.NewVector(xt.Elem, mt.Len)
+			inst.X = x
 			inst.Y = m.irValue(oldInst.Y)
-			inst.Mask = m.irValue(oldInst.Mask)
+			inst.Mask = mask
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 
 		// Aggregate instructions
@@ -978,6 +1175,8 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 					panic("unable to index into element of pointer type; for more information, see http://llvm.org/docs/GetElementPtr.html#what-is-dereferenced-by-gep")
 				case *types.ArrayType:
 					e = t.Elem
+				case *types.VectorType:
+					e = t.Elem
 				case *types.StructType:
 					idx, ok := index.(*constant.Int)
 					if !ok {
@@ -985,7 +1184,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 					}
 					e = t.Fields[idx.Int64()]
 				default:
-					panic(fmt.Errorf("support for indexing element type %T not yet implemented", e))
+					panic(unsupportedf("support for indexing element type %T not yet implemented", e))
 				}
 			}
 			typ := types.NewPointer(e)
@@ -1165,6 +1364,13 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			inst.X = m.irValue(oldInst.X)
 			inst.Y = m.irValue(oldInst.Y)
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
+		case *ast.InstFreeze:
+			inst, ok := v.(*ir.InstFreeze)
+			if !ok {
+				panic(fmt.Errorf("invalid instruction type; expected *ir.InstFreeze, got %T", v))
+			}
+			inst.X = m.irValue(oldInst.X)
+			inst.Metadata = m.irMetadata(oldInst.Metadata)
 		case *ast.InstCall:
 			inst, ok := v.(*ir.InstCall)
 			if !ok {
@@ -1190,7 +1396,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			inst.Metadata = m.irMetadata(oldInst.Metadata)
 
 		default:
-			panic(fmt.Errorf("support for instruction %T not yet implemented", oldInst))
+			panic(unsupportedf("support for instruction %T not yet implemented", oldInst))
 		}
 	}
 
@@ -1215,6 +1421,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 			panic(fmt.Errorf("invalid target branch type, expected *ir.BasicBlock, got %T", v))
 		}
 		term.Target = target
+		term.Successors = []*ir.BasicBlock{target}
 		term.Metadata = m.irMetadata(oldTerm.Metadata)
 		block.Term = term
 	case *ast.TermCondBr:
@@ -1278,7 +1485,7 @@ func (m *Module) basicBlock(oldBlock *ast.BasicBlock, block *ir.BasicBlock) {
 		term.Metadata = m.irMetadata(oldTerm.Metadata)
 		block.Term = term
 	default:
-		panic(fmt.Errorf("support for terminator %T not yet implemented", oldTerm))
+		panic(unsupportedf("support for terminator %T not yet implemented", oldTerm))
 	}
 }
 
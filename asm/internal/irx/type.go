@@ -37,7 +37,7 @@ func (m *Module) irType(old ast.Type) types.Type {
 		case ast.FloatKindDoubleDouble_128:
 			return types.PPC_FP128
 		default:
-			panic(fmt.Errorf("support for %v not yet implemented", old.Kind))
+			panic(unsupportedf("support for %v not yet implemented", old.Kind))
 		}
 	case *ast.PointerType:
 		typ := types.NewPointer(m.irType(old.Elem))
@@ -66,7 +66,7 @@ func (m *Module) irType(old ast.Type) types.Type {
 	case *ast.TypeDummy:
 		panic("invalid type *ast.TypeDummy; dummy types should have been translated during parsing by astx")
 	default:
-		panic(fmt.Errorf("support for %T not yet implemented", old))
+		panic(unsupportedf("support for %T not yet implemented", old))
 	}
 }
 
@@ -35,7 +35,7 @@ func irIntPred(cond ast.IntPred) ir.IntPred {
 	case ast.IntSLE:
 		return ir.IntSLE
 	}
-	panic(fmt.Errorf("support for integer predicate %v not yet implemented", cond))
+	panic(unsupportedf("support for integer predicate %v not yet implemented", cond))
 }
 
 // irFloatPred returns the corresponding LLVM IR floating-point predicate of the
@@ -75,7 +75,7 @@ func irFloatPred(cond ast.FloatPred) ir.FloatPred {
 	case ast.FloatTrue:
 		return ir.FloatTrue
 	}
-	panic(fmt.Errorf("support for floating-point predicate %v not yet implemented", cond))
+	panic(unsupportedf("support for floating-point predicate %v not yet implemented", cond))
 }
 
 // irMetadata returns the corresponding LLVM IR metadata of the given list of
@@ -96,3 +96,20 @@ func (m *Module) irMetadata(oldMDs []*ast.AttachedMD) map[string]*metadata.Metad
 	}
 	return mds
 }
+
+// An UnsupportedError reports a construct not yet supported by the translator.
+type UnsupportedError struct {
+	// Error message.
+	Msg string
+}
+
+// Error returns the error message of the unsupported construct.
+func (e *UnsupportedError) Error() string {
+	return e.Msg
+}
+
+// unsupportedf returns a new error reporting an unsupported construct, with the
+// error message formatted according to a format specifier.
+func unsupportedf(format string, args ...interface{}) error {
+	return &UnsupportedError{Msg: fmt.Sprintf(format, args...)}
+}
@@ -1,8 +1,6 @@
 package irx
 
 import (
-	"fmt"
-
 	"github.com/llir/llvm/asm/internal/ast"
 	"github.com/llir/llvm/ir/value"
 )
@@ -23,12 +21,12 @@ func (m *Module) irValue(old ast.Value) value.Value {
 		case *ast.Param, *ast.BasicBlock, *ast.LocalDummy, ast.Instruction:
 			return m.getLocal(old.GetName())
 		default:
-			panic(fmt.Errorf("support for named value %T not yet implemented", old))
+			panic(unsupportedf("support for named value %T not yet implemented", old))
 		}
 	// Metadata node.
 	case ast.MetadataNode:
 		return m.irMetadataNode(old)
 	default:
-		panic(fmt.Errorf("support for value %T not yet implemented", old))
+		panic(unsupportedf("support for value %T not yet implemented", old))
 	}
 }
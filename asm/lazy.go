@@ -0,0 +1,207 @@
+package asm
+
+import (
+	"bytes"
+
+	"github.com/llir/llvm/asm/internal/astx"
+	"github.com/llir/llvm/asm/internal/irx"
+	"github.com/llir/llvm/asm/internal/lexer"
+	"github.com/llir/llvm/asm/internal/token"
+	"github.com/llir/llvm/ir"
+	"github.com/pkg/errors"
+)
+
+// stubBody is the function body used in place of lazily parsed function bodies
+// when parsing the skeleton of a module.
+const stubBody = "{\n\tunreachable\n}"
+
+// translateLazy parses the given LLVM IR assembly file, reading from b, and
+// translates it to LLVM IR, emitting code to gen. The parsing and translation
+// of function bodies is deferred until first accessed, after which materialized
+// is invoked with the function, if non-nil. Errors and warnings of function
+// bodies are reported at materialization, as specified by opts.
+func translateLazy(b []byte, gen *irx.Module, opts ParseOptions, materialized func(f *ir.Function)) error {
+	toks, ok := tokenize(b)
+	if !ok {
+		// Let the parser report the syntax error.
+		return translate(b, gen)
+	}
+	bodies, ok := scanFuncBodies(toks)
+	if !ok {
+		// Let the parser report the syntax error.
+		return translate(b, gen)
+	}
+	// Parse the skeleton of the module, with the body of each function
+	// definition replaced by a stub.
+	skeleton := &bytes.Buffer{}
+	prev := 0
+	for _, body := range bodies {
+		skeleton.Write(b[prev:body.lbrace])
+		skeleton.WriteString(stubBody)
+		prev = body.end
+	}
+	skeleton.Write(b[prev:])
+	if err := translate(skeleton.Bytes(), gen); err != nil {
+		return errors.WithStack(err)
+	}
+	// Record the byte range of each function body, to be materialized on first
+	// access.
+	l := &loader{gen: gen, opts: opts, materialized: materialized}
+	i := 0
+	for _, f := range gen.Funcs {
+		if len(f.Blocks) == 0 {
+			// Function declaration.
+			continue
+		}
+		if i >= len(bodies) {
+			return errors.Errorf("unable to locate body of function %s", f.Ident())
+		}
+		body := bodies[i]
+		i++
+		src := b[body.start:body.end]
+		f.Blocks = nil
+		f.SetMaterializer(func(f *ir.Function) error {
+			return l.materialize(f, src)
+		})
+	}
+	return nil
+}
+
+// A loader materializes lazily parsed function bodies.
+type loader struct {
+	// gen is the module generator used to translate the skeleton of the module.
+	gen *irx.Module
+	// opts specifies how errors and warnings of function bodies are reported.
+	opts ParseOptions
+	// materialized is invoked after the materialization of a function body; or
+	// nil if not used.
+	materialized func(f *ir.Function)
+}
+
+// materialize parses and translates the body of the given function, based on
+// the source of its function definition.
+func (l *loader) materialize(f *ir.Function, src []byte) error {
+	module, err := parseModule(src)
+	if err != nil {
+		return l.opts.errs([]error{errors.WithStack(err)})
+	}
+	if len(module.Funcs) != 1 {
+		return l.opts.errs([]error{errors.Errorf("invalid number of function definitions in body of function %s; expected 1, got %d", f.Ident(), len(module.Funcs))})
+	}
+	oldFunc := astx.FixFunc(module.Funcs[0])
+	errs, warns := l.gen.TranslateFuncBody(oldFunc, f)
+	l.opts.warn(warns)
+	if len(errs) > 0 {
+		return l.opts.errs(errs)
+	}
+	if l.materialized != nil {
+		l.materialized(f)
+	}
+	return nil
+}
+
+// funcBody records the byte range of a function definition.
+type funcBody struct {
+	// Start offset of the function definition (i.e. of the "define" keyword).
+	start int
+	// Start offset of the function body (i.e. of the opening brace).
+	lbrace int
+	// End offset of the function body (i.e. past the closing brace).
+	end int
+}
+
+// Token types used to locate function bodies.
+var (
+	tokDefine         = token.TokMap.Type("define")
+	tokAssign         = token.TokMap.Type("=")
+	tokLBrace         = token.TokMap.Type("{")
+	tokRBrace         = token.TokMap.Type("}")
+	tokLParen         = token.TokMap.Type("(")
+	tokRParen         = token.TokMap.Type(")")
+	tokLBrack         = token.TokMap.Type("[")
+	tokRBrack         = token.TokMap.Type("]")
+	tokDeclare        = token.TokMap.Type("declare")
+	tokAttributes     = token.TokMap.Type("attributes")
+	tokSourceFilename = token.TokMap.Type("source_filename")
+	tokTarget         = token.TokMap.Type("target")
+	tokModule         = token.TokMap.Type("module")
+)
+
+// Token types of identifiers which start a top-level entity when followed by
+// "=".
+var tokTopLevelIdents = map[token.Type]bool{
+	token.TokMap.Type("global_ident"):  true,
+	token.TokMap.Type("local_ident"):   true,
+	token.TokMap.Type("comdat_name"):   true,
+	token.TokMap.Type("metadata_name"): true,
+	token.TokMap.Type("metadata_id"):   true,
+}
+
+// tokenize returns the tokens of the given LLVM IR assembly file, terminated
+// by an EOF token. The boolean return value indicates success, and is false if
+// the source contains invalid tokens.
+func tokenize(src []byte) ([]*token.Token, bool) {
+	var toks []*token.Token
+	l := lexer.NewLexer(src)
+	for {
+		tok := l.Scan()
+		if tok.Type == token.INVALID {
+			return nil, false
+		}
+		toks = append(toks, tok)
+		if tok.Type == token.EOF {
+			return toks, true
+		}
+	}
+}
+
+// isTopLevel reports whether the token at index i starts a top-level entity or
+// marks the end of the file, assuming that the token is not nested within
+// brackets.
+func isTopLevel(toks []*token.Token, i int) bool {
+	switch typ := toks[i].Type; typ {
+	case token.EOF, tokDefine, tokDeclare, tokAttributes, tokSourceFilename, tokTarget, tokModule:
+		return true
+	default:
+		return tokTopLevelIdents[typ] && i+1 < len(toks) && toks[i+1].Type == tokAssign
+	}
+}
+
+// scanFuncBodies locates the byte range of each function definition of the
+// given tokens of an LLVM IR assembly file. The boolean return value indicates
+// success, and is false if the brackets of the source are unbalanced.
+func scanFuncBodies(toks []*token.Token) ([]funcBody, bool) {
+	var bodies []funcBody
+	depth := 0
+	inDefine := false
+	var body funcBody
+	for i, tok := range toks {
+		switch tok.Type {
+		case tokDefine:
+			if depth == 0 {
+				inDefine = true
+				body = funcBody{start: tok.Offset}
+			}
+		case tokLBrace:
+			if depth == 0 {
+				body.lbrace = tok.Offset
+			}
+			depth++
+		case tokLParen, tokLBrack:
+			depth++
+		case tokRParen, tokRBrack:
+			depth--
+		case tokRBrace:
+			depth--
+			if depth == 0 && inDefine && isTopLevel(toks, i+1) {
+				body.end = tok.Offset + len(tok.Lit)
+				bodies = append(bodies, body)
+				inDefine = false
+			}
+		}
+		if depth < 0 {
+			return nil, false
+		}
+	}
+	return bodies, true
+}
@@ -0,0 +1,113 @@
+package asm_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestParseLazyFuncBodies(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.ll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		want, err := asm.ParseFile(path)
+		if err != nil {
+			t.Errorf("%q: unable to parse file; %v", path, err)
+			continue
+		}
+		opts := asm.ParseOptions{LazyFuncBodies: true}
+		m, err := asm.ParseFileOptions(path, opts)
+		if err != nil {
+			t.Errorf("%q: unable to lazily parse file; %v", path, err)
+			continue
+		}
+		for i, f := range m.Funcs {
+			if len(want.Funcs[i].Blocks) == 0 {
+				continue
+			}
+			if !f.IsMaterializable() {
+				t.Errorf("%q: function %s not materializable before first access", path, f.Ident())
+			}
+			if len(f.Blocks) != 0 {
+				t.Errorf("%q: function %s materialized before first access", path, f.Ident())
+			}
+		}
+		got := m.String()
+		if got != want.String() {
+			t.Errorf("%q: module mismatch; expected `%v`, got `%v`", path, want, got)
+		}
+		for _, f := range m.Funcs {
+			if f.IsMaterializable() {
+				t.Errorf("%q: function %s not materialized after first access", path, f.Ident())
+			}
+		}
+	}
+}
+
+func TestParseLazyFuncBodiesMaterialize(t *testing.T) {
+	const src = `
+%t = type { i32, i32 }
+
+define { i32, i32 } @f({ i32, i32 } %x) {
+	ret { i32, i32 } %x
+}
+
+@g = global i32 0
+
+define i32 @h() {
+; entry
+	%1 = load i32, i32* @g
+	%2 = call i32 @h()
+	%3 = add i32 %1, %2
+	ret i32 %3
+}
+`
+	m, err := asm.ParseBytesOptions([]byte(src), asm.ParseOptions{LazyFuncBodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := m.Funcs[1]
+	if err := h.Materialize(); err != nil {
+		t.Fatalf("unable to materialize function %s; %v", h.Ident(), err)
+	}
+	if got, want := len(h.Blocks), 1; got != want {
+		t.Fatalf("number of basic blocks mismatch; expected %d, got %d", want, got)
+	}
+	if got, want := len(h.Blocks[0].Insts), 3; got != want {
+		t.Errorf("number of instructions mismatch; expected %d, got %d", want, got)
+	}
+	if !m.Funcs[0].IsMaterializable() {
+		t.Errorf("function %s materialized before first access", m.Funcs[0].Ident())
+	}
+}
+
+func TestParseLazyFuncBodiesError(t *testing.T) {
+	const src = `
+define i32 @f() {
+	ret i32 ,
+}
+`
+	m, err := asm.ParseBytesOptions([]byte(src), asm.ParseOptions{LazyFuncBodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	if v := f.Local("x"); v != nil {
+		t.Errorf("unexpected local %v", v.Ident())
+	}
+	// Materialization errors are written in place of the function body.
+	got := m.String()
+	if !strings.Contains(got, "define i32 @f() {\n; unable to materialize body of function @f;") {
+		t.Errorf("missing materialization error in module; got `%v`", got)
+	}
+	if got := f.String(); !strings.HasPrefix(got, "define i32 @f() {\n; ") {
+		t.Errorf("missing materialization error in function; got `%v`", got)
+	}
+	if err := f.Materialize(); err == nil {
+		t.Errorf("expected materialization error, got nil")
+	}
+}
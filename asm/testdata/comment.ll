@@ -0,0 +1,36 @@
+; ModuleID = 'comment.c'
+; RUN: llvm-as < %s | llvm-dis | FileCheck %s
+target triple = "x86_64-unknown-linux-gnu"
+
+; Type definitions.
+%T = type { i32, i32 }
+%U = type { %T, i8* } ; trailing comment of type definition
+
+; CHECK: @x = global i32 42
+@x = global i32 42
+@y = global i32 0 ; trailing comment of global
+
+; CHECK-LABEL: @f(
+define i32 @f(i32 %a) {
+; entry block
+entry:
+	; CHECK: add
+	%b = add i32 %a, 1 ; trailing comment of instruction
+
+	%c = mul i32 %b, 2
+	br label %exit ; trailing comment of terminator
+
+; exit block
+exit: ; trailing comment of label
+	; CHECK: ret
+	ret i32 %c
+	; end of function body
+} ; trailing comment of function
+
+declare i32 @g(i32)
+
+!llvm.ident = !{!0}
+
+!0 = !{!"clang"} ; trailing comment of metadata
+
+; end of file
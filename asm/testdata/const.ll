@@ -38,11 +38,23 @@
 ; Hexadecimal floating-point literal.
 @g27 = global double 0x0000000000000000
 @g28 = global x86_fp80 0xK00000000000000000000
-; TODO: add test case for 0xL floating-point constant.
-;@g29 = global fp128 0xL00000000000000000000000000000000
-; TODO: add test case for 0xM floating-point constant.
-;@g30 = global ppc_fp128 0xM00000000000000000000000000000000
+@g29 = global fp128 0xL00000000000000000000000000000000
+@g30 = global ppc_fp128 0xM00000000000000000000000000000000
 @g31 = global half 0xH0000
+@g31.1 = global x86_fp80 0xK3FFF8000000000000001
+@g31.2 = global fp128 0xL00000000000000013FFF000000000000
+@g31.3 = global ppc_fp128 0xM3FF00000000000003C80000000000000
+@g31.4 = global half 0xH3555
+
+; Special floating-point values.
+@g31.5 = global double 0x7FF0000000000000
+@g31.6 = global float 0xFFF0000000000000
+@g31.7 = global double 0x7FF8000000000001
+@g31.8 = global float 0x7FF4000000000000
+@g31.9 = global x86_fp80 0xKFFFFC000000000000000
+@g31.10 = global fp128 0xL00000000000000007FFF800000000000
+@g31.11 = global half 0xH7E00
+@g31.12 = global double -0.0
 
 ; --- [ Pointer constant ] -----------------------------------------------------
 
@@ -95,6 +107,10 @@
 
 @g51 = global i8* undef
 
+; --- [ Poison value constant ] ------------------------------------------------
+
+@g52 = global i8* poison
+
 define void @f1() {
 	ret void
 }
@@ -6,6 +6,10 @@ define i32 @add_1() {
 	ret i32 add (i32 30, i32 12)
 }
 
+define i32 @add_2() {
+	ret i32 add nuw nsw (i32 30, i32 12)
+}
+
 ; ~~~ [ fadd ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define double @fadd_1() {
@@ -18,6 +22,10 @@ define i32 @sub_1() {
 	ret i32 sub (i32 50, i32 8)
 }
 
+define i32 @sub_2() {
+	ret i32 sub nuw (i32 50, i32 8)
+}
+
 ; ~~~ [ fsub ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define double @fsub_1() {
@@ -30,6 +38,10 @@ define i32 @mul_1() {
 	ret i32 mul (i32 21, i32 2)
 }
 
+define i32 @mul_2() {
+	ret i32 mul nsw (i32 21, i32 2)
+}
+
 ; ~~~ [ fmul ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define double @fmul_1() {
@@ -42,12 +54,20 @@ define i32 @udiv_1() {
 	ret i32 udiv (i32 84, i32 2)
 }
 
+define i32 @udiv_2() {
+	ret i32 udiv exact (i32 84, i32 2)
+}
+
 ; ~~~ [ sdiv ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define i32 @sdiv_1() {
 	ret i32 sdiv (i32 -84, i32 -2)
 }
 
+define i32 @sdiv_2() {
+	ret i32 sdiv exact (i32 -84, i32 -2)
+}
+
 ; ~~~ [ fdiv ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define double @fdiv_1() {
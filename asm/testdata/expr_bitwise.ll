@@ -6,18 +6,30 @@ define i32 @shl_1() {
 	ret i32 shl (i32 21, i32 1)
 }
 
+define i32 @shl_2() {
+	ret i32 shl nuw nsw (i32 21, i32 1)
+}
+
 ; ~~~ [ lshr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define i32 @lshr_1() {
 	ret i32 lshr (i32 84, i32 1)
 }
 
+define i32 @lshr_2() {
+	ret i32 lshr exact (i32 84, i32 1)
+}
+
 ; ~~~ [ ashr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define i32 @ashr_1() {
 	ret i32 ashr (i32 84, i32 1)
 }
 
+define i32 @ashr_2() {
+	ret i32 ashr exact (i32 84, i32 1)
+}
+
 ; ~~~ [ and ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define i32 @and_1() {
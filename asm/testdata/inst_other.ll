@@ -160,6 +160,26 @@ define i32 @select_3(i1 %cond) {
 	ret i32 %result
 }
 
+; ~~~ [ freeze ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+define i32 @freeze_1(i32 %x) {
+	; Plain instruction.
+	%result = freeze i32 %x
+	ret i32 %result
+}
+
+define <2 x i32> @freeze_2() {
+	; Vector operand.
+	%result = freeze <2 x i32> <i32 42, i32 poison>
+	ret <2 x i32> %result
+}
+
+define i32 @freeze_3() {
+	; Metadata.
+	%result = freeze i32 poison, !foo !{!"bar"}, !baz !{!"qux"}
+	ret i32 %result
+}
+
 ; ~~~ [ call ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 define i32 @f() {
@@ -0,0 +1,184 @@
+package floats
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// DoubleDouble represents a 128-bit PowerPC double-double floating-point
+// value, the value of which is the sum of two IEEE 754 double-precision
+// floating-point values.
+//
+// References:
+//    https://en.wikipedia.org/wiki/Quadruple-precision_floating-point_format#Double-double_arithmetic
+type DoubleDouble struct {
+	// High-order double, in binary64 format.
+	//
+	//    1 bit:   sign
+	//    11 bits: exponent
+	//    52 bits: fraction
+	hi uint64
+	// Low-order double, in binary64 format.
+	lo uint64
+}
+
+// doubleDoublePrec is a precision sufficient to represent the sum of any two
+// float64 values exactly, and the product of any two such sums.
+const doubleDoublePrec = 4400
+
+// Bits returns the binary representation of f, with the binary64
+// representation of the high-order double in hi and of the low-order double in
+// lo.
+func (f DoubleDouble) Bits() (hi, lo uint64) {
+	return f.hi, f.lo
+}
+
+// Bytes returns the binary representation of f as a byte slice, containing 32
+// bytes in hexadecimal format.
+func (f DoubleDouble) Bytes() []byte {
+	return []byte(f.String())
+}
+
+// String returns the binary representation of f as a string, containing 32
+// bytes in hexadecimal format; the high-order double followed by the low-order
+// double.
+func (f DoubleDouble) String() string {
+	return fmt.Sprintf("%016X%016X", f.hi, f.lo)
+}
+
+// Float64 returns the nearest float64 representation of f. The sign and
+// payload of NaN values are preserved.
+func (f DoubleDouble) Float64() float64 {
+	if f.IsNaN() {
+		return math.Float64frombits(f.hi)
+	}
+	x, _ := f.Big().Float64()
+	return x
+}
+
+// IsNaN reports whether f is an IEEE 754 "not-a-number" value; i.e. whether
+// the high-order double of f is NaN.
+func (f DoubleDouble) IsNaN() bool {
+	return math.IsNaN(math.Float64frombits(f.hi))
+}
+
+// Big returns the exact big.Float representation of f. It panics if f is NaN.
+func (f DoubleDouble) Big() *big.Float {
+	if f.IsNaN() {
+		panic("unable to represent NaN as big.Float")
+	}
+	hi, lo := math.Float64frombits(f.hi), math.Float64frombits(f.lo)
+	if math.IsInf(hi, 0) || lo == 0 {
+		return big.NewFloat(hi)
+	}
+	x := new(big.Float).SetPrec(doubleDoublePrec).SetFloat64(hi)
+	x.Add(x, big.NewFloat(lo))
+	return x.SetPrec(x.MinPrec())
+}
+
+// Add returns the sum f+g, rounded to the nearest double-double value.
+func (f DoubleDouble) Add(g DoubleDouble) DoubleDouble {
+	return f.arith(g, (*big.Float).Add)
+}
+
+// Sub returns the difference f-g, rounded to the nearest double-double value.
+func (f DoubleDouble) Sub(g DoubleDouble) DoubleDouble {
+	return f.arith(g, (*big.Float).Sub)
+}
+
+// Mul returns the product f*g, rounded to the nearest double-double value.
+func (f DoubleDouble) Mul(g DoubleDouble) DoubleDouble {
+	return f.arith(g, (*big.Float).Mul)
+}
+
+// Div returns the quotient f/g, rounded to the nearest double-double value.
+func (f DoubleDouble) Div(g DoubleDouble) DoubleDouble {
+	return f.arith(g, (*big.Float).Quo)
+}
+
+// Neg returns f with its sign inverted.
+func (f DoubleDouble) Neg() DoubleDouble {
+	const sign = 1 << 63
+	return NewDoubleDoubleFromBits(f.hi^sign, f.lo^sign)
+}
+
+// Cmp compares f and g and returns -1, 0 or +1 depending on whether f is less
+// than, equal to or greater than g. It panics if f or g is NaN.
+func (f DoubleDouble) Cmp(g DoubleDouble) int {
+	return f.Big().Cmp(g.Big())
+}
+
+// arith returns the result of the arithmetic operation op on f and g, rounded
+// to the nearest double-double value. NaN operands are propagated as quiet
+// NaNs, and invalid operations (e.g. 0/0 or Inf-Inf) result in the default
+// quiet NaN.
+func (f DoubleDouble) arith(g DoubleDouble, op func(z, x, y *big.Float) *big.Float) (h DoubleDouble) {
+	const quiet = 1 << 51
+	switch {
+	case f.IsNaN():
+		return NewDoubleDoubleFromBits(f.hi|quiet, f.lo)
+	case g.IsNaN():
+		return NewDoubleDoubleFromBits(g.hi|quiet, g.lo)
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(big.ErrNaN); !ok {
+				panic(e)
+			}
+			h = NewDoubleDoubleFromFloat64(math.NaN())
+		}
+	}()
+	z := new(big.Float).SetPrec(doubleDoublePrec)
+	h, _ = NewDoubleDoubleFromBig(op(z, f.Big(), g.Big()))
+	return h
+}
+
+// NewDoubleDoubleFromBig returns the nearest double-double value for x and a
+// bool indicating whether f represents x exactly. The high-order double of f is
+// the nearest float64 value of x, and the low-order double is the nearest
+// float64 value of the remainder.
+func NewDoubleDoubleFromBig(x *big.Float) (f DoubleDouble, exact bool) {
+	hi, _ := x.Float64()
+	if math.IsInf(hi, 0) {
+		return NewDoubleDoubleFromFloat64(hi), x.IsInf()
+	}
+	rem := new(big.Float).SetPrec(doubleDoublePrec).Sub(x, big.NewFloat(hi))
+	lo, acc := rem.Float64()
+	return NewDoubleDoubleFromBits(math.Float64bits(hi), math.Float64bits(lo)), acc == big.Exact
+}
+
+// NewDoubleDoubleFromFloat64 returns the double-double value of x, which is
+// represented exactly. The sign and payload of NaN values are preserved.
+func NewDoubleDoubleFromFloat64(x float64) DoubleDouble {
+	return NewDoubleDoubleFromBits(math.Float64bits(x), 0)
+}
+
+// NewDoubleDoubleFromString returns a new double-double value based on s,
+// which contains 32 bytes in hexadecimal format.
+func NewDoubleDoubleFromString(s string) DoubleDouble {
+	return NewDoubleDoubleFromBytes([]byte(s))
+}
+
+// NewDoubleDoubleFromBytes returns a new double-double value based on b, which
+// contains 32 bytes in hexadecimal format.
+func NewDoubleDoubleFromBytes(b []byte) DoubleDouble {
+	var f DoubleDouble
+	if len(b) != 32 {
+		panic(fmt.Errorf("invalid length of double-double hexadecimal representation, expected 32, got %d", len(b)))
+	}
+	for i := 0; i < 16; i++ {
+		f.hi = f.hi<<4 | unhex(b[i])
+		f.lo = f.lo<<4 | unhex(b[16+i])
+	}
+	return f
+}
+
+// NewDoubleDoubleFromBits returns a new double-double value based on the
+// binary64 representation of its high-order and low-order doubles.
+func NewDoubleDoubleFromBits(hi, lo uint64) DoubleDouble {
+	return DoubleDouble{
+		hi: hi,
+		lo: lo,
+	}
+}
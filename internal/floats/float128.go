@@ -1,5 +1,11 @@
 package floats
 
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
 // Float128 represents a 128-bit IEEE 754 quadruple-precision floating-point
 // value, in binary128 format.
 //
@@ -14,7 +20,137 @@ type Float128 struct {
 	a, b uint64
 }
 
-// Bytes returns the IEEE 754 binary representation of f as a byte slice.
+// Bits returns the IEEE 754 binary representation of f, with the sign, exponent
+// and most significant bits of the fraction in a and the least significant bits
+// of the fraction in b.
+func (f Float128) Bits() (a, b uint64) {
+	return f.a, f.b
+}
+
+// Bytes returns the IEEE 754 binary representation of f as a byte slice,
+// containing 32 bytes in hexadecimal format.
 func (f Float128) Bytes() []byte {
-	panic("not yet implemented")
+	return []byte(f.String())
+}
+
+// String returns the IEEE 754 binary representation of f as a string,
+// containing 32 bytes in hexadecimal format.
+func (f Float128) String() string {
+	return fmt.Sprintf("%016X%016X", f.a, f.b)
+}
+
+// Float64 returns the nearest float64 representation of f. The sign and the
+// most significant bits of the payload of NaN values are preserved.
+func (f Float128) Float64() float64 {
+	if f.IsNaN() {
+		return math.Float64frombits(convertNaN(f.bits(), binary128, binary64).Uint64())
+	}
+	x, _ := f.Big().Float64()
+	return x
+}
+
+// IsNaN reports whether f is an IEEE 754 "not-a-number" value.
+func (f Float128) IsNaN() bool {
+	return binary128.isNaN(f.bits())
+}
+
+// Big returns the exact big.Float representation of f. It panics if f is NaN.
+func (f Float128) Big() *big.Float {
+	return binary128.decode(f.bits())
+}
+
+// Add returns the sum f+g, rounded to the nearest 128-bit floating-point value.
+func (f Float128) Add(g Float128) Float128 {
+	return newFloat128(binary128.arith(f.bits(), g.bits(), (*big.Float).Add))
+}
+
+// Sub returns the difference f-g, rounded to the nearest 128-bit floating-point
+// value.
+func (f Float128) Sub(g Float128) Float128 {
+	return newFloat128(binary128.arith(f.bits(), g.bits(), (*big.Float).Sub))
+}
+
+// Mul returns the product f*g, rounded to the nearest 128-bit floating-point
+// value.
+func (f Float128) Mul(g Float128) Float128 {
+	return newFloat128(binary128.arith(f.bits(), g.bits(), (*big.Float).Mul))
+}
+
+// Div returns the quotient f/g, rounded to the nearest 128-bit floating-point
+// value.
+func (f Float128) Div(g Float128) Float128 {
+	return newFloat128(binary128.arith(f.bits(), g.bits(), (*big.Float).Quo))
+}
+
+// Neg returns f with its sign inverted.
+func (f Float128) Neg() Float128 {
+	return newFloat128(binary128.neg(f.bits()))
+}
+
+// Cmp compares f and g and returns -1, 0 or +1 depending on whether f is less
+// than, equal to or greater than g. It panics if f or g is NaN.
+func (f Float128) Cmp(g Float128) int {
+	return binary128.cmp(f.bits(), g.bits())
+}
+
+// bits returns the binary representation of f as an integer.
+func (f Float128) bits() *big.Int {
+	bits := new(big.Int).SetUint64(f.a)
+	bits.Lsh(bits, 64)
+	return bits.Or(bits, new(big.Int).SetUint64(f.b))
+}
+
+// NewFloat128FromBig returns the nearest 128-bit floating-point value for x and
+// a bool indicating whether f represents x exactly.
+func NewFloat128FromBig(x *big.Float) (f Float128, exact bool) {
+	bits, exact := binary128.encode(x)
+	return newFloat128(bits), exact
+}
+
+// NewFloat128FromFloat64 returns the 128-bit floating-point value of x, which
+// is represented exactly. The sign and payload of NaN values are preserved.
+func NewFloat128FromFloat64(x float64) Float128 {
+	if math.IsNaN(x) {
+		bits := new(big.Int).SetUint64(math.Float64bits(x))
+		return newFloat128(convertNaN(bits, binary64, binary128))
+	}
+	f, _ := NewFloat128FromBig(big.NewFloat(x))
+	return f
+}
+
+// NewFloat128FromString returns a new 128-bit floating-point value based on s,
+// which contains 32 bytes in hexadecimal format.
+func NewFloat128FromString(s string) Float128 {
+	return NewFloat128FromBytes([]byte(s))
+}
+
+// NewFloat128FromBytes returns a new 128-bit floating-point value based on b,
+// which contains 32 bytes in hexadecimal format.
+func NewFloat128FromBytes(b []byte) Float128 {
+	var f Float128
+	if len(b) != 32 {
+		panic(fmt.Errorf("invalid length of float128 hexadecimal representation, expected 32, got %d", len(b)))
+	}
+	for i := 0; i < 16; i++ {
+		f.a = f.a<<4 | unhex(b[i])
+		f.b = f.b<<4 | unhex(b[16+i])
+	}
+	return f
+}
+
+// NewFloat128FromBits returns a new 128-bit floating-point value based on the
+// sign, exponent and fraction bits.
+func NewFloat128FromBits(a, b uint64) Float128 {
+	return Float128{
+		a: a,
+		b: b,
+	}
+}
+
+// newFloat128 returns a new 128-bit floating-point value based on its binary
+// representation as an integer.
+func newFloat128(bits *big.Int) Float128 {
+	b := new(big.Int).And(bits, mask(64)).Uint64()
+	a := new(big.Int).Rsh(bits, 64).Uint64()
+	return NewFloat128FromBits(a, b)
 }
@@ -8,6 +8,7 @@ package floats
 import (
 	"fmt"
 	"math"
+	"math/big"
 )
 
 // Float16 represents a 16-bit IEEE 754 half-precision floating-point value, in
@@ -150,6 +151,16 @@ func (f Float16) Float64() float64 {
 	return math.Float64frombits(bits)
 }
 
+// IsNaN reports whether f is an IEEE 754 "not-a-number" value.
+func (f Float16) IsNaN() bool {
+	return binary16.isNaN(big.NewInt(int64(f.a)))
+}
+
+// Big returns the exact big.Float representation of f. It panics if f is NaN.
+func (f Float16) Big() *big.Float {
+	return binary16.decode(big.NewInt(int64(f.a)))
+}
+
 // NewFloat16FromFloat32 returns the nearest 16-bit floating-point value for x
 // and a bool indicating whether f represents x exactly.
 func NewFloat16FromFloat32(x float32) (f Float16, exact bool) {
@@ -264,6 +275,13 @@ func NewFloat16FromFloat64(x float64) (f Float16, exact bool) {
 	return NewFloat16FromBits(a), exact
 }
 
+// NewFloat16FromBig returns the nearest 16-bit floating-point value for x and a
+// bool indicating whether f represents x exactly.
+func NewFloat16FromBig(x *big.Float) (f Float16, exact bool) {
+	bits, exact := binary16.encode(x)
+	return NewFloat16FromBits(uint16(bits.Uint64())), exact
+}
+
 // NewFloat16FromString returns a new 16-bit floating-point value based on s,
 // which contains 4 bytes in hexadecimal format.
 func NewFloat16FromString(s string) Float16 {
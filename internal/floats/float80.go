@@ -3,6 +3,7 @@ package floats
 import (
 	"fmt"
 	"math"
+	"math/big"
 )
 
 // Float80 represents an 80-bit IEEE 754 extended precision floating-point
@@ -41,101 +42,83 @@ func (f Float80) String() string {
 	return fmt.Sprintf("%04X%016X", f.se, f.m)
 }
 
-// Float64 returns the float64 representation of f.
+// Float64 returns the nearest float64 representation of f. The sign and the
+// most significant bits of the payload of NaN values are preserved.
 func (f Float80) Float64() float64 {
-	se := uint64(f.se)
-	m := f.m
-	// 1 bit: sign
-	sign := se >> 15
-	// 15 bits: exponent
-	exp := se & 0x7FFF
-	// Adjust for exponent bias.
-	//
-	// === [ binary64 ] =========================================================
-	//
-	// Exponent bias 1023.
-	//
-	//    +===========================+=======================+
-	//    | Exponent (in binary)      | Notes                 |
-	//    +===========================+=======================+
-	//    | 00000000000               | zero/subnormal number |
-	//    +---------------------------+-----------------------+
-	//    | 00000000001 - 11111111110 | normalized value      |
-	//    +---------------------------+-----------------------+
-	//    | 11111111111               | infinity/NaN          |
-	//    +---------------------------+-----------------------+
-	//
-	// References:
-	//    https://en.wikipedia.org/wiki/Double-precision_floating-point_format#Exponent_encoding
-	exp64 := int64(exp) - 16383 + 1023
-	switch {
-	case exp == 0:
-		// exponent is all zeroes.
-		exp64 = 0
-	case exp == 0x7FFF:
-		// exponent is all ones.
-		exp64 = 0x7FF
-	default:
+	if f.IsNaN() {
+		return math.Float64frombits(convertNaN(f.bits(), binary80, binary64).Uint64())
 	}
-	// 63 bits: fraction
-	frac := m & 0x7FFFFFFFFFFFFFFF
-	// Sign, exponent and fraction of binary64.
-	//
-	//    1 bit:   sign
-	//    11 bits: exponent
-	//    52 bits: fraction
-	//
-	// References:
-	//    https://en.wikipedia.org/wiki/Double-precision_floating-point_format#IEEE_754_double-precision_binary_floating-point_format:_binary64
-	bits := sign<<63 | uint64(exp64)<<52 | frac>>11
-	return math.Float64frombits(bits)
+	x, _ := f.Big().Float64()
+	return x
 }
 
-// NewFloat80FromFloat64 returns the nearest 80-bit floating-point value for x.
-func NewFloat80FromFloat64(x float64) Float80 {
-	// Sign, exponent and fraction of binary64.
-	//
-	//    1 bit:   sign
-	//    11 bits: exponent
-	//    52 bits: fraction
-	bits := math.Float64bits(x)
-	// 1 bit: sign
-	sign := uint16(bits >> 63)
-	// 11 bits: exponent
-	exp := bits >> 52 & 0x7FF
-	// 52 bits: fraction
-	frac := bits & 0xFFFFFFFFFFFFF
-
-	if exp == 0 && frac == 0 {
-		// zero value.
-		return Float80{}
-	}
+// IsNaN reports whether f is an IEEE 754 "not-a-number" value.
+func (f Float80) IsNaN() bool {
+	return binary80.isNaN(f.bits())
+}
 
-	// Sign, exponent and fraction of binary80.
-	//
-	//    1 bit:   sign
-	//    15 bits: exponent
-	//    1 bit:   integer part
-	//    63 bits: fraction
+// Big returns the exact big.Float representation of f. It panics if f is NaN.
+func (f Float80) Big() *big.Float {
+	return binary80.decode(f.bits())
+}
 
-	// 15 bits: exponent.
-	//
-	// Exponent bias 1023  (binary64)
-	// Exponent bias 16383 (binary80)
-	exp80 := int64(exp) - 1023 + 16383
-	// 63 bits: fraction.
-	//
-	frac80 := frac << 11
-	switch {
-	case exp == 0:
-		exp80 = 0
-	case exp == 0x7FF:
-		exp80 = 0x7FFF
+// Add returns the sum f+g, rounded to the nearest 80-bit floating-point value.
+func (f Float80) Add(g Float80) Float80 {
+	return newFloat80(binary80.arith(f.bits(), g.bits(), (*big.Float).Add))
+}
+
+// Sub returns the difference f-g, rounded to the nearest 80-bit floating-point
+// value.
+func (f Float80) Sub(g Float80) Float80 {
+	return newFloat80(binary80.arith(f.bits(), g.bits(), (*big.Float).Sub))
+}
+
+// Mul returns the product f*g, rounded to the nearest 80-bit floating-point
+// value.
+func (f Float80) Mul(g Float80) Float80 {
+	return newFloat80(binary80.arith(f.bits(), g.bits(), (*big.Float).Mul))
+}
+
+// Div returns the quotient f/g, rounded to the nearest 80-bit floating-point
+// value.
+func (f Float80) Div(g Float80) Float80 {
+	return newFloat80(binary80.arith(f.bits(), g.bits(), (*big.Float).Quo))
+}
+
+// Neg returns f with its sign inverted.
+func (f Float80) Neg() Float80 {
+	return newFloat80(binary80.neg(f.bits()))
+}
+
+// Cmp compares f and g and returns -1, 0 or +1 depending on whether f is less
+// than, equal to or greater than g. It panics if f or g is NaN.
+func (f Float80) Cmp(g Float80) int {
+	return binary80.cmp(f.bits(), g.bits())
+}
+
+// bits returns the binary representation of f as an integer.
+func (f Float80) bits() *big.Int {
+	bits := new(big.Int).SetUint64(uint64(f.se))
+	bits.Lsh(bits, 64)
+	return bits.Or(bits, new(big.Int).SetUint64(f.m))
+}
+
+// NewFloat80FromFloat64 returns the 80-bit floating-point value of x, which is
+// represented exactly. The sign and payload of NaN values are preserved.
+func NewFloat80FromFloat64(x float64) Float80 {
+	if math.IsNaN(x) {
+		bits := new(big.Int).SetUint64(math.Float64bits(x))
+		return newFloat80(convertNaN(bits, binary64, binary80))
 	}
-	se := sign<<15 | uint16(exp80)
-	// Integer part set to specify normalized value.
-	m := 0x8000000000000000 | frac80
-	return NewFloat80FromBits(se, m)
+	f, _ := NewFloat80FromBig(big.NewFloat(x))
+	return f
+}
+
+// NewFloat80FromBig returns the nearest 80-bit floating-point value for x and a
+// bool indicating whether f represents x exactly.
+func NewFloat80FromBig(x *big.Float) (f Float80, exact bool) {
+	bits, exact := binary80.encode(x)
+	return newFloat80(bits), exact
 }
 
 // NewFloat80FromString returns a new 80-bit floating-point value based on s,
@@ -164,3 +147,11 @@ func NewFloat80FromBits(se uint16, m uint64) Float80 {
 		m:  m,
 	}
 }
+
+// newFloat80 returns a new 80-bit floating-point value based on its binary
+// representation as an integer.
+func newFloat80(bits *big.Int) Float80 {
+	m := new(big.Int).And(bits, mask(64)).Uint64()
+	se := uint16(new(big.Int).Rsh(bits, 64).Uint64())
+	return NewFloat80FromBits(se, m)
+}
@@ -2,6 +2,7 @@ package floats
 
 import (
 	"math"
+	"math/big"
 	"testing"
 )
 
@@ -169,12 +170,14 @@ func TestNewFloat80FromFloat64(t *testing.T) {
 		// in
 		in float64
 	}{
-		{se: 0x0000, m: 0x0000000000000000, in: 0.0}, // +0
-		//{se: 0x8000, m: 0x0000000000000000, in: -0.0},  // -0
-		{se: 0x3FFF, m: 0x8000000000000000, in: 1.0},   // 1
-		{se: 0x4000, m: 0x8000000000000000, in: 2.0},   // 2
-		{se: 0x4000, m: 0xC000000000000000, in: 3.0},   // 3
-		{se: 0x3FFC, m: 0x8000000000000000, in: 0.125}, // 0.125
+		{se: 0x0000, m: 0x0000000000000000, in: 0.0},                  // +0
+		{se: 0x8000, m: 0x0000000000000000, in: math.Copysign(0, -1)}, // -0
+		{se: 0x3FFF, m: 0x8000000000000000, in: 1.0},                  // 1
+		{se: 0x4000, m: 0x8000000000000000, in: 2.0},                  // 2
+		{se: 0x4000, m: 0xC000000000000000, in: 3.0},                  // 3
+		{se: 0x3FFC, m: 0x8000000000000000, in: 0.125},                // 0.125
+		{se: 0x3C01, m: 0x8000000000000000, in: 0x1p-1022},            // min positive normal float64
+		{se: 0x3BCD, m: 0x8000000000000000, in: 0x1p-1074},            // min positive subnormal float64
 		//{se: 0x7FFE, m: 0xFFFFFFFFFFFFFFFF, in: 1.18973149535723176505e+4932}, // max normal
 		//{se: 0x0001, m: 0x8000000000000000, in: 3.36210314311209350626e-4932}, // min positive normal
 		//{se: 0x0000, m: 0x7FFFFFFFFFFFFFFF, in: 3.36210314311209350608e-4932}, // max subnormal
@@ -199,3 +202,151 @@ func TestNewFloat80FromFloat64(t *testing.T) {
 		}
 	}
 }
+
+// === [ exact conversions ] ===================================================
+
+func TestBigRoundTrip(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// binary16.
+		{in: "1", want: "H3C00"},
+		{in: "65504", want: "H7BFF"},
+		{in: "65520", want: "H7C00"},   // round to +inf
+		{in: "0x1p-24", want: "H0001"}, // min positive subnormal
+		{in: "0x1p-25", want: "H0000"}, // round half to even
+		{in: "0x3p-25", want: "H0002"}, // round half to even
+		// binary80.
+		{in: "1", want: "K3FFF8000000000000000"},
+		{in: "-3", want: "KC000C000000000000000"},
+		{in: "0x1p-16445", want: "K00000000000000000001"},                 // min positive subnormal
+		{in: "0x1.fffffffffffffffep16383", want: "K7FFEFFFFFFFFFFFFFFFF"}, // max normal
+		{in: "0x1.ffffffffffffffffp16383", want: "K7FFF8000000000000000"}, // round to +inf
+		// binary128.
+		{in: "1", want: "L3FFF0000000000000000000000000000"},
+		{in: "-0.5", want: "LBFFE0000000000000000000000000000"},
+		{in: "0x1p-16494", want: "L00000000000000000000000000000001"}, // min positive subnormal
+		{in: "0x1.0000000000000000000000000001p0", want: "L3FFF0000000000000000000000000001"},
+	}
+	for _, g := range golden {
+		x, _, err := big.ParseFloat(g.in, 0, 256, big.ToNearestEven)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		var y *big.Float
+		var exact bool
+		switch g.want[0] {
+		case 'H':
+			f, ok := NewFloat16FromBig(x)
+			got, y, exact = "H"+f.String(), f.Big(), ok
+		case 'K':
+			f, ok := NewFloat80FromBig(x)
+			got, y, exact = "K"+f.String(), f.Big(), ok
+		case 'L':
+			f, ok := NewFloat128FromBig(x)
+			got, y, exact = "L"+f.String(), f.Big(), ok
+		}
+		if got != g.want {
+			t.Errorf("binary representation mismatch for %v; expected %s, got %s", g.in, g.want, got)
+			continue
+		}
+		// Check that exact representations round-trip.
+		if exact != (y.Cmp(x) == 0) {
+			t.Errorf("exactness mismatch for %v; expected %v, got %v", g.in, y.Cmp(x) == 0, exact)
+		}
+	}
+}
+
+func TestIsNaN(t *testing.T) {
+	if !NewFloat16FromString("7E00").IsNaN() || NewFloat16FromString("7C00").IsNaN() {
+		t.Errorf("binary16 NaN mismatch")
+	}
+	if !NewFloat80FromString("7FFFC000000000000000").IsNaN() || NewFloat80FromString("7FFF8000000000000000").IsNaN() {
+		t.Errorf("binary80 NaN mismatch")
+	}
+	if !NewFloat128FromString("7FFF8000000000000000000000000000").IsNaN() || NewFloat128FromString("7FFF0000000000000000000000000000").IsNaN() {
+		t.Errorf("binary128 NaN mismatch")
+	}
+}
+
+// === [ arithmetic ] ==========================================================
+
+func TestFloat80Arith(t *testing.T) {
+	one := NewFloat80FromFloat64(1)
+	three := NewFloat80FromFloat64(3)
+	// 1/3 rounded to 64 bits of precision.
+	third := one.Div(three)
+	if got, want := third.String(), "3FFDAAAAAAAAAAAAAAAB"; got != want {
+		t.Errorf("1/3 mismatch; expected %s, got %s", want, got)
+	}
+	// 1 + 2^-63 is representable in binary80 but not in binary64.
+	eps := NewFloat80FromBits(0x3FC0, 0x8000000000000000)
+	if got, want := one.Add(eps).String(), "3FFF8000000000000001"; got != want {
+		t.Errorf("1+2^-63 mismatch; expected %s, got %s", want, got)
+	}
+	if got := one.Add(eps).Sub(one); got.Cmp(eps) != 0 {
+		t.Errorf("(1+2^-63)-1 mismatch; expected %s, got %s", eps, got)
+	}
+	if got, want := three.Mul(three).Neg().Float64(), -9.0; got != want {
+		t.Errorf("-(3*3) mismatch; expected %v, got %v", want, got)
+	}
+	// Invalid operations result in NaN.
+	zero := NewFloat80FromFloat64(0)
+	if got := zero.Div(zero); !got.IsNaN() {
+		t.Errorf("0/0 mismatch; expected NaN, got %s", got)
+	}
+	// NaN payloads are propagated and quieted.
+	snan := NewFloat80FromString("7FFF8000000000000001")
+	if got, want := snan.Add(one).String(), "7FFFC000000000000001"; got != want {
+		t.Errorf("NaN+1 mismatch; expected %s, got %s", want, got)
+	}
+}
+
+func TestFloat128Arith(t *testing.T) {
+	one := NewFloat128FromFloat64(1)
+	ten := NewFloat128FromFloat64(10)
+	// 1/10 rounded to 113 bits of precision.
+	if got, want := one.Div(ten).String(), "3FFB999999999999999999999999999A"; got != want {
+		t.Errorf("1/10 mismatch; expected %s, got %s", want, got)
+	}
+	// Subnormal results.
+	min := NewFloat128FromBits(0, 1)
+	if got, want := min.Mul(NewFloat128FromFloat64(0.5)).String(), "00000000000000000000000000000000"; got != want {
+		t.Errorf("min/2 mismatch; expected %s, got %s", want, got)
+	}
+	if got, want := min.Mul(NewFloat128FromFloat64(1.5)).String(), "00000000000000000000000000000002"; got != want {
+		t.Errorf("min*1.5 mismatch; expected %s, got %s", want, got)
+	}
+	// NaN payloads are preserved by conversion.
+	nan := math.Float64frombits(0x7FF8000000000123)
+	if got := NewFloat128FromFloat64(nan).Float64(); math.Float64bits(got) != 0x7FF8000000000123 {
+		t.Errorf("NaN mismatch; expected 0x7FF8000000000123, got 0x%016X", math.Float64bits(got))
+	}
+}
+
+func TestDoubleDoubleArith(t *testing.T) {
+	one := NewDoubleDoubleFromFloat64(1)
+	ten := NewDoubleDoubleFromFloat64(10)
+	tenth := one.Div(ten)
+	if got, want := tenth.String(), "3FB999999999999ABC5999999999999A"; got != want {
+		t.Errorf("1/10 mismatch; expected %s, got %s", want, got)
+	}
+	if got, want := tenth.Float64(), 0.1; got != want {
+		t.Errorf("float64 mismatch; expected %v, got %v", want, got)
+	}
+	// 1 + 2^-100 is representable as a double-double.
+	eps := NewDoubleDoubleFromFloat64(0x1p-100)
+	sum := one.Add(eps)
+	if got, want := sum.String(), "3FF000000000000039B0000000000000"; got != want {
+		t.Errorf("1+2^-100 mismatch; expected %s, got %s", want, got)
+	}
+	if got := sum.Sub(one); got.Cmp(eps) != 0 {
+		t.Errorf("(1+2^-100)-1 mismatch; expected %s, got %s", eps, got)
+	}
+	inf := NewDoubleDoubleFromFloat64(math.Inf(1))
+	if got := inf.Sub(inf); !got.IsNaN() {
+		t.Errorf("Inf-Inf mismatch; expected NaN, got %s", got)
+	}
+}
@@ -0,0 +1,249 @@
+package floats
+
+import (
+	"math/big"
+)
+
+// format describes the binary representation of an IEEE 754 floating-point
+// format, as used for the exact conversion of floating-point values to and
+// from big.Float.
+//
+//    1 bit:        sign
+//    exp bits:     exponent
+//    1 bit:        integer part (only if explicit)
+//    frac bits:    fraction
+type format struct {
+	// Number of exponent bits.
+	exp uint
+	// Number of fraction bits.
+	frac uint
+	// explicit specifies whether the integer part of the significand is stored
+	// explicitly (as in the x86 extended precision format).
+	explicit bool
+}
+
+// Binary floating-point formats.
+var (
+	// binary16 format.
+	binary16 = format{exp: 5, frac: 10}
+	// binary64 format.
+	binary64 = format{exp: 11, frac: 52}
+	// x86 extended precision format.
+	binary80 = format{exp: 15, frac: 63, explicit: true}
+	// binary128 format.
+	binary128 = format{exp: 15, frac: 112}
+)
+
+// prec returns the precision in bits of the significand of the format.
+func (f format) prec() uint {
+	return f.frac + 1
+}
+
+// bias returns the exponent bias of the format.
+func (f format) bias() int {
+	return 1<<(f.exp-1) - 1
+}
+
+// sigBits returns the number of bits of the stored significand of the format.
+func (f format) sigBits() uint {
+	if f.explicit {
+		return f.frac + 1
+	}
+	return f.frac
+}
+
+// isNaN reports whether bits, in the given format, represents a NaN value.
+func (f format) isNaN(bits *big.Int) bool {
+	exp := new(big.Int).Rsh(bits, f.sigBits())
+	exp.And(exp, mask(f.exp))
+	frac := new(big.Int).And(bits, mask(f.frac))
+	return exp.Cmp(mask(f.exp)) == 0 && frac.Sign() != 0
+}
+
+// decode returns the exact big.Float representation of bits, in the given
+// format. It panics if bits represents a NaN value.
+func (f format) decode(bits *big.Int) *big.Float {
+	if f.isNaN(bits) {
+		panic("unable to represent NaN as big.Float")
+	}
+	sigBits := f.sigBits()
+	sign := bits.Bit(int(f.exp+sigBits)) == 1
+	exp := new(big.Int).Rsh(bits, sigBits)
+	exp.And(exp, mask(f.exp))
+	sig := new(big.Int).And(bits, mask(sigBits))
+	x := new(big.Float).SetPrec(f.prec())
+	switch e := int(exp.Int64()); {
+	case e == 1<<f.exp-1:
+		// infinity.
+		x.SetInf(sign)
+		return x
+	case e == 0:
+		// zero or subnormal number.
+		x.SetInt(sig)
+		x.SetMantExp(x, 1-f.bias()-int(f.frac))
+	default:
+		// normalized value.
+		if !f.explicit {
+			sig.SetBit(sig, int(f.frac), 1)
+		}
+		x.SetInt(sig)
+		x.SetMantExp(x, e-f.bias()-int(f.frac))
+	}
+	if sign {
+		x.Neg(x)
+	}
+	return x
+}
+
+// encode returns the nearest value of x in the given format (rounding half to
+// even), and a bool indicating whether the result represents x exactly.
+// Values out of range are rounded to infinity.
+func (f format) encode(x *big.Float) (bits *big.Int, exact bool) {
+	bits = new(big.Int)
+	sigBits := f.sigBits()
+	if x.Signbit() {
+		bits.SetBit(bits, int(f.exp+sigBits), 1)
+	}
+	inf := func() (*big.Int, bool) {
+		bits.Or(bits, new(big.Int).Lsh(mask(f.exp), sigBits))
+		if f.explicit {
+			bits.SetBit(bits, int(f.frac), 1)
+		}
+		return bits, x.IsInf()
+	}
+	switch {
+	case x.IsInf():
+		return inf()
+	case x.Sign() == 0:
+		return bits, true
+	}
+	// Unbiased exponent of x; i.e. 2^exp <= |x| < 2^(exp+1).
+	abs := new(big.Float).Abs(x)
+	exp := abs.MantExp(nil) - 1
+	minExp := 1 - f.bias()
+	if exp < minExp {
+		// subnormal number.
+		exp = minExp
+	}
+	// Round the significand to an integer of prec bits.
+	sig, exact := roundInt(new(big.Float).SetMantExp(abs, int(f.frac)-exp))
+	if sig.BitLen() > int(f.prec()) {
+		sig.Rsh(sig, 1)
+		exp++
+	}
+	if exp > f.bias() {
+		// set to infinity if exp is too high.
+		bits, _ := inf()
+		return bits, false
+	}
+	if sig.BitLen() == int(f.prec()) {
+		// normalized value.
+		if !f.explicit {
+			sig.SetBit(sig, int(f.frac), 0)
+		}
+		e := big.NewInt(int64(exp + f.bias()))
+		bits.Or(bits, e.Lsh(e, sigBits))
+	}
+	return bits.Or(bits, sig), exact
+}
+
+// nan returns the default quiet NaN of the format.
+func (f format) nan() *big.Int {
+	bits := new(big.Int).Lsh(mask(f.exp), f.sigBits())
+	if f.explicit {
+		bits.SetBit(bits, int(f.frac), 1)
+	}
+	return bits.SetBit(bits, int(f.frac-1), 1)
+}
+
+// quiet returns the quiet NaN with the sign and payload of the given NaN, in
+// the given format.
+func (f format) quiet(bits *big.Int) *big.Int {
+	return new(big.Int).SetBit(bits, int(f.frac-1), 1)
+}
+
+// neg returns bits, in the given format, with the sign bit inverted.
+func (f format) neg(bits *big.Int) *big.Int {
+	sign := int(f.exp + f.sigBits())
+	return new(big.Int).SetBit(bits, sign, bits.Bit(sign)^1)
+}
+
+// arith returns the result of the arithmetic operation op on a and b, in the
+// given format, rounded to the nearest value of the format. NaN operands are
+// propagated as quiet NaNs, and invalid operations (e.g. 0/0 or Inf-Inf)
+// result in the default quiet NaN.
+func (f format) arith(a, b *big.Int, op func(z, x, y *big.Float) *big.Float) (bits *big.Int) {
+	switch {
+	case f.isNaN(a):
+		return f.quiet(a)
+	case f.isNaN(b):
+		return f.quiet(b)
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(big.ErrNaN); !ok {
+				panic(e)
+			}
+			bits = f.nan()
+		}
+	}()
+	// Rounding the result of an arithmetic operation first to a precision of
+	// at least 2p+2 bits and then to a precision of p bits is equivalent to
+	// rounding it directly to p bits.
+	z := new(big.Float).SetPrec(2*f.prec() + 2)
+	bits, _ = f.encode(op(z, f.decode(a), f.decode(b)))
+	return bits
+}
+
+// cmp compares a and b, in the given format, and returns -1, 0 or +1 depending
+// on whether a is less than, equal to or greater than b. It panics if a or b is
+// NaN.
+func (f format) cmp(a, b *big.Int) int {
+	return f.decode(a).Cmp(f.decode(b))
+}
+
+// convertNaN converts the NaN value bits from one format to another,
+// preserving the sign, the quiet bit and the most significant bits of the
+// payload.
+func convertNaN(bits *big.Int, from, to format) *big.Int {
+	sign := bits.Bit(int(from.exp + from.sigBits()))
+	frac := new(big.Int).And(bits, mask(from.frac))
+	if to.frac >= from.frac {
+		frac.Lsh(frac, to.frac-from.frac)
+	} else {
+		frac.Rsh(frac, from.frac-to.frac)
+	}
+	z := to.nan()
+	if frac.Sign() != 0 {
+		z.Xor(z, new(big.Int).And(z, mask(to.frac)))
+		z.Or(z, frac)
+	}
+	return z.SetBit(z, int(to.exp+to.sigBits()), sign)
+}
+
+// ### [ helper functions ] ####################################################
+
+// mask returns a bit mask of the n least significant bits.
+func mask(n uint) *big.Int {
+	m := new(big.Int).Lsh(big.NewInt(1), n)
+	return m.Sub(m, big.NewInt(1))
+}
+
+// roundInt returns the non-negative x rounded to the nearest integer (rounding
+// half to even), and a bool indicating whether x is an integer.
+func roundInt(x *big.Float) (*big.Int, bool) {
+	i, acc := x.Int(nil)
+	if acc == big.Exact {
+		return i, true
+	}
+	rem := new(big.Float).Sub(x, new(big.Float).SetInt(i))
+	switch rem.Cmp(big.NewFloat(0.5)) {
+	case 1:
+		i.Add(i, big.NewInt(1))
+	case 0:
+		if i.Bit(0) == 1 {
+			i.Add(i, big.NewInt(1))
+		}
+	}
+	return i, false
+}
@@ -0,0 +1,35 @@
+// Package rename keeps track of the renaming of named LLVM IR entities which
+// may not be located through a parent, so that symbol tables may detect
+// entries invalidated by renaming.
+package rename
+
+import "sync/atomic"
+
+// Rename generations of global identifiers, type names and local identifiers.
+var (
+	// Globals tracks the renaming of global variables, and of functions not
+	// part of a module.
+	Globals Counter
+	// Types tracks the renaming of types.
+	Types Counter
+	// Locals tracks the renaming of function parameters, and of basic blocks
+	// and local variables not part of a function.
+	Locals Counter
+)
+
+// A Counter counts the renaming of a kind of named entities.
+type Counter struct {
+	// Number of renamed entities.
+	n uint64
+}
+
+// Renamed records the renaming of a named entity.
+func (c *Counter) Renamed() {
+	atomic.AddUint64(&c.n, 1)
+}
+
+// Generation returns the number of renamed entities; it changes on every
+// renaming of a named entity.
+func (c *Counter) Generation() uint64 {
+	return atomic.LoadUint64(&c.n)
+}
@@ -4,9 +4,10 @@ package ir
 
 import (
 	"bytes"
-	"fmt"
+	"io"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 	"github.com/llir/llvm/ir/types"
 	"github.com/llir/llvm/ir/value"
 )
@@ -53,29 +54,74 @@ func (block *BasicBlock) GetName() string {
 
 // SetName sets the label name of the basic block.
 func (block *BasicBlock) SetName(name string) {
+	oldName := block.Name
 	block.Name = name
+	if block.Parent == nil {
+		rename.Locals.Renamed()
+		return
+	}
+	block.Parent.renameLocal(block, oldName)
 }
 
 // String returns the LLVM syntax representation of the basic block.
 func (block *BasicBlock) String() string {
 	buf := &bytes.Buffer{}
-	if isLocalID(block.Name) {
+	block.write(newWriter(buf))
+	return buf.String()
+}
 
-		fmt.Fprintf(buf, "; <label>:%s\n", enc.EscapeIdent(block.Name))
+// WriteTo writes the LLVM syntax representation of the basic block to w. The
+// number of bytes written and any error encountered are returned.
+func (block *BasicBlock) WriteTo(w io.Writer) (n int64, err error) {
+	bw := newWriter(w)
+	block.write(bw)
+	return bw.n, bw.err
+}
+
+// write writes the LLVM syntax representation of the basic block to w.
+func (block *BasicBlock) write(w *writer) {
+	var m *Module
+	if block.Parent != nil {
+		m = block.Parent.Parent
+	}
+	c := m.comments(block)
+	if c != nil {
+		writeLines(w, c.Leading, "")
+	}
+	if isLocalID(block.Name) {
+		w.Printf("; <label>:%s", enc.EscapeIdent(block.Name))
 	} else {
-		fmt.Fprintf(buf, "%s:\n", enc.EscapeIdent(block.Name))
+		w.Printf("%s:", enc.EscapeIdent(block.Name))
 	}
+	writeTrailing(w, c)
+	w.WriteString("\n")
 	for _, inst := range block.Insts {
-		fmt.Fprintf(buf, "\t%s\n", inst)
+		c := m.comments(inst)
+		if c != nil {
+			writeLines(w, c.Leading, "\t")
+		}
+		w.WriteString("\t")
+		w.text(inst)
+		writeTrailing(w, c)
+		if n, ok := inst.(value.Named); ok && w.uses != nil && !types.IsVoid(n.Type()) {
+			w.Printf(" ; uses = %d", w.uses[n])
+		}
+		w.WriteString("\n")
 	}
-	fmt.Fprintf(buf, "\t%s", block.Term)
-	return buf.String()
+	c = m.comments(block.Term)
+	if c != nil {
+		writeLines(w, c.Leading, "\t")
+	}
+	w.WriteString("\t")
+	w.text(block.Term)
+	writeTrailing(w, c)
 }
 
 // AppendInst appends the given instruction to the basic block.
 func (block *BasicBlock) AppendInst(inst Instruction) {
 	inst.SetParent(block)
 	block.Insts = append(block.Insts, inst)
+	indexInst(block, inst)
 }
 
 // SetTerm sets the terminator of the basic block.
@@ -450,6 +496,14 @@ func (block *BasicBlock) NewSelect(cond, x, y value.Value) *InstSelect {
 	return inst
 }
 
+// NewFreeze appends a new freeze instruction to the basic block based on the
+// given operand.
+func (block *BasicBlock) NewFreeze(x value.Value) *InstFreeze {
+	inst := NewFreeze(x)
+	block.AppendInst(inst)
+	return inst
+}
+
 // NewCall appends a new call instruction to the basic block based on the given
 // callee and function arguments.
 //
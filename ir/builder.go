@@ -0,0 +1,592 @@
+// === [ Instruction builder ] =================================================
+
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// A Builder inserts new instructions at an insertion point within a basic
+// block.
+//
+// The insertion point is located before a given instruction of the basic
+// block, or at the end of the basic block. Instructions are inserted in order,
+// each new instruction being inserted after the previously inserted one.
+type Builder struct {
+	// Basic block of the insertion point; or nil if not set.
+	block *BasicBlock
+	// Instruction before which new instructions are inserted; or nil to insert
+	// at the end of the basic block.
+	next Instruction
+}
+
+// NewBuilder returns a new instruction builder with an insertion point at the
+// end of the given basic block.
+func NewBuilder(block *BasicBlock) *Builder {
+	return &Builder{block: block}
+}
+
+// Block returns the basic block of the insertion point.
+func (b *Builder) Block() *BasicBlock {
+	return b.block
+}
+
+// SetInsertPointStart sets the insertion point to the start of the given basic
+// block.
+func (b *Builder) SetInsertPointStart(block *BasicBlock) {
+	b.block = block
+	b.next = nil
+	if len(block.Insts) > 0 {
+		b.next = block.Insts[0]
+	}
+}
+
+// SetInsertPointEnd sets the insertion point to the end of the given basic
+// block; i.e. after its last non-branching instruction.
+func (b *Builder) SetInsertPointEnd(block *BasicBlock) {
+	b.block = block
+	b.next = nil
+}
+
+// SetInsertPointBefore sets the insertion point to before the given
+// instruction. If inst is the terminator of its parent basic block, the
+// insertion point is set to the end of the basic block.
+func (b *Builder) SetInsertPointBefore(inst Instruction) {
+	block := parentOf(inst)
+	b.block = block
+	b.next = nil
+	if _, ok := inst.(Terminator); !ok {
+		b.next = inst
+	}
+}
+
+// SetInsertPointAfter sets the insertion point to after the given
+// non-branching instruction.
+func (b *Builder) SetInsertPointAfter(inst Instruction) {
+	block := parentOf(inst)
+	i := instIndex(block, inst)
+	b.block = block
+	b.next = nil
+	if i+1 < len(block.Insts) {
+		b.next = block.Insts[i+1]
+	}
+}
+
+// Insert inserts the given instruction at the insertion point.
+func (b *Builder) Insert(inst Instruction) {
+	if b.block == nil {
+		panic("invalid insertion point; basic block not set")
+	}
+	if b.next == nil {
+		b.block.AppendInst(inst)
+		return
+	}
+	InsertBefore(inst, b.next)
+}
+
+// InsertBefore inserts the given instruction before pos, within the parent
+// basic block of pos. If pos is a terminator, inst is appended to the
+// non-branching instructions of the basic block.
+func InsertBefore(inst, pos Instruction) {
+	block := parentOf(pos)
+	if _, ok := pos.(Terminator); ok {
+		block.AppendInst(inst)
+		return
+	}
+	insertAt(block, instIndex(block, pos), inst)
+}
+
+// InsertAfter inserts the given instruction after the non-branching
+// instruction pos, within the parent basic block of pos.
+func InsertAfter(inst, pos Instruction) {
+	block := parentOf(pos)
+	insertAt(block, instIndex(block, pos)+1, inst)
+}
+
+// Remove removes the given non-branching instruction from its parent basic
+// block. The parent basic block of the instruction is set to nil.
+func Remove(inst Instruction) {
+	block := parentOf(inst)
+	i := instIndex(block, inst)
+	copy(block.Insts[i:], block.Insts[i+1:])
+	block.Insts[len(block.Insts)-1] = nil
+	block.Insts = block.Insts[:len(block.Insts)-1]
+	inst.SetParent(nil)
+}
+
+// MoveBefore moves the given non-branching instruction from its parent basic
+// block to before pos, within the parent basic block of pos.
+func MoveBefore(inst, pos Instruction) {
+	Remove(inst)
+	InsertBefore(inst, pos)
+}
+
+// --- [ Binary instructions ] -------------------------------------------------
+
+// NewAdd inserts a new add instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewAdd(x, y value.Value) *InstAdd {
+	inst := NewAdd(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFAdd inserts a new fadd instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewFAdd(x, y value.Value) *InstFAdd {
+	inst := NewFAdd(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSub inserts a new sub instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewSub(x, y value.Value) *InstSub {
+	inst := NewSub(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFSub inserts a new fsub instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewFSub(x, y value.Value) *InstFSub {
+	inst := NewFSub(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewMul inserts a new mul instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewMul(x, y value.Value) *InstMul {
+	inst := NewMul(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFMul inserts a new fmul instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewFMul(x, y value.Value) *InstFMul {
+	inst := NewFMul(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewUDiv inserts a new udiv instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewUDiv(x, y value.Value) *InstUDiv {
+	inst := NewUDiv(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSDiv inserts a new sdiv instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewSDiv(x, y value.Value) *InstSDiv {
+	inst := NewSDiv(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFDiv inserts a new fdiv instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewFDiv(x, y value.Value) *InstFDiv {
+	inst := NewFDiv(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewURem inserts a new urem instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewURem(x, y value.Value) *InstURem {
+	inst := NewURem(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSRem inserts a new srem instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewSRem(x, y value.Value) *InstSRem {
+	inst := NewSRem(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFRem inserts a new frem instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewFRem(x, y value.Value) *InstFRem {
+	inst := NewFRem(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Bitwise instructions ] ------------------------------------------------
+
+// NewShl inserts a new shl instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewShl(x, y value.Value) *InstShl {
+	inst := NewShl(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewLShr inserts a new lshr instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewLShr(x, y value.Value) *InstLShr {
+	inst := NewLShr(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewAShr inserts a new ashr instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewAShr(x, y value.Value) *InstAShr {
+	inst := NewAShr(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewAnd inserts a new and instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewAnd(x, y value.Value) *InstAnd {
+	inst := NewAnd(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewOr inserts a new or instruction at the insertion point based on the given
+// operands.
+func (b *Builder) NewOr(x, y value.Value) *InstOr {
+	inst := NewOr(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewXor inserts a new xor instruction at the insertion point based on the
+// given operands.
+func (b *Builder) NewXor(x, y value.Value) *InstXor {
+	inst := NewXor(x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Vector instructions ] -------------------------------------------------
+
+// NewExtractElement inserts a new extractelement instruction at the insertion
+// point based on the given vector and index.
+func (b *Builder) NewExtractElement(x, index value.Value) *InstExtractElement {
+	inst := NewExtractElement(x, index)
+	b.Insert(inst)
+	return inst
+}
+
+// NewInsertElement inserts a new insertelement instruction at the insertion
+// point based on the given vector, element and index.
+func (b *Builder) NewInsertElement(x, elem, index value.Value) *InstInsertElement {
+	inst := NewInsertElement(x, elem, index)
+	b.Insert(inst)
+	return inst
+}
+
+// NewShuffleVector inserts a new shufflevector instruction at the insertion
+// point based on the given vectors and shuffle mask.
+func (b *Builder) NewShuffleVector(x, y, mask value.Value) *InstShuffleVector {
+	inst := NewShuffleVector(x, y, mask)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Aggregate instructions ] ----------------------------------------------
+
+// NewExtractValue inserts a new extractvalue instruction at the insertion point
+// based on the given vector and indices.
+func (b *Builder) NewExtractValue(x value.Value, indices []int64) *InstExtractValue {
+	inst := NewExtractValue(x, indices)
+	b.Insert(inst)
+	return inst
+}
+
+// NewInsertValue inserts a new insertvalue instruction at the insertion point
+// based on the given vector, element and indices.
+func (b *Builder) NewInsertValue(x, elem value.Value, indices []int64) *InstInsertValue {
+	inst := NewInsertValue(x, elem, indices)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Memory instructions ] -------------------------------------------------
+
+// NewAlloca inserts a new alloca instruction at the insertion point based on
+// the given element type.
+func (b *Builder) NewAlloca(elem types.Type) *InstAlloca {
+	inst := NewAlloca(elem)
+	b.Insert(inst)
+	return inst
+}
+
+// NewLoad inserts a new load instruction at the insertion point based on the
+// given source address.
+func (b *Builder) NewLoad(src value.Value) *InstLoad {
+	inst := NewLoad(src)
+	b.Insert(inst)
+	return inst
+}
+
+// NewStore inserts a new store instruction at the insertion point based on the
+// given source value and destination address.
+func (b *Builder) NewStore(src, dst value.Value) *InstStore {
+	inst := NewStore(src, dst)
+	b.Insert(inst)
+	return inst
+}
+
+// NewGetElementPtr inserts a new getelementptr instruction at the insertion
+// point based on the given source address and element indices.
+func (b *Builder) NewGetElementPtr(src value.Value, indices ...value.Value) *InstGetElementPtr {
+	inst := NewGetElementPtr(src, indices...)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Conversion instructions ] ---------------------------------------------
+
+// NewTrunc inserts a new trunc instruction at the insertion point based on the
+// given source value and target type.
+func (b *Builder) NewTrunc(from value.Value, to types.Type) *InstTrunc {
+	inst := NewTrunc(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewZExt inserts a new zext instruction at the insertion point based on the
+// given source value and target type.
+func (b *Builder) NewZExt(from value.Value, to types.Type) *InstZExt {
+	inst := NewZExt(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSExt inserts a new sext instruction at the insertion point based on the
+// given source value and target type.
+func (b *Builder) NewSExt(from value.Value, to types.Type) *InstSExt {
+	inst := NewSExt(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFPTrunc inserts a new fptrunc instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewFPTrunc(from value.Value, to types.Type) *InstFPTrunc {
+	inst := NewFPTrunc(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFPExt inserts a new fpext instruction at the insertion point based on the
+// given source value and target type.
+func (b *Builder) NewFPExt(from value.Value, to types.Type) *InstFPExt {
+	inst := NewFPExt(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFPToUI inserts a new fptoui instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewFPToUI(from value.Value, to types.Type) *InstFPToUI {
+	inst := NewFPToUI(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFPToSI inserts a new fptosi instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewFPToSI(from value.Value, to types.Type) *InstFPToSI {
+	inst := NewFPToSI(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewUIToFP inserts a new uitofp instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewUIToFP(from value.Value, to types.Type) *InstUIToFP {
+	inst := NewUIToFP(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSIToFP inserts a new sitofp instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewSIToFP(from value.Value, to types.Type) *InstSIToFP {
+	inst := NewSIToFP(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewPtrToInt inserts a new ptrtoint instruction at the insertion point based
+// on the given source value and target type.
+func (b *Builder) NewPtrToInt(from value.Value, to types.Type) *InstPtrToInt {
+	inst := NewPtrToInt(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewIntToPtr inserts a new inttoptr instruction at the insertion point based
+// on the given source value and target type.
+func (b *Builder) NewIntToPtr(from value.Value, to types.Type) *InstIntToPtr {
+	inst := NewIntToPtr(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewBitCast inserts a new bitcast instruction at the insertion point based on
+// the given source value and target type.
+func (b *Builder) NewBitCast(from value.Value, to types.Type) *InstBitCast {
+	inst := NewBitCast(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// NewAddrSpaceCast inserts a new addrspacecast instruction at the insertion
+// point based on the given source value and target type.
+func (b *Builder) NewAddrSpaceCast(from value.Value, to types.Type) *InstAddrSpaceCast {
+	inst := NewAddrSpaceCast(from, to)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Other instructions ] --------------------------------------------------
+
+// NewICmp inserts a new icmp instruction at the insertion point based on the
+// given integer condition code and operands.
+func (b *Builder) NewICmp(pred IntPred, x, y value.Value) *InstICmp {
+	inst := NewICmp(pred, x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFCmp inserts a new fcmp instruction at the insertion point based on the
+// given floating-point condition code and operands.
+func (b *Builder) NewFCmp(pred FloatPred, x, y value.Value) *InstFCmp {
+	inst := NewFCmp(pred, x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewPhi inserts a new phi instruction at the insertion point based on the
+// given incoming values.
+func (b *Builder) NewPhi(incs ...*Incoming) *InstPhi {
+	inst := NewPhi(incs...)
+	b.Insert(inst)
+	return inst
+}
+
+// NewSelect inserts a new select instruction at the insertion point based on
+// the given selection condition and operands.
+func (b *Builder) NewSelect(cond, x, y value.Value) *InstSelect {
+	inst := NewSelect(cond, x, y)
+	b.Insert(inst)
+	return inst
+}
+
+// NewFreeze inserts a new freeze instruction at the insertion point based on
+// the given operand.
+func (b *Builder) NewFreeze(x value.Value) *InstFreeze {
+	inst := NewFreeze(x)
+	b.Insert(inst)
+	return inst
+}
+
+// NewCall inserts a new call instruction at the insertion point based on the
+// given callee and function arguments.
+//
+// The callee value may have one of the following underlying types.
+//
+//    *ir.Function
+//    *types.Param
+//    *constant.ExprBitCast
+//    *ir.InstBitCast
+//    *ir.InstLoad
+func (b *Builder) NewCall(callee value.Named, args ...value.Value) *InstCall {
+	inst := NewCall(callee, args...)
+	b.Insert(inst)
+	return inst
+}
+
+// --- [ Terminators ] ---------------------------------------------------------
+
+// NewRet sets the terminator of the basic block of the insertion point to a new
+// ret terminator based on the given return value. A nil return value indicates
+// a "void" return.
+func (b *Builder) NewRet(x value.Value) *TermRet {
+	term := NewRet(x)
+	b.block.SetTerm(term)
+	return term
+}
+
+// NewBr sets the terminator of the basic block of the insertion point to a new
+// unconditional br terminator based on the given target branch.
+func (b *Builder) NewBr(target *BasicBlock) *TermBr {
+	term := NewBr(target)
+	b.block.SetTerm(term)
+	return term
+}
+
+// NewCondBr sets the terminator of the basic block of the insertion point to a
+// new conditional br terminator based on the given branching condition and
+// conditional target branches.
+func (b *Builder) NewCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) *TermCondBr {
+	term := NewCondBr(cond, targetTrue, targetFalse)
+	b.block.SetTerm(term)
+	return term
+}
+
+// NewSwitch sets the terminator of the basic block of the insertion point to a
+// new switch terminator based on the given control variable, default target
+// branch and switch cases.
+func (b *Builder) NewSwitch(x value.Value, targetDefault *BasicBlock, cases ...*Case) *TermSwitch {
+	term := NewSwitch(x, targetDefault, cases...)
+	b.block.SetTerm(term)
+	return term
+}
+
+// NewUnreachable sets the terminator of the basic block of the insertion point
+// to a new unreachable terminator.
+func (b *Builder) NewUnreachable() *TermUnreachable {
+	term := NewUnreachable()
+	b.block.SetTerm(term)
+	return term
+}
+
+// ### [ Helper functions ] ####################################################
+
+// parentOf returns the parent basic block of the given instruction.
+func parentOf(inst Instruction) *BasicBlock {
+	block := inst.GetParent()
+	if block == nil {
+		panic(fmt.Errorf("invalid instruction %q; parent basic block not set", inst))
+	}
+	return block
+}
+
+// instIndex returns the index of the given non-branching instruction within
+// the instructions of the basic block.
+func instIndex(block *BasicBlock, inst Instruction) int {
+	for i, v := range block.Insts {
+		if v == inst {
+			return i
+		}
+	}
+	panic(fmt.Errorf("unable to locate instruction %q in basic block %s", inst, block.Ident()))
+}
+
+// insertAt inserts the given instruction at index i of the instructions of the
+// basic block.
+func insertAt(block *BasicBlock, i int, inst Instruction) {
+	inst.SetParent(block)
+	block.Insts = append(block.Insts, nil)
+	copy(block.Insts[i+1:], block.Insts[i:])
+	block.Insts[i] = inst
+	indexInst(block, inst)
+}
@@ -0,0 +1,68 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestBuilder(t *testing.T) {
+	f := ir.NewFunction("f", types.I32, types.NewParam("x", types.I32))
+	x := f.Params()[0]
+	entry := f.NewBlock("entry")
+	b := ir.NewBuilder(entry)
+	a := b.NewAdd(x, constant.NewInt(1, types.I32))
+	ret := b.NewRet(a)
+	// Insert before an instruction.
+	b.SetInsertPointBefore(a)
+	c := b.NewMul(x, x)
+	d := b.NewMul(c, x)
+	// Insert after an instruction.
+	b.SetInsertPointAfter(a)
+	e := b.NewSub(a, d)
+	ret.X = e
+	// Insert at the start of the block.
+	b.SetInsertPointStart(entry)
+	g := b.NewAlloca(types.I32)
+	const want = `define i32 @f(i32 %x) {
+entry:
+	%0 = alloca i32
+	%1 = mul i32 %x, %x
+	%2 = mul i32 %1, %x
+	%3 = add i32 %x, 1
+	%4 = sub i32 %3, %2
+	ret i32 %4
+}`
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	for _, inst := range entry.Insts {
+		if inst.GetParent() != entry {
+			t.Errorf("parent basic block of %q mismatch; expected %s, got %v", inst, entry.Ident(), inst.GetParent())
+		}
+	}
+
+	// Remove and move instructions.
+	ir.Remove(g)
+	if g.Parent != nil {
+		t.Errorf("parent basic block of removed instruction not reset")
+	}
+	ir.MoveBefore(e, c)
+	ir.InsertAfter(g, d)
+	ir.InsertBefore(ir.NewLoad(g), ret)
+	load := entry.Insts[len(entry.Insts)-1]
+	want2 := []ir.Instruction{e, c, d, g, a, load}
+	if len(entry.Insts) != len(want2) {
+		t.Fatalf("number of instructions mismatch; expected %d, got %d", len(want2), len(entry.Insts))
+	}
+	for i, inst := range entry.Insts {
+		if inst != want2[i] {
+			t.Errorf("instruction %d mismatch; expected %q, got %q", i, want2[i], inst)
+		}
+		if inst.GetParent() != entry {
+			t.Errorf("parent basic block of %q mismatch; expected %s, got %v", inst, entry.Ident(), inst.GetParent())
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// === [ Control flow graph editing ] ==========================================
+
+package ir
+
+import (
+	"fmt"
+)
+
+// Preds returns the unique predecessor basic blocks of the basic block, in
+// order of occurrence within the parent function.
+func (block *BasicBlock) Preds() []*BasicBlock {
+	f := parentFunc(block)
+	var preds []*BasicBlock
+	for _, pred := range f.Blocks {
+		if pred.Term == nil {
+			continue
+		}
+		for _, succ := range pred.Term.Succs() {
+			if succ == block {
+				preds = append(preds, pred)
+				break
+			}
+		}
+	}
+	return preds
+}
+
+// Split splits the basic block at the given instruction, which is moved along
+// with the succeeding instructions and the terminator into a new basic block of
+// the given label name. An empty label name indicates an unnamed basic block.
+// The new basic block is inserted after the original basic block, which is
+// terminated by an unconditional branch to the new basic block.
+//
+// If inst is the terminator of the basic block, the new basic block only
+// contains the terminator. The predecessors of phi instructions in successor
+// basic blocks are updated accordingly.
+func (block *BasicBlock) Split(inst Instruction, name string) *BasicBlock {
+	f := parentFunc(block)
+	i := len(block.Insts)
+	if inst != block.Term {
+		if inst.GetParent() != block {
+			panic(fmt.Errorf("unable to split basic block %s; instruction %q not located in basic block", block.Ident(), inst))
+		}
+		i = instIndex(block, inst)
+	}
+	newBlock := NewBlock(name)
+	newBlock.Parent = f
+	for _, inst := range block.Insts[i:] {
+		newBlock.AppendInst(inst)
+	}
+	for j := i; j < len(block.Insts); j++ {
+		block.Insts[j] = nil
+	}
+	block.Insts = block.Insts[:i]
+	newBlock.SetTerm(block.Term)
+	for _, succ := range uniqueSuccs(newBlock) {
+		replacePhiPreds(succ, block, newBlock)
+	}
+	block.SetTerm(NewBr(newBlock))
+	insertBlockAfter(f, newBlock, block)
+	return newBlock
+}
+
+// MergeIntoPred merges the basic block into its single predecessor basic
+// block, provided that the basic block is the only successor of the
+// predecessor. The phi instructions of the basic block are replaced by their
+// incoming values, and the predecessors of phi instructions in successor basic
+// blocks are updated accordingly. The basic block is removed from its parent
+// function.
+//
+// The boolean return value indicates whether the basic block was merged.
+func (block *BasicBlock) MergeIntoPred() bool {
+	f := parentFunc(block)
+	preds := block.Preds()
+	if len(preds) != 1 {
+		return false
+	}
+	pred := preds[0]
+	if pred == block {
+		return false
+	}
+	for _, succ := range pred.Term.Succs() {
+		if succ != block {
+			return false
+		}
+	}
+	// Replace phi instructions by their incoming values.
+	var insts []Instruction
+	for _, inst := range block.Insts {
+		if phi, ok := inst.(*InstPhi); ok {
+			f.ReplaceAllUsesWith(phi, phi.Incs[0].X)
+			phi.Parent = nil
+			continue
+		}
+		insts = append(insts, inst)
+	}
+	for _, inst := range insts {
+		pred.AppendInst(inst)
+	}
+	pred.SetTerm(block.Term)
+	for _, succ := range uniqueSuccs(pred) {
+		replacePhiPreds(succ, block, pred)
+	}
+	block.Insts = nil
+	block.Term = nil
+	removeBlock(f, block)
+	return true
+}
+
+// IsCriticalEdge reports whether the control flow edge from the basic block
+// from to the basic block to is critical; i.e. from has multiple successors
+// and to has multiple predecessors.
+func IsCriticalEdge(from, to *BasicBlock) bool {
+	return len(uniqueSuccs(from)) > 1 && len(to.Preds()) > 1
+}
+
+// SplitEdge splits the control flow edge from the basic block from to the
+// basic block to, by inserting a new basic block of the given label name which
+// unconditionally branches to to. An empty label name indicates an unnamed
+// basic block. The new basic block is inserted after from.
+//
+// All targets of the terminator of from which refer to to are redirected to the
+// new basic block, and the incoming values of phi instructions in to are
+// updated to refer to the new basic block as predecessor.
+func SplitEdge(from, to *BasicBlock, name string) *BasicBlock {
+	f := parentFunc(from)
+	found := false
+	for _, succ := range from.Term.Succs() {
+		if succ == to {
+			found = true
+			break
+		}
+	}
+	if !found {
+		panic(fmt.Errorf("unable to split edge; basic block %s not a successor of basic block %s", to.Ident(), from.Ident()))
+	}
+	newBlock := NewBlock(name)
+	newBlock.Parent = f
+	newBlock.SetTerm(NewBr(to))
+	replaceTargets(from.Term, to, newBlock)
+	replacePhiPreds(to, from, newBlock)
+	insertBlockAfter(f, newBlock, from)
+	return newBlock
+}
+
+// SplitCriticalEdges splits the critical control flow edges of the function
+// (see IsCriticalEdge and SplitEdge). The number of split edges is returned.
+func (f *Function) SplitCriticalEdges() int {
+	n := 0
+	blocks := make([]*BasicBlock, len(f.Blocks))
+	copy(blocks, f.Blocks)
+	for _, block := range blocks {
+		if block.Term == nil {
+			continue
+		}
+		for _, succ := range uniqueSuccs(block) {
+			if IsCriticalEdge(block, succ) {
+				SplitEdge(block, succ, "")
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ### [ Helper functions ] ####################################################
+
+// parentFunc returns the parent function of the given basic block.
+func parentFunc(block *BasicBlock) *Function {
+	if block.Parent == nil {
+		panic(fmt.Errorf("invalid basic block %s; parent function not set", block.Ident()))
+	}
+	return block.Parent
+}
+
+// uniqueSuccs returns the unique successor basic blocks of the given basic
+// block.
+func uniqueSuccs(block *BasicBlock) []*BasicBlock {
+	if block.Term == nil {
+		return nil
+	}
+	var succs []*BasicBlock
+	seen := make(map[*BasicBlock]bool)
+	for _, succ := range block.Term.Succs() {
+		if !seen[succ] {
+			seen[succ] = true
+			succs = append(succs, succ)
+		}
+	}
+	return succs
+}
+
+// replacePhiPreds replaces the predecessor old of the incoming values of phi
+// instructions in the given basic block with new. Duplicate incoming values of
+// new are removed.
+func replacePhiPreds(block, old, new *BasicBlock) {
+	for _, inst := range block.Insts {
+		phi, ok := inst.(*InstPhi)
+		if !ok {
+			continue
+		}
+		var incs []*Incoming
+		seen := false
+		for _, inc := range phi.Incs {
+			if inc.Pred == old {
+				inc.Pred = new
+			}
+			if inc.Pred == new {
+				if seen {
+					continue
+				}
+				seen = true
+			}
+			incs = append(incs, inc)
+		}
+		phi.Incs = incs
+	}
+}
+
+// insertBlockAfter inserts the given basic block after pos within the basic
+// blocks of f.
+func insertBlockAfter(f *Function, block, pos *BasicBlock) {
+	i := blockIndex(f, pos) + 1
+	f.Blocks = append(f.Blocks, nil)
+	copy(f.Blocks[i+1:], f.Blocks[i:])
+	f.Blocks[i] = block
+}
+
+// removeBlock removes the given basic block from the basic blocks of f.
+func removeBlock(f *Function, block *BasicBlock) {
+	i := blockIndex(f, block)
+	copy(f.Blocks[i:], f.Blocks[i+1:])
+	f.Blocks[len(f.Blocks)-1] = nil
+	f.Blocks = f.Blocks[:len(f.Blocks)-1]
+	block.Parent = nil
+}
+
+// blockIndex returns the index of the given basic block within the basic
+// blocks of f.
+func blockIndex(f *Function, block *BasicBlock) int {
+	for i, b := range f.Blocks {
+		if b == block {
+			return i
+		}
+	}
+	panic(fmt.Errorf("unable to locate basic block %s in function %s", block.Ident(), f.Ident()))
+}
@@ -0,0 +1,148 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestSplit(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x, i1 %c) {
+entry:
+	%a = add i32 %x, 1
+	%b = mul i32 %a, 2
+	br i1 %c, label %then, label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ %b, %entry ], [ %x, %then ]
+	ret i32 %r
+}
+`
+	const want = `define i32 @f(i32 %x, i1 %c) {
+entry:
+	%a = add i32 %x, 1
+	br label %tail
+tail:
+	%b = mul i32 %a, 2
+	br i1 %c, label %then, label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ %b, %tail ], [ %x, %then ]
+	ret i32 %r
+}`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	entry := f.Blocks[0]
+	tail := entry.Split(entry.Insts[1], "tail")
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	if preds := f.Blocks[3].Preds(); len(preds) != 2 || preds[0] != tail || preds[1] != f.Blocks[2] {
+		t.Errorf("predecessors mismatch; got %v", preds)
+	}
+
+	// Merge split basic block back into its predecessor.
+	if !tail.MergeIntoPred() {
+		t.Fatalf("unable to merge basic block %s", tail.Ident())
+	}
+	const want2 = `define i32 @f(i32 %x, i1 %c) {
+entry:
+	%a = add i32 %x, 1
+	%b = mul i32 %a, 2
+	br i1 %c, label %then, label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ %b, %entry ], [ %x, %then ]
+	ret i32 %r
+}`
+	if got := f.String(); got != want2 {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want2, got)
+	}
+	// Basic blocks with multiple predecessors are not merged.
+	if f.Blocks[2].MergeIntoPred() {
+		t.Errorf("unexpected merge of basic block with multiple predecessors")
+	}
+}
+
+func TestMergeIntoPredPhi(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x) {
+entry:
+	br label %next
+next:
+	%p = phi i32 [ %x, %entry ]
+	%y = add i32 %p, 1
+	ret i32 %y
+}
+`
+	const want = `define i32 @f(i32 %x) {
+entry:
+	%y = add i32 %x, 1
+	ret i32 %y
+}`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	if !f.Blocks[1].MergeIntoPred() {
+		t.Fatalf("unable to merge basic block")
+	}
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestSplitCriticalEdges(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x) {
+entry:
+	switch i32 %x, label %exit [
+		i32 0, label %then
+		i32 1, label %exit
+	]
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ 1, %entry ], [ 1, %entry ], [ 2, %then ]
+	ret i32 %r
+}
+`
+	const want = `define i32 @f(i32 %x) {
+entry:
+	switch i32 %x, label %0 [
+		i32 0, label %then
+		i32 1, label %0
+	]
+; <label>:0
+	br label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ 1, %0 ], [ 2, %then ]
+	ret i32 %r
+}`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	entry, exit := f.Blocks[0], f.Blocks[2]
+	if !ir.IsCriticalEdge(entry, exit) {
+		t.Errorf("expected critical edge from %s to %s", entry.Ident(), exit.Ident())
+	}
+	if n := f.SplitCriticalEdges(); n != 1 {
+		t.Errorf("number of split edges mismatch; expected 1, got %d", n)
+	}
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+}
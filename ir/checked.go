@@ -0,0 +1,1206 @@
+// === [ Checked instruction builder ] =========================================
+
+package ir
+
+import (
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// A CheckedBuilder inserts new instructions at an insertion point within a
+// basic block, after validating their operands.
+//
+// Contrary to Builder, which may produce malformed IR or panic when given
+// invalid operands, the instruction constructors of CheckedBuilder return a
+// descriptive error, and leave the basic block unmodified. The operand types,
+// the element types of pointers, the number and types of function call
+// arguments, and the element indices of getelementptr instructions are
+// validated.
+type CheckedBuilder struct {
+	*Builder
+}
+
+// NewCheckedBuilder returns a new checked instruction builder with an
+// insertion point at the end of the given basic block.
+func NewCheckedBuilder(block *BasicBlock) *CheckedBuilder {
+	return &CheckedBuilder{Builder: NewBuilder(block)}
+}
+
+// NewAdd inserts a new add instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewAdd(x, y value.Value) (*InstAdd, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("add", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewAdd(x, y), nil
+}
+
+// NewFAdd inserts a new fadd instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewFAdd(x, y value.Value) (*InstFAdd, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFloatBinary("fadd", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFAdd(x, y), nil
+}
+
+// NewSub inserts a new sub instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewSub(x, y value.Value) (*InstSub, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("sub", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSub(x, y), nil
+}
+
+// NewFSub inserts a new fsub instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewFSub(x, y value.Value) (*InstFSub, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFloatBinary("fsub", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFSub(x, y), nil
+}
+
+// NewMul inserts a new mul instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewMul(x, y value.Value) (*InstMul, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("mul", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewMul(x, y), nil
+}
+
+// NewFMul inserts a new fmul instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewFMul(x, y value.Value) (*InstFMul, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFloatBinary("fmul", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFMul(x, y), nil
+}
+
+// NewUDiv inserts a new udiv instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewUDiv(x, y value.Value) (*InstUDiv, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("udiv", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewUDiv(x, y), nil
+}
+
+// NewSDiv inserts a new sdiv instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewSDiv(x, y value.Value) (*InstSDiv, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("sdiv", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSDiv(x, y), nil
+}
+
+// NewFDiv inserts a new fdiv instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewFDiv(x, y value.Value) (*InstFDiv, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFloatBinary("fdiv", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFDiv(x, y), nil
+}
+
+// NewURem inserts a new urem instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewURem(x, y value.Value) (*InstURem, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("urem", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewURem(x, y), nil
+}
+
+// NewSRem inserts a new srem instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewSRem(x, y value.Value) (*InstSRem, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("srem", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSRem(x, y), nil
+}
+
+// NewFRem inserts a new frem instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewFRem(x, y value.Value) (*InstFRem, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFloatBinary("frem", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFRem(x, y), nil
+}
+
+// NewShl inserts a new shl instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewShl(x, y value.Value) (*InstShl, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("shl", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewShl(x, y), nil
+}
+
+// NewLShr inserts a new lshr instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewLShr(x, y value.Value) (*InstLShr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("lshr", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewLShr(x, y), nil
+}
+
+// NewAShr inserts a new ashr instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewAShr(x, y value.Value) (*InstAShr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("ashr", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewAShr(x, y), nil
+}
+
+// NewAnd inserts a new and instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewAnd(x, y value.Value) (*InstAnd, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("and", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewAnd(x, y), nil
+}
+
+// NewOr inserts a new or instruction at the insertion point based on the given
+// operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewOr(x, y value.Value) (*InstOr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("or", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewOr(x, y), nil
+}
+
+// NewXor inserts a new xor instruction at the insertion point based on the
+// given operands. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewXor(x, y value.Value) (*InstXor, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkIntBinary("xor", x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewXor(x, y), nil
+}
+
+// NewExtractElement inserts a new extractelement instruction at the insertion
+// point based on the given vector and index. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewExtractElement(x, index value.Value) (*InstExtractElement, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkExtractElement(x, index); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewExtractElement(x, index), nil
+}
+
+// NewInsertElement inserts a new insertelement instruction at the insertion
+// point based on the given vector, element and index. An error is returned if
+// the operands are invalid.
+func (b *CheckedBuilder) NewInsertElement(x, elem, index value.Value) (*InstInsertElement, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkInsertElement(x, elem, index); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewInsertElement(x, elem, index), nil
+}
+
+// NewShuffleVector inserts a new shufflevector instruction at the insertion
+// point based on the given vectors and shuffle mask. An error is returned if
+// the operands are invalid.
+func (b *CheckedBuilder) NewShuffleVector(x, y, mask value.Value) (*InstShuffleVector, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkShuffleVector(x, y, mask); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewShuffleVector(x, y, mask), nil
+}
+
+// NewExtractValue inserts a new extractvalue instruction at the insertion point
+// based on the given vector and indices. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewExtractValue(x value.Value, indices []int64) (*InstExtractValue, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkExtractValue(x, indices); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewExtractValue(x, indices), nil
+}
+
+// NewInsertValue inserts a new insertvalue instruction at the insertion point
+// based on the given vector, element and indices. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewInsertValue(x, elem value.Value, indices []int64) (*InstInsertValue, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkInsertValue(x, elem, indices); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewInsertValue(x, elem, indices), nil
+}
+
+// NewAlloca inserts a new alloca instruction at the insertion point based on
+// the given element type. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewAlloca(elem types.Type) (*InstAlloca, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkAlloca(elem); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewAlloca(elem), nil
+}
+
+// NewLoad inserts a new load instruction at the insertion point based on the
+// given source address. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewLoad(src value.Value) (*InstLoad, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkLoad(src); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewLoad(src), nil
+}
+
+// NewStore inserts a new store instruction at the insertion point based on the
+// given source value and destination address. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewStore(src, dst value.Value) (*InstStore, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkStore(src, dst); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewStore(src, dst), nil
+}
+
+// NewGetElementPtr inserts a new getelementptr instruction at the insertion
+// point based on the given source address and element indices. An error is
+// returned if the operands are invalid.
+func (b *CheckedBuilder) NewGetElementPtr(src value.Value, indices ...value.Value) (*InstGetElementPtr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkGetElementPtr(src, indices); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewGetElementPtr(src, indices...), nil
+}
+
+// NewTrunc inserts a new trunc instruction at the insertion point based on the
+// given source value and target type. An error is returned if the operands are
+// invalid.
+func (b *CheckedBuilder) NewTrunc(from value.Value, to types.Type) (*InstTrunc, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("trunc", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewTrunc(from, to), nil
+}
+
+// NewZExt inserts a new zext instruction at the insertion point based on the
+// given source value and target type. An error is returned if the operands are
+// invalid.
+func (b *CheckedBuilder) NewZExt(from value.Value, to types.Type) (*InstZExt, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("zext", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewZExt(from, to), nil
+}
+
+// NewSExt inserts a new sext instruction at the insertion point based on the
+// given source value and target type. An error is returned if the operands are
+// invalid.
+func (b *CheckedBuilder) NewSExt(from value.Value, to types.Type) (*InstSExt, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("sext", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSExt(from, to), nil
+}
+
+// NewFPTrunc inserts a new fptrunc instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewFPTrunc(from value.Value, to types.Type) (*InstFPTrunc, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("fptrunc", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFPTrunc(from, to), nil
+}
+
+// NewFPExt inserts a new fpext instruction at the insertion point based on the
+// given source value and target type. An error is returned if the operands are
+// invalid.
+func (b *CheckedBuilder) NewFPExt(from value.Value, to types.Type) (*InstFPExt, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("fpext", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFPExt(from, to), nil
+}
+
+// NewFPToUI inserts a new fptoui instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewFPToUI(from value.Value, to types.Type) (*InstFPToUI, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("fptoui", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFPToUI(from, to), nil
+}
+
+// NewFPToSI inserts a new fptosi instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewFPToSI(from value.Value, to types.Type) (*InstFPToSI, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("fptosi", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFPToSI(from, to), nil
+}
+
+// NewUIToFP inserts a new uitofp instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewUIToFP(from value.Value, to types.Type) (*InstUIToFP, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("uitofp", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewUIToFP(from, to), nil
+}
+
+// NewSIToFP inserts a new sitofp instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewSIToFP(from value.Value, to types.Type) (*InstSIToFP, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("sitofp", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSIToFP(from, to), nil
+}
+
+// NewPtrToInt inserts a new ptrtoint instruction at the insertion point based
+// on the given source value and target type. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewPtrToInt(from value.Value, to types.Type) (*InstPtrToInt, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("ptrtoint", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewPtrToInt(from, to), nil
+}
+
+// NewIntToPtr inserts a new inttoptr instruction at the insertion point based
+// on the given source value and target type. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewIntToPtr(from value.Value, to types.Type) (*InstIntToPtr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("inttoptr", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewIntToPtr(from, to), nil
+}
+
+// NewBitCast inserts a new bitcast instruction at the insertion point based on
+// the given source value and target type. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewBitCast(from value.Value, to types.Type) (*InstBitCast, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("bitcast", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewBitCast(from, to), nil
+}
+
+// NewAddrSpaceCast inserts a new addrspacecast instruction at the insertion
+// point based on the given source value and target type. An error is returned
+// if the operands are invalid.
+func (b *CheckedBuilder) NewAddrSpaceCast(from value.Value, to types.Type) (*InstAddrSpaceCast, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkConversion("addrspacecast", from, to); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewAddrSpaceCast(from, to), nil
+}
+
+// NewICmp inserts a new icmp instruction at the insertion point based on the
+// given integer condition code and operands. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewICmp(pred IntPred, x, y value.Value) (*InstICmp, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkICmp(x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewICmp(pred, x, y), nil
+}
+
+// NewFCmp inserts a new fcmp instruction at the insertion point based on the
+// given floating-point condition code and operands. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewFCmp(pred FloatPred, x, y value.Value) (*InstFCmp, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFCmp(x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFCmp(pred, x, y), nil
+}
+
+// NewPhi inserts a new phi instruction at the insertion point based on the
+// given incoming values. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewPhi(incs ...*Incoming) (*InstPhi, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkPhi(incs); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewPhi(incs...), nil
+}
+
+// NewSelect inserts a new select instruction at the insertion point based on
+// the given selection condition and operands. An error is returned if the
+// operands are invalid.
+func (b *CheckedBuilder) NewSelect(cond, x, y value.Value) (*InstSelect, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkSelect(cond, x, y); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSelect(cond, x, y), nil
+}
+
+// NewFreeze inserts a new freeze instruction at the insertion point based on
+// the given operand. An error is returned if the operand is invalid.
+func (b *CheckedBuilder) NewFreeze(x value.Value) (*InstFreeze, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkFreeze(x); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewFreeze(x), nil
+}
+
+// NewCall inserts a new call instruction at the insertion point based on the
+// given callee and function arguments. An error is returned if the operands
+// are invalid.
+func (b *CheckedBuilder) NewCall(callee value.Named, args ...value.Value) (*InstCall, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkCall(callee, args); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewCall(callee, args...), nil
+}
+
+// NewRet sets the terminator of the basic block of the insertion point to a new
+// ret terminator based on the given return value. A nil return value indicates
+// a "void" return. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewRet(x value.Value) (*TermRet, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := b.checkRet(x); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewRet(x), nil
+}
+
+// NewBr sets the terminator of the basic block of the insertion point to a new
+// unconditional br terminator based on the given target branch. An error is
+// returned if the operands are invalid.
+func (b *CheckedBuilder) NewBr(target *BasicBlock) (*TermBr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkBr(target); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewBr(target), nil
+}
+
+// NewCondBr sets the terminator of the basic block of the insertion point to a
+// new conditional br terminator based on the given branching condition and
+// conditional target branches. An error is returned if the operands are
+// invalid.
+func (b *CheckedBuilder) NewCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) (*TermCondBr, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkCondBr(cond, targetTrue, targetFalse); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewCondBr(cond, targetTrue, targetFalse), nil
+}
+
+// NewSwitch sets the terminator of the basic block of the insertion point to a
+// new switch terminator based on the given control variable, default target
+// branch and switch cases. An error is returned if the operands are invalid.
+func (b *CheckedBuilder) NewSwitch(x value.Value, targetDefault *BasicBlock, cases ...*Case) (*TermSwitch, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	if err := checkSwitch(x, targetDefault, cases); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewSwitch(x, targetDefault, cases...), nil
+}
+
+// NewUnreachable sets the terminator of the basic block of the insertion point
+// to a new unreachable terminator.
+func (b *CheckedBuilder) NewUnreachable() (*TermUnreachable, error) {
+	if err := b.checkInsertPoint(); err != nil {
+		return nil, err
+	}
+	return b.Builder.NewUnreachable(), nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// checkInsertPoint validates the insertion point of the checked builder.
+func (b *CheckedBuilder) checkInsertPoint() error {
+	if b.block == nil {
+		return errors.New("invalid insertion point; basic block not set")
+	}
+	return nil
+}
+
+// checkIntBinary validates the operands of the integer binary or bitwise
+// instruction with the given opcode.
+func checkIntBinary(op string, x, y value.Value) error {
+	if err := checkOperands(op, x, y); err != nil {
+		return err
+	}
+	if !types.IsInt(scalarType(x.Type())) {
+		return errors.Errorf("invalid %s operand type; expected integer or vector of integers, got %v", op, x.Type())
+	}
+	return checkSameType(op, x, y)
+}
+
+// checkFloatBinary validates the operands of the floating-point binary
+// instruction with the given opcode.
+func checkFloatBinary(op string, x, y value.Value) error {
+	if err := checkOperands(op, x, y); err != nil {
+		return err
+	}
+	if !types.IsFloat(scalarType(x.Type())) {
+		return errors.Errorf("invalid %s operand type; expected floating-point or vector of floating-points, got %v", op, x.Type())
+	}
+	return checkSameType(op, x, y)
+}
+
+// checkExtractElement validates the operands of an extractelement instruction.
+func checkExtractElement(x, index value.Value) error {
+	if err := checkOperands("extractelement", x, index); err != nil {
+		return err
+	}
+	if _, ok := x.Type().(*types.VectorType); !ok {
+		return errors.Errorf("invalid extractelement vector type; expected *types.VectorType, got %v", x.Type())
+	}
+	return checkIndex("extractelement", index)
+}
+
+// checkInsertElement validates the operands of an insertelement instruction.
+func checkInsertElement(x, elem, index value.Value) error {
+	if err := checkOperands("insertelement", x, elem, index); err != nil {
+		return err
+	}
+	t, ok := x.Type().(*types.VectorType)
+	if !ok {
+		return errors.Errorf("invalid insertelement vector type; expected *types.VectorType, got %v", x.Type())
+	}
+	if !t.Elem.Equal(elem.Type()) {
+		return errors.Errorf("invalid insertelement element type; expected %v, got %v", t.Elem, elem.Type())
+	}
+	return checkIndex("insertelement", index)
+}
+
+// checkShuffleVector validates the operands of a shufflevector instruction.
+func checkShuffleVector(x, y, mask value.Value) error {
+	if err := checkOperands("shufflevector", x, y, mask); err != nil {
+		return err
+	}
+	if _, ok := x.Type().(*types.VectorType); !ok {
+		return errors.Errorf("invalid shufflevector vector type; expected *types.VectorType, got %v", x.Type())
+	}
+	if err := checkSameType("shufflevector", x, y); err != nil {
+		return err
+	}
+	t, ok := mask.Type().(*types.VectorType)
+	if !ok || !t.Elem.Equal(types.I32) {
+		return errors.Errorf("invalid shufflevector mask type; expected vector of i32, got %v", mask.Type())
+	}
+	return nil
+}
+
+// checkExtractValue validates the operands of an extractvalue instruction.
+func checkExtractValue(x value.Value, indices []int64) error {
+	if err := checkOperands("extractvalue", x); err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		return errors.New("invalid extractvalue instruction; missing element indices")
+	}
+	if _, err := aggregateElemType(x.Type(), indices); err != nil {
+		return errors.Wrap(err, "invalid extractvalue instruction")
+	}
+	return nil
+}
+
+// checkInsertValue validates the operands of an insertvalue instruction.
+func checkInsertValue(x, elem value.Value, indices []int64) error {
+	if err := checkOperands("insertvalue", x, elem); err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		return errors.New("invalid insertvalue instruction; missing element indices")
+	}
+	e, err := aggregateElemType(x.Type(), indices)
+	if err != nil {
+		return errors.Wrap(err, "invalid insertvalue instruction")
+	}
+	if !e.Equal(elem.Type()) {
+		return errors.Errorf("invalid insertvalue element type; expected %v, got %v", e, elem.Type())
+	}
+	return nil
+}
+
+// checkAlloca validates the element type of an alloca instruction.
+func checkAlloca(elem types.Type) error {
+	if elem == nil {
+		return errors.New("invalid alloca element type; nil type")
+	}
+	if !isSized(elem) {
+		return errors.Errorf("invalid alloca element type; expected sized type, got %v", elem)
+	}
+	return nil
+}
+
+// checkLoad validates the source address of a load instruction.
+func checkLoad(src value.Value) error {
+	if err := checkOperands("load", src); err != nil {
+		return err
+	}
+	t, ok := src.Type().(*types.PointerType)
+	if !ok {
+		return errors.Errorf("invalid load source address type; expected *types.PointerType, got %v", src.Type())
+	}
+	if !isSized(t.Elem) {
+		return errors.Errorf("invalid load element type; expected sized type, got %v", t.Elem)
+	}
+	return nil
+}
+
+// checkStore validates the operands of a store instruction.
+func checkStore(src, dst value.Value) error {
+	if err := checkOperands("store", src, dst); err != nil {
+		return err
+	}
+	t, ok := dst.Type().(*types.PointerType)
+	if !ok {
+		return errors.Errorf("invalid store destination address type; expected *types.PointerType, got %v", dst.Type())
+	}
+	if !t.Elem.Equal(src.Type()) {
+		return errors.Errorf("invalid store source type; expected %v (element type of destination address), got %v", t.Elem, src.Type())
+	}
+	return nil
+}
+
+// checkGetElementPtr validates the operands of a getelementptr instruction.
+func checkGetElementPtr(src value.Value, indices []value.Value) error {
+	if err := checkOperands("getelementptr", append([]value.Value{src}, indices...)...); err != nil {
+		return err
+	}
+	t, ok := src.Type().(*types.PointerType)
+	if !ok {
+		return errors.Errorf("invalid getelementptr source address type; expected *types.PointerType, got %v", src.Type())
+	}
+	e := t.Elem
+	for i, index := range indices {
+		if err := checkIndex("getelementptr", index); err != nil {
+			return err
+		}
+		if i == 0 {
+			// The 0th index steps through the source address.
+			continue
+		}
+		switch t := e.(type) {
+		case *types.ArrayType:
+			e = t.Elem
+		case *types.VectorType:
+			e = t.Elem
+		case *types.StructType:
+			idx, ok := index.(*constant.Int)
+			if !ok {
+				return errors.Errorf("invalid getelementptr index %d; expected constant integer for struct type %v, got %v", i, t, index.Ident())
+			}
+			if !idx.X.IsInt64() || idx.Int64() < 0 || idx.Int64() >= int64(len(t.Fields)) {
+				return errors.Errorf("invalid getelementptr index %d (%v); exceeds struct field count (%d)", i, idx.X, len(t.Fields))
+			}
+			e = t.Fields[idx.Int64()]
+		default:
+			return errors.Errorf("invalid getelementptr index %d; unable to index into element of type %v", i, e)
+		}
+	}
+	return nil
+}
+
+// checkConversion validates the operand and target type of the conversion
+// instruction with the given opcode.
+func checkConversion(op string, from value.Value, to types.Type) error {
+	if err := checkOperands(op, from); err != nil {
+		return err
+	}
+	if to == nil {
+		return errors.Errorf("invalid %s target type; nil type", op)
+	}
+	fromType := from.Type()
+	// Vector conversions are validated element-wise.
+	if t, ok := fromType.(*types.VectorType); ok && op != "bitcast" {
+		u, ok := to.(*types.VectorType)
+		if !ok || t.Len != u.Len {
+			return errors.Errorf("invalid %s target type; expected vector of length %d, got %v", op, t.Len, to)
+		}
+		fromType, to = t.Elem, u.Elem
+	}
+	fromSize, toSize := primitiveSize(fromType), primitiveSize(to)
+	invalid := func() error {
+		return errors.Errorf("invalid %s conversion from %v to %v", op, fromType, to)
+	}
+	switch op {
+	case "trunc":
+		if !types.IsInt(fromType) || !types.IsInt(to) || fromSize <= toSize {
+			return invalid()
+		}
+	case "zext", "sext":
+		if !types.IsInt(fromType) || !types.IsInt(to) || fromSize >= toSize {
+			return invalid()
+		}
+	case "fptrunc":
+		if !types.IsFloat(fromType) || !types.IsFloat(to) || fromSize <= toSize {
+			return invalid()
+		}
+	case "fpext":
+		if !types.IsFloat(fromType) || !types.IsFloat(to) || fromSize >= toSize {
+			return invalid()
+		}
+	case "fptoui", "fptosi":
+		if !types.IsFloat(fromType) || !types.IsInt(to) {
+			return invalid()
+		}
+	case "uitofp", "sitofp":
+		if !types.IsInt(fromType) || !types.IsFloat(to) {
+			return invalid()
+		}
+	case "ptrtoint":
+		if !types.IsPointer(fromType) || !types.IsInt(to) {
+			return invalid()
+		}
+	case "inttoptr":
+		if !types.IsInt(fromType) || !types.IsPointer(to) {
+			return invalid()
+		}
+	case "bitcast":
+		if types.IsPointer(scalarType(fromType)) || types.IsPointer(scalarType(to)) {
+			// Pointers may only be converted to pointers of the same address
+			// space.
+			if !isPointerOfAddrSpace(scalarType(fromType), scalarType(to)) || vectorLen(fromType) != vectorLen(to) {
+				return invalid()
+			}
+			return nil
+		}
+		size, toSize := bitSize(fromType), bitSize(to)
+		if size == 0 || size != toSize {
+			return invalid()
+		}
+	case "addrspacecast":
+		s, ok := fromType.(*types.PointerType)
+		if !ok {
+			return invalid()
+		}
+		t, ok := to.(*types.PointerType)
+		if !ok || s.AddrSpace == t.AddrSpace {
+			return invalid()
+		}
+	default:
+		return errors.Errorf("support for conversion opcode %q not yet implemented", op)
+	}
+	return nil
+}
+
+// checkICmp validates the operands of an icmp instruction.
+func checkICmp(x, y value.Value) error {
+	if err := checkOperands("icmp", x, y); err != nil {
+		return err
+	}
+	if t := scalarType(x.Type()); !types.IsInt(t) && !types.IsPointer(t) {
+		return errors.Errorf("invalid icmp operand type; expected integer, pointer or vector of integers or pointers, got %v", x.Type())
+	}
+	return checkSameType("icmp", x, y)
+}
+
+// checkFCmp validates the operands of an fcmp instruction.
+func checkFCmp(x, y value.Value) error {
+	if err := checkOperands("fcmp", x, y); err != nil {
+		return err
+	}
+	if !types.IsFloat(scalarType(x.Type())) {
+		return errors.Errorf("invalid fcmp operand type; expected floating-point or vector of floating-points, got %v", x.Type())
+	}
+	return checkSameType("fcmp", x, y)
+}
+
+// checkPhi validates the incoming values of a phi instruction.
+func checkPhi(incs []*Incoming) error {
+	if len(incs) == 0 {
+		return errors.New("invalid number of phi incoming values; expected > 0, got 0")
+	}
+	for i, inc := range incs {
+		if inc == nil || inc.X == nil {
+			return errors.Errorf("invalid phi incoming value %d; nil value", i)
+		}
+		if inc.Pred == nil {
+			return errors.Errorf("invalid phi incoming value %d; nil predecessor basic block", i)
+		}
+		if !inc.X.Type().Equal(incs[0].X.Type()) {
+			return errors.Errorf("invalid phi incoming value %d type; expected %v, got %v", i, incs[0].X.Type(), inc.X.Type())
+		}
+	}
+	return nil
+}
+
+// checkSelect validates the operands of a select instruction.
+func checkSelect(cond, x, y value.Value) error {
+	if err := checkOperands("select", cond, x, y); err != nil {
+		return err
+	}
+	switch t := cond.Type().(type) {
+	case *types.VectorType:
+		u, ok := x.Type().(*types.VectorType)
+		if !types.IsBool(t.Elem) || !ok || t.Len != u.Len {
+			return errors.Errorf("invalid select condition type; expected i1 or vector of i1 of length matching %v, got %v", x.Type(), t)
+		}
+	default:
+		if !types.IsBool(t) {
+			return errors.Errorf("invalid select condition type; expected i1 or vector of i1, got %v", t)
+		}
+	}
+	return checkSameType("select", x, y)
+}
+
+// checkFreeze validates the operand of a freeze instruction.
+func checkFreeze(x value.Value) error {
+	if err := checkOperands("freeze", x); err != nil {
+		return err
+	}
+	if !isSized(x.Type()) {
+		return errors.Errorf("invalid freeze operand type; expected first-class type, got %v", x.Type())
+	}
+	return nil
+}
+
+// checkCall validates the callee and function arguments of a call instruction.
+func checkCall(callee value.Named, args []value.Value) error {
+	if callee == nil {
+		return errors.New("invalid call callee; nil value")
+	}
+	if err := checkOperands("call", args...); err != nil {
+		return err
+	}
+	t, ok := callee.Type().(*types.PointerType)
+	if !ok {
+		return errors.Errorf("invalid callee type; expected *types.PointerType, got %v", callee.Type())
+	}
+	sig, ok := t.Elem.(*types.FuncType)
+	if !ok {
+		return errors.Errorf("invalid callee signature type; expected *types.FuncType, got %v", t.Elem)
+	}
+	switch {
+	case sig.Variadic && len(args) < len(sig.Params):
+		return errors.Errorf("invalid number of arguments in call to %s; expected >= %d, got %d", callee.Ident(), len(sig.Params), len(args))
+	case !sig.Variadic && len(args) != len(sig.Params):
+		return errors.Errorf("invalid number of arguments in call to %s; expected %d, got %d", callee.Ident(), len(sig.Params), len(args))
+	}
+	for i, param := range sig.Params {
+		if !param.Typ.Equal(args[i].Type()) {
+			return errors.Errorf("invalid argument %d type in call to %s; expected %v, got %v", i, callee.Ident(), param.Typ, args[i].Type())
+		}
+	}
+	return nil
+}
+
+// checkRet validates the return value of a ret terminator, based on the
+// return type of the parent function of the insertion point, if known.
+func (b *CheckedBuilder) checkRet(x value.Value) error {
+	var ret types.Type
+	if f := b.block.Parent; f != nil && f.Sig != nil {
+		ret = f.Sig.Ret
+	}
+	if x == nil {
+		if ret != nil && !types.IsVoid(ret) {
+			return errors.Errorf("invalid void return in function returning %v", ret)
+		}
+		return nil
+	}
+	if ret != nil && !ret.Equal(x.Type()) {
+		return errors.Errorf("invalid return value type; expected %v, got %v", ret, x.Type())
+	}
+	return nil
+}
+
+// checkBr validates the target branch of a br terminator.
+func checkBr(target *BasicBlock) error {
+	if target == nil {
+		return errors.New("invalid br target branch; nil basic block")
+	}
+	return nil
+}
+
+// checkCondBr validates the operands of a conditional br terminator.
+func checkCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) error {
+	if err := checkOperands("br", cond); err != nil {
+		return err
+	}
+	if !types.IsBool(cond.Type()) {
+		return errors.Errorf("invalid br condition type; expected i1, got %v", cond.Type())
+	}
+	if targetTrue == nil || targetFalse == nil {
+		return errors.New("invalid br target branch; nil basic block")
+	}
+	return nil
+}
+
+// checkSwitch validates the operands of a switch terminator.
+func checkSwitch(x value.Value, targetDefault *BasicBlock, cases []*Case) error {
+	if err := checkOperands("switch", x); err != nil {
+		return err
+	}
+	if !types.IsInt(x.Type()) {
+		return errors.Errorf("invalid switch control variable type; expected integer, got %v", x.Type())
+	}
+	if targetDefault == nil {
+		return errors.New("invalid switch default target branch; nil basic block")
+	}
+	for i, c := range cases {
+		if c == nil || c.X == nil || c.Target == nil {
+			return errors.Errorf("invalid switch case %d; nil comparand or target branch", i)
+		}
+		if !c.X.Type().Equal(x.Type()) {
+			return errors.Errorf("invalid switch case %d comparand type; expected %v, got %v", i, x.Type(), c.X.Type())
+		}
+	}
+	return nil
+}
+
+// checkOperands reports an error if any of the given operands of the
+// instruction with the given opcode is nil.
+func checkOperands(op string, operands ...value.Value) error {
+	for i, operand := range operands {
+		if operand == nil {
+			return errors.Errorf("invalid %s operand %d; nil value", op, i)
+		}
+	}
+	return nil
+}
+
+// checkSameType reports an error if the given operands of the instruction with
+// the given opcode have different types.
+func checkSameType(op string, x, y value.Value) error {
+	if !x.Type().Equal(y.Type()) {
+		return errors.Errorf("%s operand type mismatch; %v and %v", op, x.Type(), y.Type())
+	}
+	return nil
+}
+
+// checkIndex reports an error if the given index of the instruction with the
+// given opcode is not of integer type.
+func checkIndex(op string, index value.Value) error {
+	if !types.IsInt(scalarType(index.Type())) {
+		return errors.Errorf("invalid %s index type; expected integer, got %v", op, index.Type())
+	}
+	return nil
+}
+
+// scalarType returns the element type of the given vector type, or t itself if
+// not a vector type.
+func scalarType(t types.Type) types.Type {
+	if t, ok := t.(*types.VectorType); ok {
+		return t.Elem
+	}
+	return t
+}
+
+// vectorLen returns the length of the given vector type, or -1 if not a vector
+// type.
+func vectorLen(t types.Type) int64 {
+	if t, ok := t.(*types.VectorType); ok {
+		return t.Len
+	}
+	return -1
+}
+
+// isPointerOfAddrSpace reports whether t and u are both pointer types of the
+// same address space.
+func isPointerOfAddrSpace(t, u types.Type) bool {
+	s, ok := t.(*types.PointerType)
+	if !ok {
+		return false
+	}
+	v, ok := u.(*types.PointerType)
+	return ok && s.AddrSpace == v.AddrSpace
+}
+
+// isSized reports whether values of the given type have a size in memory.
+func isSized(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.VoidType, *types.FuncType, *types.LabelType, *types.MetadataType:
+		return false
+	case *types.StructType:
+		return !t.Opaque
+	default:
+		return true
+	}
+}
+
+// primitiveSize returns the size in bits of the given integer or
+// floating-point type, or 0 otherwise.
+func primitiveSize(t types.Type) int64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return int64(t.Size)
+	case *types.FloatType:
+		switch t.Kind {
+		case types.FloatKindIEEE_16:
+			return 16
+		case types.FloatKindIEEE_32:
+			return 32
+		case types.FloatKindIEEE_64:
+			return 64
+		case types.FloatKindDoubleExtended_80:
+			return 80
+		case types.FloatKindIEEE_128, types.FloatKindDoubleDouble_128:
+			return 128
+		}
+	}
+	return 0
+}
+
+// bitSize returns the size in bits of the given first-class non-aggregate type,
+// or 0 otherwise.
+func bitSize(t types.Type) int64 {
+	if t, ok := t.(*types.VectorType); ok {
+		return t.Len * primitiveSize(t.Elem)
+	}
+	return primitiveSize(t)
+}
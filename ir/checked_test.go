@@ -0,0 +1,93 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestCheckedBuilder(t *testing.T) {
+	m := ir.NewModule()
+	st := types.NewStruct(types.I32, types.Double)
+	g := m.NewGlobalDef("g", constant.NewZeroInitializer(st))
+	v := m.NewGlobalDef("v", constant.NewZeroInitializer(types.NewVector(types.I32, 4)))
+	callee := m.NewFunction("callee", types.I32, types.NewParam("x", types.I32))
+	f := m.NewFunction("f", types.I32, types.NewParam("x", types.I32), types.NewParam("y", types.Double))
+	x, y := f.Params()[0], f.Params()[1]
+	entry := f.NewBlock("entry")
+	b := ir.NewCheckedBuilder(entry)
+	zero, one := constant.NewInt(0, types.I32), constant.NewInt(1, types.I32)
+	golden := []struct {
+		name string
+		f    func() (ir.Instruction, error)
+		// Substring of the expected error; or empty if valid.
+		want string
+	}{
+		{name: "add", f: func() (ir.Instruction, error) { return b.NewAdd(x, one) }},
+		{name: "add mismatch", f: func() (ir.Instruction, error) { return b.NewAdd(x, constant.NewInt(1, types.I64)) }, want: "add operand type mismatch"},
+		{name: "add float", f: func() (ir.Instruction, error) { return b.NewAdd(y, y) }, want: "invalid add operand type"},
+		{name: "fadd int", f: func() (ir.Instruction, error) { return b.NewFAdd(x, x) }, want: "invalid fadd operand type"},
+		{name: "nil operand", f: func() (ir.Instruction, error) { return b.NewMul(x, nil) }, want: "invalid mul operand 1; nil value"},
+		{name: "load", f: func() (ir.Instruction, error) { return b.NewLoad(g) }},
+		{name: "load non-pointer", f: func() (ir.Instruction, error) { return b.NewLoad(x) }, want: "invalid load source address type"},
+		{name: "store mismatch", f: func() (ir.Instruction, error) { return b.NewStore(y, g) }, want: "invalid store source type"},
+		{name: "gep", f: func() (ir.Instruction, error) { return b.NewGetElementPtr(g, zero, one) }},
+		{name: "gep struct index", f: func() (ir.Instruction, error) { return b.NewGetElementPtr(g, zero, x) }, want: "expected constant integer"},
+		{name: "gep struct range", f: func() (ir.Instruction, error) {
+			return b.NewGetElementPtr(g, zero, constant.NewInt(2, types.I32))
+		}, want: "exceeds struct field count"},
+		{name: "gep vector", f: func() (ir.Instruction, error) { return b.NewGetElementPtr(v, zero, one) }},
+		{name: "gep scalar", f: func() (ir.Instruction, error) { return b.NewGetElementPtr(g, zero, one, zero) }, want: "unable to index into element of type double"},
+		{name: "call", f: func() (ir.Instruction, error) { return b.NewCall(callee, x) }},
+		{name: "call argument count", f: func() (ir.Instruction, error) { return b.NewCall(callee) }, want: "invalid number of arguments in call to @callee; expected 1, got 0"},
+		{name: "call argument type", f: func() (ir.Instruction, error) { return b.NewCall(callee, y) }, want: "invalid argument 0 type"},
+		{name: "trunc", f: func() (ir.Instruction, error) { return b.NewTrunc(x, types.I8) }},
+		{name: "trunc widening", f: func() (ir.Instruction, error) { return b.NewTrunc(x, types.I64) }, want: "invalid trunc conversion from i32 to i64"},
+		{name: "bitcast size", f: func() (ir.Instruction, error) { return b.NewBitCast(x, types.Double) }, want: "invalid bitcast conversion"},
+		{name: "bitcast", f: func() (ir.Instruction, error) { return b.NewBitCast(y, types.I64) }},
+		{name: "extractvalue range", f: func() (ir.Instruction, error) {
+			return b.NewExtractValue(constant.NewZeroInitializer(st), []int64{3})
+		}, want: "exceeds struct field count"},
+		{name: "select condition", f: func() (ir.Instruction, error) { return b.NewSelect(x, x, x) }, want: "invalid select condition type"},
+		{name: "freeze", f: func() (ir.Instruction, error) { return b.NewFreeze(y) }},
+		{name: "freeze label", f: func() (ir.Instruction, error) { return b.NewFreeze(entry) }, want: "invalid freeze operand type"},
+		{name: "ret mismatch", f: func() (ir.Instruction, error) { return b.NewRet(y) }, want: "invalid return value type; expected i32, got double"},
+		{name: "ret", f: func() (ir.Instruction, error) { return b.NewRet(x) }},
+	}
+	for _, g := range golden {
+		inst, err := g.f()
+		switch {
+		case len(g.want) == 0 && err != nil:
+			t.Errorf("%s: unexpected error; %v", g.name, err)
+		case len(g.want) == 0 && inst == nil:
+			t.Errorf("%s: missing instruction", g.name)
+		case len(g.want) > 0 && err == nil:
+			t.Errorf("%s: expected error containing %q, got nil", g.name, g.want)
+		case len(g.want) > 0 && !strings.Contains(err.Error(), g.want):
+			t.Errorf("%s: error mismatch; expected error containing %q, got %q", g.name, g.want, err)
+		}
+	}
+	// Invalid instructions are not inserted.
+	const want = `define i32 @f(i32 %x, double %y) {
+entry:
+	%0 = add i32 %x, 1
+	%1 = load { i32, double }, { i32, double }* @g
+	%2 = getelementptr { i32, double }, { i32, double }* @g, i32 0, i32 1
+	%3 = getelementptr <4 x i32>, <4 x i32>* @v, i32 0, i32 1
+	%4 = call i32 @callee(i32 %x)
+	%5 = trunc i32 %x to i8
+	%6 = bitcast double %y to i64
+	%7 = freeze double %y
+	ret i32 %x
+}`
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	// Basic block of insertion point not set.
+	if _, err := (&ir.CheckedBuilder{Builder: &ir.Builder{}}).NewAdd(x, x); err == nil {
+		t.Errorf("expected error for unset insertion point")
+	}
+}
@@ -0,0 +1,686 @@
+// === [ Cloning ] =============================================================
+
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Clone returns a deep copy of the module. The global variables, functions,
+// basic blocks, instructions and terminators of the module are copied, and
+// every reference to them (including references within constant expressions)
+// is remapped to their copies. Type definitions and metadata are shared with
+// the original module.
+//
+// The value-mapping hook mapValue, which may be nil, is invoked for each
+// operand value before the default mapping takes place; a non-nil return value
+// is used in place of the mapped value.
+func (m *Module) Clone(mapValue func(v value.Value) value.Value) *Module {
+	c := newCloner(mapValue)
+	nm := &Module{
+		DataLayout:    m.DataLayout,
+		TargetTriple:  m.TargetTriple,
+		Types:         append([]types.Type(nil), m.Types...),
+		NamedMetadata: append([]*metadata.Named(nil), m.NamedMetadata...),
+		Metadata:      append([]*metadata.Metadata(nil), m.Metadata...),
+	}
+	c.entities[m] = nm
+	// Copy global variables and function headers, so that they may be referred
+	// to by the copied global variable initializers and function bodies.
+	for _, global := range m.Globals {
+		newGlobal := *global
+		newGlobal.Metadata = cloneMetadata(global.Metadata)
+		nm.Globals = append(nm.Globals, &newGlobal)
+		c.vmap[global] = &newGlobal
+		c.entities[global] = &newGlobal
+	}
+	for _, f := range m.Funcs {
+		newFunc := c.funcHeader(f)
+		newFunc.Parent = nm
+		nm.Funcs = append(nm.Funcs, newFunc)
+		c.vmap[f] = newFunc
+		c.entities[f] = newFunc
+	}
+	for i, global := range m.Globals {
+		if global.Init != nil {
+			nm.Globals[i].Init = c.constant(global.Init)
+		}
+	}
+	for i, f := range m.Funcs {
+		c.funcBody(f, nm.Funcs[i])
+	}
+	// Remap comments of copied entities.
+	if m.Comments != nil {
+		nm.Comments = make(map[interface{}]*Comments)
+		for k, v := range m.Comments {
+			if newKey, ok := c.entities[k]; ok {
+				k = newKey
+			}
+			comments := *v
+			nm.Comments[k] = &comments
+		}
+	}
+	// Remap source positions of copied entities.
+	if m.Positions != nil {
+		nm.Positions = make(map[interface{}]Position)
+		for k, pos := range m.Positions {
+			if newKey, ok := c.entities[k]; ok {
+				k = newKey
+			}
+			nm.Positions[k] = pos
+		}
+	}
+	return nm
+}
+
+// Clone returns a deep copy of the function. The function parameters, basic
+// blocks, instructions and terminators of the function are copied, and every
+// reference to them is remapped to their copies. References to global
+// variables and functions, including the function itself, are kept. The copy
+// has the same parent module as the function, but is not appended to the
+// functions of the module.
+//
+// The value-mapping hook mapValue, which may be nil, is invoked for each
+// operand value before the default mapping takes place; a non-nil return value
+// is used in place of the mapped value (e.g. to specialize a function
+// parameter by a constant).
+func (f *Function) Clone(mapValue func(v value.Value) value.Value) *Function {
+	c := newCloner(mapValue)
+	newFunc := c.funcHeader(f)
+	c.funcBody(f, newFunc)
+	return newFunc
+}
+
+// cloner keeps track of the mapping from original values to their copies
+// during cloning.
+type cloner struct {
+	// Value-mapping hook; or nil if not present.
+	mapValue func(v value.Value) value.Value
+	// vmap maps from original values to their copies.
+	vmap map[value.Value]value.Value
+	// entities maps from original entities (including terminators, which are
+	// not values) to their copies.
+	entities map[interface{}]interface{}
+}
+
+// newCloner returns a new cloner based on the given value-mapping hook.
+func newCloner(mapValue func(v value.Value) value.Value) *cloner {
+	return &cloner{
+		mapValue: mapValue,
+		vmap:     make(map[value.Value]value.Value),
+		entities: make(map[interface{}]interface{}),
+	}
+}
+
+// funcHeader returns a copy of the given function without basic blocks. The
+// function parameters are copied.
+func (c *cloner) funcHeader(f *Function) *Function {
+	// Materialize lazily parsed function body.
+	f.MustMaterialize()
+	var params []*types.Param
+	for _, param := range f.Sig.Params {
+		newParam := types.NewParam(param.Name, param.Typ)
+		params = append(params, newParam)
+		c.vmap[param] = newParam
+	}
+	sig := types.NewFunc(f.Sig.Ret, params...)
+	sig.Name = f.Sig.Name
+	sig.Variadic = f.Sig.Variadic
+	return &Function{
+		Parent:   f.Parent,
+		Name:     f.Name,
+		Typ:      types.NewPointer(sig),
+		Sig:      sig,
+		CallConv: f.CallConv,
+		Metadata: cloneMetadata(f.Metadata),
+	}
+}
+
+// funcBody copies the basic blocks of f into newFunc.
+func (c *cloner) funcBody(f, newFunc *Function) {
+	if f.Blocks == nil {
+		return
+	}
+	// Copy basic blocks, instructions and terminators, so that they may be
+	// referred to before their definition (e.g. by phi instructions).
+	newFunc.Blocks = make([]*BasicBlock, len(f.Blocks))
+	for i, block := range f.Blocks {
+		newBlock := NewBlock(block.Name)
+		newBlock.Parent = newFunc
+		newFunc.Blocks[i] = newBlock
+		c.vmap[block] = newBlock
+		c.entities[block] = newBlock
+		for _, inst := range block.Insts {
+			newInst := cloneInst(inst)
+			newBlock.AppendInst(newInst)
+			if v, ok := inst.(value.Value); ok {
+				c.vmap[v] = newInst.(value.Value)
+			}
+			c.entities[inst] = newInst
+		}
+		if block.Term != nil {
+			newTerm := cloneInst(block.Term).(Terminator)
+			newBlock.SetTerm(newTerm)
+			c.entities[block.Term] = newTerm
+		}
+	}
+	// Remap operands.
+	for _, block := range newFunc.Blocks {
+		for _, inst := range block.Insts {
+			c.operands(inst)
+		}
+		if block.Term != nil {
+			c.operands(block.Term)
+		}
+	}
+}
+
+// operands remaps the operands of the given copied instruction or terminator.
+func (c *cloner) operands(inst Instruction) {
+	for _, operand := range inst.Operands() {
+		*operand = c.value(*operand)
+	}
+	switch inst := inst.(type) {
+	case *InstPhi:
+		for _, inc := range inst.Incs {
+			inc.Pred = c.block(inc.Pred)
+		}
+	case *TermBr:
+		inst.Target = c.block(inst.Target)
+	case *TermCondBr:
+		inst.TargetTrue = c.block(inst.TargetTrue)
+		inst.TargetFalse = c.block(inst.TargetFalse)
+	case *TermSwitch:
+		inst.TargetDefault = c.block(inst.TargetDefault)
+		for _, oldCase := range inst.Cases {
+			oldCase.Target = c.block(oldCase.Target)
+		}
+	}
+	if term, ok := inst.(Terminator); ok {
+		succs := term.Succs()
+		for i, succ := range succs {
+			succs[i] = c.block(succ)
+		}
+	}
+}
+
+// value returns the mapped value of v.
+func (c *cloner) value(v value.Value) value.Value {
+	if v == nil {
+		return nil
+	}
+	if c.mapValue != nil {
+		if mapped := c.mapValue(v); mapped != nil {
+			return mapped
+		}
+	}
+	if mapped, ok := c.vmap[v]; ok {
+		return mapped
+	}
+	if v, ok := v.(constant.Constant); ok {
+		return c.constant(v)
+	}
+	return v
+}
+
+// block returns the mapped basic block of block.
+func (c *cloner) block(block *BasicBlock) *BasicBlock {
+	if block == nil {
+		return nil
+	}
+	if mapped, ok := c.vmap[block].(*BasicBlock); ok {
+		return mapped
+	}
+	return block
+}
+
+// constant returns the mapped constant of v. Constant expressions and aggregate
+// constants are copied if any of their operands are remapped.
+func (c *cloner) constant(v constant.Constant) constant.Constant {
+	if c.mapValue != nil {
+		if mapped := c.mapValue(v); mapped != nil {
+			return toConstant(mapped)
+		}
+	}
+	if mapped, ok := c.vmap[v]; ok {
+		return toConstant(mapped)
+	}
+	user, ok := v.(interface {
+		Operands() []*constant.Constant
+	})
+	if !ok {
+		return v
+	}
+	var newOperands []constant.Constant
+	changed := false
+	for _, operand := range user.Operands() {
+		newOperand := c.constant(*operand)
+		if newOperand != *operand {
+			changed = true
+		}
+		newOperands = append(newOperands, newOperand)
+	}
+	if !changed {
+		return v
+	}
+	newConst := cloneConstant(v)
+	for i, operand := range newConst.(interface {
+		Operands() []*constant.Constant
+	}).Operands() {
+		*operand = newOperands[i]
+	}
+	c.vmap[v] = newConst
+	return newConst
+}
+
+// ### [ Helper functions ] ####################################################
+
+// toConstant returns the given mapped value as a constant.
+func toConstant(v value.Value) constant.Constant {
+	c, ok := v.(constant.Constant)
+	if !ok {
+		panic(fmt.Errorf("invalid mapped value of constant; expected constant.Constant, got %T", v))
+	}
+	return c
+}
+
+// cloneMetadata returns a copy of the given metadata attachment map. The
+// metadata nodes are shared.
+func cloneMetadata(md map[string]*metadata.Metadata) map[string]*metadata.Metadata {
+	if md == nil {
+		return nil
+	}
+	newMetadata := make(map[string]*metadata.Metadata, len(md))
+	for key, node := range md {
+		newMetadata[key] = node
+	}
+	return newMetadata
+}
+
+// cloneInst returns a shallow copy of the given instruction or terminator, with
+// a parent basic block of nil. Slices of operands, incoming values and switch
+// cases are copied.
+func cloneInst(inst Instruction) Instruction {
+	newInst := shallowCloneInst(inst)
+	newInst.SetParent(nil)
+	return newInst
+}
+
+// shallowCloneInst returns a shallow copy of the given instruction or
+// terminator.
+func shallowCloneInst(inst Instruction) Instruction {
+	switch inst := inst.(type) {
+	case *InstAdd:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFAdd:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSub:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFSub:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstMul:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFMul:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstUDiv:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSDiv:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFDiv:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstURem:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSRem:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFRem:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstShl:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstLShr:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstAShr:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstAnd:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstOr:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstXor:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstExtractElement:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstInsertElement:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstShuffleVector:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstExtractValue:
+		c := *inst
+		c.Indices = append([]int64(nil), inst.Indices...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstInsertValue:
+		c := *inst
+		c.Indices = append([]int64(nil), inst.Indices...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstAlloca:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstLoad:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstStore:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstGetElementPtr:
+		c := *inst
+		c.Indices = append([]value.Value(nil), inst.Indices...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstTrunc:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstZExt:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSExt:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFPTrunc:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFPExt:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFPToUI:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFPToSI:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstUIToFP:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSIToFP:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstPtrToInt:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstIntToPtr:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstBitCast:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstAddrSpaceCast:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstICmp:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFCmp:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstPhi:
+		c := *inst
+		c.Incs = make([]*Incoming, len(inst.Incs))
+		for i, inc := range inst.Incs {
+			c.Incs[i] = NewIncoming(inc.X, inc.Pred)
+		}
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstSelect:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstFreeze:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *InstCall:
+		c := *inst
+		c.Args = append([]value.Value(nil), inst.Args...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *TermRet:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *TermBr:
+		c := *inst
+		c.Successors = append([]*BasicBlock(nil), inst.Successors...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *TermCondBr:
+		c := *inst
+		c.Successors = append([]*BasicBlock(nil), inst.Successors...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *TermSwitch:
+		c := *inst
+		c.Cases = make([]*Case, len(inst.Cases))
+		for i, oldCase := range inst.Cases {
+			newCase := *oldCase
+			newCase.Metadata = cloneMetadata(oldCase.Metadata)
+			c.Cases[i] = &newCase
+		}
+		c.Successors = append([]*BasicBlock(nil), inst.Successors...)
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	case *TermUnreachable:
+		c := *inst
+		c.Metadata = cloneMetadata(inst.Metadata)
+		return &c
+	default:
+		panic(fmt.Errorf("support for instruction %T not yet implemented", inst))
+	}
+}
+
+// cloneConstant returns a shallow copy of the given constant expression or
+// aggregate constant. Slices of operands are copied.
+func cloneConstant(v constant.Constant) constant.Constant {
+	switch v := v.(type) {
+	case *constant.Vector:
+		c := *v
+		c.Elems = append([]constant.Constant(nil), v.Elems...)
+		return &c
+	case *constant.Array:
+		c := *v
+		c.Elems = append([]constant.Constant(nil), v.Elems...)
+		return &c
+	case *constant.Struct:
+		c := *v
+		c.Fields = append([]constant.Constant(nil), v.Fields...)
+		return &c
+	case *constant.ExprAdd:
+		c := *v
+		return &c
+	case *constant.ExprFAdd:
+		c := *v
+		return &c
+	case *constant.ExprSub:
+		c := *v
+		return &c
+	case *constant.ExprFSub:
+		c := *v
+		return &c
+	case *constant.ExprMul:
+		c := *v
+		return &c
+	case *constant.ExprFMul:
+		c := *v
+		return &c
+	case *constant.ExprUDiv:
+		c := *v
+		return &c
+	case *constant.ExprSDiv:
+		c := *v
+		return &c
+	case *constant.ExprFDiv:
+		c := *v
+		return &c
+	case *constant.ExprURem:
+		c := *v
+		return &c
+	case *constant.ExprSRem:
+		c := *v
+		return &c
+	case *constant.ExprFRem:
+		c := *v
+		return &c
+	case *constant.ExprShl:
+		c := *v
+		return &c
+	case *constant.ExprLShr:
+		c := *v
+		return &c
+	case *constant.ExprAShr:
+		c := *v
+		return &c
+	case *constant.ExprAnd:
+		c := *v
+		return &c
+	case *constant.ExprOr:
+		c := *v
+		return &c
+	case *constant.ExprXor:
+		c := *v
+		return &c
+	case *constant.ExprExtractElement:
+		c := *v
+		return &c
+	case *constant.ExprInsertElement:
+		c := *v
+		return &c
+	case *constant.ExprShuffleVector:
+		c := *v
+		return &c
+	case *constant.ExprExtractValue:
+		c := *v
+		c.Indices = append([]int64(nil), v.Indices...)
+		return &c
+	case *constant.ExprInsertValue:
+		c := *v
+		c.Indices = append([]int64(nil), v.Indices...)
+		return &c
+	case *constant.ExprGetElementPtr:
+		c := *v
+		c.Indices = append([]constant.Constant(nil), v.Indices...)
+		return &c
+	case *constant.ExprTrunc:
+		c := *v
+		return &c
+	case *constant.ExprZExt:
+		c := *v
+		return &c
+	case *constant.ExprSExt:
+		c := *v
+		return &c
+	case *constant.ExprFPTrunc:
+		c := *v
+		return &c
+	case *constant.ExprFPExt:
+		c := *v
+		return &c
+	case *constant.ExprFPToUI:
+		c := *v
+		return &c
+	case *constant.ExprFPToSI:
+		c := *v
+		return &c
+	case *constant.ExprUIToFP:
+		c := *v
+		return &c
+	case *constant.ExprSIToFP:
+		c := *v
+		return &c
+	case *constant.ExprPtrToInt:
+		c := *v
+		return &c
+	case *constant.ExprIntToPtr:
+		c := *v
+		return &c
+	case *constant.ExprBitCast:
+		c := *v
+		return &c
+	case *constant.ExprAddrSpaceCast:
+		c := *v
+		return &c
+	case *constant.ExprICmp:
+		c := *v
+		return &c
+	case *constant.ExprFCmp:
+		c := *v
+		return &c
+	case *constant.ExprSelect:
+		c := *v
+		return &c
+	default:
+		panic(fmt.Errorf("support for constant %T not yet implemented", v))
+	}
+}
@@ -0,0 +1,107 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+const cloneSrc = `
+@g = global i32 0
+@p = global i32* getelementptr (i32, i32* @g, i64 1)
+
+define i32 @f(i32 %x, i1 %c) {
+entry:
+	%a = load i32, i32* @g
+	br i1 %c, label %loop, label %exit
+loop:
+	%i = phi i32 [ %x, %entry ], [ %j, %loop ]
+	%j = add i32 %i, %a
+	%k = call i32 @f(i32 %j, i1 false)
+	br i1 %c, label %loop, label %exit
+exit:
+	%r = phi i32 [ %a, %entry ], [ %k, %loop ]
+	ret i32 %r
+}
+`
+
+func TestModuleClone(t *testing.T) {
+	m, err := asm.ParseString(cloneSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := m.String()
+	clone := m.Clone(nil)
+	if got := clone.String(); got != want {
+		t.Errorf("module mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	// Check that references are remapped to copies.
+	g := clone.Globals[0]
+	f := clone.Funcs[0]
+	if g == m.Globals[0] || f == m.Funcs[0] {
+		t.Fatalf("global variables and functions not copied")
+	}
+	if f.Parent != clone {
+		t.Errorf("parent module of function not remapped")
+	}
+	gep := clone.Globals[1].Init.(*constant.ExprGetElementPtr)
+	if gep.Src != g {
+		t.Errorf("global reference within constant expression not remapped")
+	}
+	if src := f.Blocks[0].Insts[0].(*ir.InstLoad).Src; src != g {
+		t.Errorf("global reference within instruction not remapped")
+	}
+	loop := f.Blocks[1]
+	if callee := loop.Insts[2].(*ir.InstCall).Callee; callee != f {
+		t.Errorf("function reference not remapped")
+	}
+	phi := loop.Insts[0].(*ir.InstPhi)
+	if phi.Incs[0].X != f.Params()[0] || phi.Incs[0].Pred != f.Blocks[0] || phi.Incs[1].X != loop.Insts[1].(value.Value) || phi.Incs[1].Pred != loop {
+		t.Errorf("incoming values of phi instruction not remapped")
+	}
+	for _, succ := range loop.Term.Succs() {
+		if succ.Parent != f {
+			t.Errorf("successor basic block %s not remapped", succ.Ident())
+		}
+	}
+	// Check that the original module is unaffected by modifications of the copy.
+	g.Name = "h"
+	f.Blocks[0].Insts[0].(*ir.InstLoad).SetName("b")
+	if got := m.String(); got != want {
+		t.Errorf("original module modified; expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestFunctionClone(t *testing.T) {
+	m, err := asm.ParseString(cloneSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	// Specialize function parameter by a constant.
+	x := f.Params()[0]
+	clone := f.Clone(func(v value.Value) value.Value {
+		if v == x {
+			return constant.NewInt(42, types.I32)
+		}
+		return nil
+	})
+	phi := clone.Blocks[1].Insts[0].(*ir.InstPhi)
+	if got, want := phi.Incs[0].X.Ident(), "42"; got != want {
+		t.Errorf("specialized value mismatch; expected %q, got %q", want, got)
+	}
+	// References to global variables and functions are kept.
+	if src := clone.Blocks[0].Insts[0].(*ir.InstLoad).Src; src != m.Globals[0] {
+		t.Errorf("global reference remapped")
+	}
+	if callee := clone.Blocks[1].Insts[2].(*ir.InstCall).Callee; callee != f {
+		t.Errorf("function reference remapped")
+	}
+	if clone.Parent != m {
+		t.Errorf("parent module mismatch")
+	}
+}
@@ -0,0 +1,54 @@
+// === [ Comments ] ============================================================
+
+package ir
+
+// Comments represents the comments and blank lines attached to an LLVM IR
+// entity, as retained when parsing LLVM IR assembly (see
+// asm.ParseOptions.RetainComments).
+//
+// Comment lines include the leading semicolon (e.g. "; CHECK: ret void"), and
+// blank lines are represented by empty strings.
+type Comments struct {
+	// Comment lines and blank lines preceding the entity. The leading lines of
+	// the module precede the data layout and target triple.
+	Leading []string
+	// Comment on the same line as the end of the entity; or empty if not
+	// present.
+	Trailing string
+	// Comment lines and blank lines at the end of the entity. The footer lines
+	// of a function precede the closing brace of its body, and the footer lines
+	// of the module succeed its last entity.
+	Footer []string
+}
+
+// comments returns the comments attached to the given entity of the module, or
+// nil if not present.
+func (m *Module) comments(v interface{}) *Comments {
+	if m == nil || m.Comments == nil {
+		return nil
+	}
+	return m.Comments[v]
+}
+
+// ### [ Helper functions ] ####################################################
+
+// writeLines writes the given comment lines to w, each prefixed by indent
+// unless blank.
+func writeLines(w *writer, lines []string, indent string) {
+	for _, line := range lines {
+		if len(line) > 0 {
+			w.WriteString(indent)
+			w.WriteString(line)
+		}
+		w.WriteString("\n")
+	}
+}
+
+// writeTrailing writes the trailing comment of the given comments to w, if
+// present.
+func writeTrailing(w *writer, c *Comments) {
+	if c != nil && len(c.Trailing) > 0 {
+		w.WriteString(" ")
+		w.WriteString(c.Trailing)
+	}
+}
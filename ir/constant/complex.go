@@ -32,6 +32,20 @@ func NewVector(elems ...Constant) *Vector {
 	return &Vector{Typ: typ, Elems: elems}
 }
 
+// NewSplat returns a new vector constant of n elements, each of which is the
+// given element.
+func NewSplat(elem Constant, n int64) *Vector {
+	if n < 1 {
+		panic(fmt.Errorf("invalid number of vector elements; expected > 0, got %d", n))
+	}
+	elems := make([]Constant, n)
+	for i := range elems {
+		elems[i] = elem
+	}
+	typ := types.NewVector(elem.Type(), n)
+	return &Vector{Typ: typ, Elems: elems}
+}
+
 // Type returns the type of the constant.
 func (c *Vector) Type() types.Type {
 	return c.Typ
@@ -61,6 +75,15 @@ func (*Vector) Immutable() {}
 // ir.MetadataNode interface.
 func (*Vector) MetadataNode() {}
 
+// Operands returns a mutable list of the elements of the vector.
+func (c *Vector) Operands() []*Constant {
+	var operands []*Constant
+	for i := range c.Elems {
+		operands = append(operands, &c.Elems[i])
+	}
+	return operands
+}
+
 // --- [ array ] ---------------------------------------------------------------
 
 // Array represents an array constant.
@@ -69,8 +92,6 @@ type Array struct {
 	Typ *types.ArrayType
 	// Array elements.
 	Elems []Constant
-	// Pretty-print as character array.
-	CharArray bool
 }
 
 // NewArray returns a new array constant based on the given elements.
@@ -89,18 +110,9 @@ func (c *Array) Type() types.Type {
 
 // Ident returns the string representation of the constant.
 func (c *Array) Ident() string {
-	// Pretty-print character arrays.
-	if c.CharArray {
-		buf := make([]byte, 0, len(c.Elems))
-		for _, elem := range c.Elems {
-			e, ok := elem.(*Int)
-			if !ok {
-				panic(fmt.Errorf("invalid array element type; expected *constant.Int, got %T", elem))
-			}
-			b := byte(e.Int64())
-			buf = append(buf, b)
-		}
-		return fmt.Sprintf(`c"%s"`, enc.EscapeString(string(buf)))
+	// Print arrays of i8 integer constants as character arrays.
+	if s, ok := c.chars(); ok {
+		return charArrayIdent(s)
 	}
 	// Print regular arrays.
 	buf := &bytes.Buffer{}
@@ -125,6 +137,74 @@ func (*Array) Immutable() {}
 // ir.MetadataNode interface.
 func (*Array) MetadataNode() {}
 
+// Operands returns a mutable list of the elements of the array.
+func (c *Array) Operands() []*Constant {
+	var operands []*Constant
+	for i := range c.Elems {
+		operands = append(operands, &c.Elems[i])
+	}
+	return operands
+}
+
+// chars returns the characters of the array constant. The boolean return value
+// indicates whether the array is a non-empty array of i8 integer constants.
+func (c *Array) chars() ([]byte, bool) {
+	if len(c.Elems) == 0 || !c.Typ.Elem.Equal(types.I8) {
+		return nil, false
+	}
+	buf := make([]byte, len(c.Elems))
+	for i, elem := range c.Elems {
+		e, ok := elem.(*Int)
+		if !ok {
+			return nil, false
+		}
+		buf[i] = byte(e.X.Int64())
+	}
+	return buf, true
+}
+
+// --- [ character array ] -----------------------------------------------------
+
+// CharArray represents a character array constant; i.e. an array of i8
+// integers, such as `c"hello\00"`.
+type CharArray struct {
+	// Array type.
+	Typ *types.ArrayType
+	// Array characters.
+	X []byte
+}
+
+// NewCharArray returns a new character array constant based on the given
+// characters.
+func NewCharArray(x []byte) *CharArray {
+	typ := types.NewArray(types.I8, int64(len(x)))
+	return &CharArray{Typ: typ, X: x}
+}
+
+// NewCharArrayFromString returns a new character array constant based on the
+// given string. No NULL terminator is appended.
+func NewCharArrayFromString(s string) *CharArray {
+	return NewCharArray([]byte(s))
+}
+
+// Type returns the type of the constant.
+func (c *CharArray) Type() types.Type {
+	return c.Typ
+}
+
+// Ident returns the string representation of the constant.
+func (c *CharArray) Ident() string {
+	return charArrayIdent(c.X)
+}
+
+// Immutable ensures that only constants can be assigned to the
+// constant.Constant interface.
+func (*CharArray) Immutable() {}
+
+// MetadataNode ensures that only metadata nodes can be assigned to the
+// ir.MetadataNode interface.
+func (*CharArray) MetadataNode() {}
+
 // --- [ struct ] --------------------------------------------------------------
 
 // Struct represents a struct constant.
@@ -182,6 +262,15 @@ func (*Struct) Immutable() {}
 // ir.MetadataNode interface.
 func (*Struct) MetadataNode() {}
 
+// Operands returns a mutable list of the fields of the struct.
+func (c *Struct) Operands() []*Constant {
+	var operands []*Constant
+	for i := range c.Fields {
+		operands = append(operands, &c.Fields[i])
+	}
+	return operands
+}
+
 // --- [ zeroinitializer ] -----------------------------------------------------
 
 // ZeroInitializer represents a zeroinitializer constant.
@@ -213,3 +302,11 @@ func (*ZeroInitializer) Immutable() {}
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ZeroInitializer) MetadataNode() {}
+
+// ### [ Helper functions ] ####################################################
+
+// charArrayIdent returns the string representation of a character array
+// constant with the given characters.
+func charArrayIdent(x []byte) string {
+	return fmt.Sprintf(`c"%s"`, enc.EscapeString(string(x)))
+}
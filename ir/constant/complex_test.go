@@ -0,0 +1,33 @@
+package constant_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestCharArrayIdent(t *testing.T) {
+	i8 := func(x int64) constant.Constant { return constant.NewInt(x, types.I8) }
+	golden := []struct {
+		in   constant.Constant
+		want string
+	}{
+		{in: constant.NewCharArrayFromString("hello\x00"), want: `c"hello\00"`},
+		{in: constant.NewCharArrayFromString(`"\`), want: `c"\22\5C"`},
+		{in: constant.NewCharArray(nil), want: `c""`},
+		// Arrays of i8 integer constants are printed as character arrays.
+		{in: constant.NewArray(i8('h'), i8('i'), i8(-1)), want: `c"hi\FF"`},
+		{in: constant.NewArray(i8(0), constant.NewUndef(types.I8)), want: "[i8 0, i8 undef]"},
+		{in: &constant.Array{Typ: types.NewArray(types.I8, 0)}, want: "[]"},
+	}
+	for _, g := range golden {
+		if got := g.in.Ident(); got != g.want {
+			t.Errorf("string representation mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+	c := constant.NewCharArrayFromString("hello")
+	if got, want := c.Type().String(), "[5 x i8]"; got != want {
+		t.Errorf("type mismatch; expected %q, got %q", want, got)
+	}
+}
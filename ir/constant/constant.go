@@ -30,6 +30,7 @@ import (
 //
 //    *constant.Vector            (https://godoc.org/github.com/llir/llvm/ir/constant#Vector)
 //    *constant.Array             (https://godoc.org/github.com/llir/llvm/ir/constant#Array)
+//    *constant.CharArray         (https://godoc.org/github.com/llir/llvm/ir/constant#CharArray)
 //    *constant.Struct            (https://godoc.org/github.com/llir/llvm/ir/constant#Struct)
 //    *constant.ZeroInitializer   (https://godoc.org/github.com/llir/llvm/ir/constant#ZeroInitializer)
 //
@@ -40,7 +41,8 @@ import (
 //
 // Undefined value constants
 //
-//    *constant.Undef   (https://godoc.org/github.com/llir/llvm/ir/constant#Undef)
+//    *constant.Undef    (https://godoc.org/github.com/llir/llvm/ir/constant#Undef)
+//    *constant.Poison   (https://godoc.org/github.com/llir/llvm/ir/constant#Poison)
 //
 // Constant expressions
 //
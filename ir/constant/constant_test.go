@@ -14,9 +14,11 @@ var (
 	// Complex constants.
 	_ constant.Constant = &constant.Vector{}
 	_ constant.Constant = &constant.Array{}
+	_ constant.Constant = &constant.CharArray{}
 	_ constant.Constant = &constant.Struct{}
 	_ constant.Constant = &constant.ZeroInitializer{}
 	_ constant.Constant = &constant.Undef{}
+	_ constant.Constant = &constant.Poison{}
 )
 
 // Validate that the relevant types satisfy the constant.Expr interface.
@@ -79,9 +81,11 @@ var (
 	// Complex constants.
 	_ metadata.Node = &constant.Vector{}
 	_ metadata.Node = &constant.Array{}
+	_ metadata.Node = &constant.CharArray{}
 	_ metadata.Node = &constant.Struct{}
 	_ metadata.Node = &constant.ZeroInitializer{}
 	_ metadata.Node = &constant.Undef{}
+	_ metadata.Node = &constant.Poison{}
 	// Binary expressions.
 	_ metadata.Node = &constant.ExprAdd{}
 	_ metadata.Node = &constant.ExprFAdd{}
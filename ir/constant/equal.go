@@ -0,0 +1,186 @@
+// === [ Constant equality ] ===================================================
+
+package constant
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/llir/llvm/ir/types"
+)
+
+// Equal reports whether the given constants are structurally equal; i.e.
+// whether they are of the same kind and type, and have equal values or
+// operands. Constant expressions are compared without folding, and the
+// addresses of global variables and functions are only equal to themselves.
+//
+// Floating-point constants are equal if they have the same binary
+// representation; thus 0.0 and -0.0 are distinct, and NaN values are equal if
+// their payloads are equal.
+func Equal(x, y Constant) bool {
+	if x == y {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	if reflect.TypeOf(x) != reflect.TypeOf(y) || !x.Type().Equal(y.Type()) {
+		return false
+	}
+	switch x := x.(type) {
+	case *Int:
+		return x.Unsigned().Cmp(y.(*Int).Unsigned()) == 0
+	case *Float:
+		return x.bits().Cmp(y.(*Float).bits()) == 0
+	case *Null, *ZeroInitializer, *Undef, *Poison:
+		return true
+	case *CharArray:
+		return bytes.Equal(x.X, y.(*CharArray).X)
+	case *ExprICmp:
+		if x.Pred != y.(*ExprICmp).Pred {
+			return false
+		}
+	case *ExprFCmp:
+		if x.Pred != y.(*ExprFCmp).Pred {
+			return false
+		}
+	case *ExprExtractValue:
+		if !equalIndices(x.Indices, y.(*ExprExtractValue).Indices) {
+			return false
+		}
+	case *ExprInsertValue:
+		if !equalIndices(x.Indices, y.(*ExprInsertValue).Indices) {
+			return false
+		}
+	case *ExprGetElementPtr:
+		if !x.Elem.Equal(y.(*ExprGetElementPtr).Elem) {
+			return false
+		}
+	case flagser:
+		// Overflow and exact flags.
+		if x.flags() != y.(flagser).flags() {
+			return false
+		}
+	}
+	// Compare the elements of complex constants and the operands of constant
+	// expressions.
+	xs, ok := x.(operandser)
+	if !ok {
+		// Global variables and functions.
+		return false
+	}
+	xops, yops := xs.Operands(), y.(operandser).Operands()
+	if len(xops) != len(yops) {
+		return false
+	}
+	for i := range xops {
+		if !Equal(*xops[i], *yops[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// operandser is the interface implemented by complex constants and constant
+// expressions, which provide a mutable list of their elements or operands.
+type operandser interface {
+	// Operands returns a mutable list of the operands of the constant.
+	Operands() []*Constant
+}
+
+// flagser is the interface implemented by constant expressions with overflow
+// or exact flags.
+type flagser interface {
+	// flags returns the string representation of the flags of the constant
+	// expression.
+	flags() string
+}
+
+// --- [ Constant pool ] -------------------------------------------------------
+
+// Pool is a pool of constants, which maps structurally equal constants (as
+// reported by Equal) to a single canonical instance. Canonical instances may be
+// compared by identity, and used as map keys.
+//
+// Canonical instances must not be modified while held by the pool.
+type Pool struct {
+	// Canonical constants, indexed by pool key (see poolKey).
+	consts map[string][]Constant
+	// Number of canonical constants.
+	n int
+}
+
+// NewPool returns a new empty constant pool.
+func NewPool() *Pool {
+	return &Pool{consts: make(map[string][]Constant)}
+}
+
+// Get returns the canonical instance of the given constant. The given constant
+// becomes the canonical instance if the pool holds no constant equal to it.
+func (p *Pool) Get(c Constant) Constant {
+	key := poolKey(c)
+	for _, canonical := range p.consts[key] {
+		if Equal(c, canonical) {
+			return canonical
+		}
+	}
+	p.consts[key] = append(p.consts[key], c)
+	p.n++
+	return c
+}
+
+// Int returns the canonical integer constant based on the given integer value
+// and type.
+func (p *Pool) Int(x int64, typ types.Type) *Int {
+	return p.Get(NewInt(x, typ)).(*Int)
+}
+
+// Len returns the number of canonical constants held by the pool.
+func (p *Pool) Len() int {
+	return p.n
+}
+
+// ### [ Helper functions ] ####################################################
+
+// poolKey returns the key of the given constant in a constant pool. Equal
+// constants (as reported by Equal) have equal keys; e.g. integer constants are
+// keyed by their unsigned interpretation, as i8 255 and i8 -1 are equal.
+func poolKey(c Constant) string {
+	buf := &bytes.Buffer{}
+	writePoolKey(buf, c)
+	return buf.String()
+}
+
+// writePoolKey writes the pool key of the given constant to buf.
+func writePoolKey(buf *bytes.Buffer, c Constant) {
+	fmt.Fprintf(buf, "%T %s ", c, c.Type())
+	switch c := c.(type) {
+	case *Int:
+		buf.WriteString(c.Unsigned().String())
+	case *Float:
+		buf.WriteString(c.bits().String())
+	case operandser:
+		buf.WriteString("(")
+		for _, operand := range c.Operands() {
+			writePoolKey(buf, *operand)
+			buf.WriteString(", ")
+		}
+		buf.WriteString(")")
+	default:
+		buf.WriteString(c.Ident())
+	}
+}
+
+// equalIndices reports whether the given lists of aggregate indices are equal.
+func equalIndices(xs, ys []int64) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if xs[i] != ys[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package constant_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestEqual(t *testing.T) {
+	i32 := func(x int64) *constant.Int { return constant.NewInt(x, types.I32) }
+	f64 := func(x float64) *constant.Float { return constant.NewFloat(x, types.Double) }
+	x := ir.NewGlobalDef("x", i32(1))
+	y := ir.NewGlobalDef("x", i32(1))
+	golden := []struct {
+		a, b constant.Constant
+		want bool
+	}{
+		// Simple constants.
+		{a: i32(1), b: i32(1), want: true},
+		{a: i32(1), b: i32(2), want: false},
+		{a: i32(1), b: constant.NewInt(1, types.I64), want: false},
+		{a: constant.NewInt(-1, types.I8), b: constant.NewInt(255, types.I8), want: true},
+		{a: f64(0.5), b: f64(0.5), want: true},
+		{a: f64(0), b: f64(math.Copysign(0, -1)), want: false},
+		{a: f64(math.NaN()), b: f64(math.NaN()), want: true},
+		{a: constant.NewNull(types.NewPointer(types.I8)), b: constant.NewNull(types.NewPointer(types.I8)), want: true},
+		{a: constant.NewNull(types.NewPointer(types.I8)), b: constant.NewNull(types.NewPointer(types.I32)), want: false},
+		{a: constant.NewUndef(types.I32), b: constant.NewUndef(types.I32), want: true},
+		{a: constant.NewUndef(types.I32), b: constant.NewZeroInitializer(types.I32), want: false},
+		{a: constant.NewPoison(types.I32), b: constant.NewPoison(types.I32), want: true},
+		{a: constant.NewPoison(types.I32), b: constant.NewUndef(types.I32), want: false},
+		// Complex constants.
+		{a: constant.NewVector(i32(1), i32(2)), b: constant.NewVector(i32(1), i32(2)), want: true},
+		{a: constant.NewVector(i32(1), i32(2)), b: constant.NewVector(i32(1), i32(3)), want: false},
+		{a: constant.NewArray(i32(1), i32(2)), b: constant.NewVector(i32(1), i32(2)), want: false},
+		{a: constant.NewStruct(i32(1), f64(2)), b: constant.NewStruct(i32(1), f64(2)), want: true},
+		{a: constant.NewCharArrayFromString("foo"), b: constant.NewCharArrayFromString("foo"), want: true},
+		{a: constant.NewCharArrayFromString("foo"), b: constant.NewCharArrayFromString("bar"), want: false},
+		// Global variables.
+		{a: x, b: x, want: true},
+		{a: x, b: y, want: false},
+		// Constant expressions.
+		{a: constant.NewAdd(i32(1), i32(2)), b: constant.NewAdd(i32(1), i32(2)), want: true},
+		{a: constant.NewAdd(i32(1), i32(2)), b: constant.NewSub(i32(1), i32(2)), want: false},
+		{a: constant.NewAdd(i32(1), i32(2)), b: i32(3), want: false},
+		{a: constant.NewICmp(constant.IntEQ, i32(1), i32(2)), b: constant.NewICmp(constant.IntNE, i32(1), i32(2)), want: false},
+		{a: constant.NewZExt(i32(1), types.I64), b: constant.NewSExt(i32(1), types.I64), want: false},
+		{a: constant.NewPtrToInt(x, types.I64), b: constant.NewPtrToInt(x, types.I64), want: true},
+		{a: constant.NewPtrToInt(x, types.I64), b: constant.NewPtrToInt(y, types.I64), want: false},
+		{a: constant.NewExtractValue(constant.NewStruct(i32(1), i32(2)), []int64{0}), b: constant.NewExtractValue(constant.NewStruct(i32(1), i32(2)), []int64{1}), want: false},
+	}
+	for _, g := range golden {
+		if got := constant.Equal(g.a, g.b); got != g.want {
+			t.Errorf("equality of %v %v and %v %v mismatch; expected %v, got %v", g.a.Type(), g.a.Ident(), g.b.Type(), g.b.Ident(), g.want, got)
+		}
+		if got := constant.Equal(g.b, g.a); got != g.want {
+			t.Errorf("equality of %v %v and %v %v mismatch; expected %v, got %v", g.b.Type(), g.b.Ident(), g.a.Type(), g.a.Ident(), g.want, got)
+		}
+	}
+}
+
+func TestPool(t *testing.T) {
+	m := ir.NewModule()
+	pool := m.ConstantPool()
+	if pool != m.ConstantPool() {
+		t.Errorf("expected the same constant pool on each call")
+	}
+	a := pool.Int(0, types.I32)
+	if b := pool.Int(0, types.I32); a != b {
+		t.Errorf("expected canonical instance of %v", a.Ident())
+	}
+	if b := pool.Int(0, types.I64); constant.Constant(a) == constant.Constant(b) {
+		t.Errorf("expected distinct instances of i32 0 and i64 0")
+	}
+	vec := constant.NewVector(a, a)
+	if got := pool.Get(vec); got != vec {
+		t.Errorf("expected new constant to become canonical")
+	}
+	if got := pool.Get(constant.NewVector(constant.NewInt(0, types.I32), constant.NewInt(0, types.I32))); got != vec {
+		t.Errorf("expected canonical instance of %v", vec.Ident())
+	}
+	x := m.NewGlobalDef("x", a)
+	y := ir.NewGlobalDef("x", a)
+	if pool.Get(x) != x || pool.Get(y) != y {
+		t.Errorf("expected distinct globals to be distinct canonical instances")
+	}
+	// Integer constants are canonicalized by value, irrespective of sign.
+	if a, b := pool.Int(255, types.I8), pool.Int(-1, types.I8); a != b {
+		t.Errorf("expected canonical instance of i8 255 and i8 -1")
+	}
+	if a, b := pool.Int(1, types.I1), pool.Int(-1, types.I1); a != b {
+		t.Errorf("expected canonical instance of i1 1 and i1 -1")
+	}
+	v1 := pool.Get(constant.NewVector(constant.NewInt(255, types.I8)))
+	if v2 := pool.Get(constant.NewVector(constant.NewInt(-1, types.I8))); v1 != v2 {
+		t.Errorf("expected canonical instance of <i8 255> and <i8 -1>")
+	}
+	if got, want := pool.Len(), 8; got != want {
+		t.Errorf("number of canonical constants mismatch; expected %d, got %d", want, got)
+	}
+}
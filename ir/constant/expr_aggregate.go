@@ -65,13 +65,18 @@ func (*ExprExtractValue) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprExtractValue) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprExtractValue) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprExtractValue) Operands() []*Constant {
+	return []*Constant{&expr.X}
+}
+
 // --- [ insertvalue ] ---------------------------------------------------------
 
 // ExprInsertValue represents an insertvalue expression.
@@ -122,13 +127,18 @@ func (*ExprInsertValue) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprInsertValue) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprInsertValue) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprInsertValue) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Elem}
+}
+
 // ### [ Helper functions ] ####################################################
 
 // aggregateElemType returns the element type of the given aggregate type, based
@@ -22,6 +22,10 @@ import (
 type ExprAdd struct {
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 }
 
 // NewAdd returns a new add expression based on the given operands.
@@ -39,26 +43,38 @@ func (expr *ExprAdd) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprAdd) Ident() string {
-	return fmt.Sprintf("add (%s %s, %s %s)",
+	return fmt.Sprintf("add%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the overflow flags of the
+// constant expression, with a leading space if non-empty.
+func (expr *ExprAdd) flags() string {
+	return overflowFlags(expr.NUW, expr.NSW)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprAdd) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprAdd) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprAdd) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprAdd) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ fadd ] ----------------------------------------------------------------
 
 // ExprFAdd represents a floating-point addition expression.
@@ -98,13 +114,18 @@ func (*ExprFAdd) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFAdd) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFAdd) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFAdd) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ sub ] -----------------------------------------------------------------
 
 // ExprSub represents a subtraction expression.
@@ -114,6 +135,10 @@ func (*ExprFAdd) MetadataNode() {}
 type ExprSub struct {
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 }
 
 // NewSub returns a new sub expression based on the given operands.
@@ -131,26 +156,38 @@ func (expr *ExprSub) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprSub) Ident() string {
-	return fmt.Sprintf("sub (%s %s, %s %s)",
+	return fmt.Sprintf("sub%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the overflow flags of the
+// constant expression, with a leading space if non-empty.
+func (expr *ExprSub) flags() string {
+	return overflowFlags(expr.NUW, expr.NSW)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprSub) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSub) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSub) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSub) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ fsub ] ----------------------------------------------------------------
 
 // ExprFSub represents a floating-point subtraction expression.
@@ -190,13 +227,18 @@ func (*ExprFSub) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFSub) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFSub) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFSub) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ mul ] -----------------------------------------------------------------
 
 // ExprMul represents a multiplication expression.
@@ -206,6 +248,10 @@ func (*ExprFSub) MetadataNode() {}
 type ExprMul struct {
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 }
 
 // NewMul returns a new mul expression based on the given operands.
@@ -223,26 +269,38 @@ func (expr *ExprMul) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprMul) Ident() string {
-	return fmt.Sprintf("mul (%s %s, %s %s)",
+	return fmt.Sprintf("mul%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the overflow flags of the
+// constant expression, with a leading space if non-empty.
+func (expr *ExprMul) flags() string {
+	return overflowFlags(expr.NUW, expr.NSW)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprMul) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprMul) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprMul) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprMul) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ fmul ] ----------------------------------------------------------------
 
 // ExprFMul represents a floating-point multiplication expression.
@@ -282,13 +340,18 @@ func (*ExprFMul) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFMul) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFMul) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFMul) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ udiv ] ----------------------------------------------------------------
 
 // ExprUDiv represents an unsigned division expression.
@@ -298,6 +361,8 @@ func (*ExprFMul) MetadataNode() {}
 type ExprUDiv struct {
 	// Operands.
 	X, Y Constant
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 }
 
 // NewUDiv returns a new udiv expression based on the given operands.
@@ -315,26 +380,38 @@ func (expr *ExprUDiv) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprUDiv) Ident() string {
-	return fmt.Sprintf("udiv (%s %s, %s %s)",
+	return fmt.Sprintf("udiv%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the exact flag of the constant
+// expression, with a leading space if non-empty.
+func (expr *ExprUDiv) flags() string {
+	return exactFlag(expr.Exact)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprUDiv) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprUDiv) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprUDiv) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprUDiv) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ sdiv ] ----------------------------------------------------------------
 
 // ExprSDiv represents a signed division expression.
@@ -344,6 +421,8 @@ func (*ExprUDiv) MetadataNode() {}
 type ExprSDiv struct {
 	// Operands.
 	X, Y Constant
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 }
 
 // NewSDiv returns a new sdiv expression based on the given operands.
@@ -361,26 +440,38 @@ func (expr *ExprSDiv) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprSDiv) Ident() string {
-	return fmt.Sprintf("sdiv (%s %s, %s %s)",
+	return fmt.Sprintf("sdiv%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the exact flag of the constant
+// expression, with a leading space if non-empty.
+func (expr *ExprSDiv) flags() string {
+	return exactFlag(expr.Exact)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprSDiv) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSDiv) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSDiv) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSDiv) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ fdiv ] ----------------------------------------------------------------
 
 // ExprFDiv represents a floating-point division expression.
@@ -420,13 +511,18 @@ func (*ExprFDiv) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFDiv) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFDiv) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFDiv) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ urem ] ----------------------------------------------------------------
 
 // ExprURem represents an unsigned remainder expression.
@@ -466,13 +562,18 @@ func (*ExprURem) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprURem) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprURem) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprURem) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ srem ] ----------------------------------------------------------------
 
 // ExprSRem represents a signed remainder expression.
@@ -512,13 +613,18 @@ func (*ExprSRem) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSRem) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSRem) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSRem) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ frem ] ----------------------------------------------------------------
 
 // ExprFRem represents a floating-point remainder expression.
@@ -558,9 +664,14 @@ func (*ExprFRem) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFRem) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFRem) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFRem) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
@@ -22,6 +22,10 @@ import (
 type ExprShl struct {
 	// Operands.
 	X, Y Constant
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 }
 
 // NewShl returns a new shl expression based on the given operands.
@@ -39,26 +43,38 @@ func (expr *ExprShl) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprShl) Ident() string {
-	return fmt.Sprintf("shl (%s %s, %s %s)",
+	return fmt.Sprintf("shl%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the overflow flags of the
+// constant expression, with a leading space if non-empty.
+func (expr *ExprShl) flags() string {
+	return overflowFlags(expr.NUW, expr.NSW)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprShl) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprShl) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprShl) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprShl) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ lshr ] ----------------------------------------------------------------
 
 // ExprLShr represents a logical shift right expression.
@@ -68,6 +84,8 @@ func (*ExprShl) MetadataNode() {}
 type ExprLShr struct {
 	// Operands.
 	X, Y Constant
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 }
 
 // NewLShr returns a new lshr expression based on the given operands.
@@ -85,26 +103,38 @@ func (expr *ExprLShr) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprLShr) Ident() string {
-	return fmt.Sprintf("lshr (%s %s, %s %s)",
+	return fmt.Sprintf("lshr%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the exact flag of the constant
+// expression, with a leading space if non-empty.
+func (expr *ExprLShr) flags() string {
+	return exactFlag(expr.Exact)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprLShr) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprLShr) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprLShr) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprLShr) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ ashr ] ----------------------------------------------------------------
 
 // ExprAShr represents an arithmetic shift right expression.
@@ -114,6 +144,8 @@ func (*ExprLShr) MetadataNode() {}
 type ExprAShr struct {
 	// Operands.
 	X, Y Constant
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 }
 
 // NewAShr returns a new ashr expression based on the given operands.
@@ -131,26 +163,38 @@ func (expr *ExprAShr) Type() types.Type {
 
 // Ident returns the string representation of the constant expression.
 func (expr *ExprAShr) Ident() string {
-	return fmt.Sprintf("ashr (%s %s, %s %s)",
+	return fmt.Sprintf("ashr%s (%s %s, %s %s)",
+		expr.flags(),
 		expr.X.Type(),
 		expr.X.Ident(),
 		expr.Y.Type(),
 		expr.Y.Ident())
 }
 
+// flags returns the string representation of the exact flag of the constant
+// expression, with a leading space if non-empty.
+func (expr *ExprAShr) flags() string {
+	return exactFlag(expr.Exact)
+}
+
 // Immutable ensures that only constants can be assigned to the
 // constant.Constant interface.
 func (*ExprAShr) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprAShr) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprAShr) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprAShr) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ and ] -----------------------------------------------------------------
 
 // ExprAnd represents an AND expression.
@@ -190,13 +234,18 @@ func (*ExprAnd) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprAnd) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprAnd) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprAnd) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ or ] ------------------------------------------------------------------
 
 // ExprOr represents an OR expression.
@@ -236,13 +285,18 @@ func (*ExprOr) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprOr) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprOr) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprOr) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // --- [ xor ] -----------------------------------------------------------------
 
 // ExprXor represents an exclusive-OR expression.
@@ -282,9 +336,14 @@ func (*ExprXor) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprXor) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprXor) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprXor) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
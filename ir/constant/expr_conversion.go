@@ -53,13 +53,18 @@ func (*ExprTrunc) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprTrunc) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprTrunc) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprTrunc) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ zext ] ----------------------------------------------------------------
 
 // ExprZExt represents a zero extension expression.
@@ -100,13 +105,18 @@ func (*ExprZExt) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprZExt) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprZExt) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprZExt) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ sext ] ----------------------------------------------------------------
 
 // ExprSExt represents a sign extension expression.
@@ -147,13 +157,18 @@ func (*ExprSExt) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSExt) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSExt) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSExt) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ fptrunc ] -------------------------------------------------------------
 
 // ExprFPTrunc represents a floating-point truncation expression.
@@ -194,13 +209,18 @@ func (*ExprFPTrunc) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFPTrunc) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFPTrunc) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFPTrunc) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ fpext ] ---------------------------------------------------------------
 
 // ExprFPExt represents a floating-point extension expression.
@@ -241,13 +261,18 @@ func (*ExprFPExt) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFPExt) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFPExt) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFPExt) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ fptoui ] --------------------------------------------------------------
 
 // ExprFPToUI represents a floating-point to unsigned integer conversion expression.
@@ -288,13 +313,18 @@ func (*ExprFPToUI) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFPToUI) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFPToUI) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFPToUI) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ fptosi ] --------------------------------------------------------------
 
 // ExprFPToSI represents a floating-point to signed integer conversion expression.
@@ -335,13 +365,18 @@ func (*ExprFPToSI) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFPToSI) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFPToSI) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFPToSI) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ uitofp ] --------------------------------------------------------------
 
 // ExprUIToFP represents an unsigned integer to floating-point conversion expression.
@@ -382,13 +417,18 @@ func (*ExprUIToFP) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprUIToFP) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprUIToFP) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprUIToFP) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ sitofp ] --------------------------------------------------------------
 
 // ExprSIToFP represents a signed integer to floating-point conversion expression.
@@ -429,13 +469,18 @@ func (*ExprSIToFP) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSIToFP) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSIToFP) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSIToFP) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ ptrtoint ] ------------------------------------------------------------
 
 // ExprPtrToInt represents a pointer to integer conversion expression.
@@ -476,13 +521,18 @@ func (*ExprPtrToInt) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprPtrToInt) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprPtrToInt) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprPtrToInt) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ inttoptr ] ------------------------------------------------------------
 
 // ExprIntToPtr represents an integer to pointer conversion expression.
@@ -523,13 +573,18 @@ func (*ExprIntToPtr) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprIntToPtr) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprIntToPtr) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprIntToPtr) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ bitcast ] -------------------------------------------------------------
 
 // ExprBitCast represents a bitcast expression.
@@ -570,13 +625,18 @@ func (*ExprBitCast) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprBitCast) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprBitCast) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprBitCast) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
+
 // --- [ addrspacecast ] -------------------------------------------------------
 
 // ExprAddrSpaceCast represents an address space cast expression.
@@ -617,9 +677,14 @@ func (*ExprAddrSpaceCast) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprAddrSpaceCast) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprAddrSpaceCast) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprAddrSpaceCast) Operands() []*Constant {
+	return []*Constant{&expr.From}
+}
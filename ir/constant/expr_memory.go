@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/llir/llvm/ir/types"
+	"github.com/pkg/errors"
 )
 
 // --- [ getelementptr ] -------------------------------------------------------
@@ -30,11 +31,25 @@ type ExprGetElementPtr struct {
 }
 
 // NewGetElementPtr returns a new getelementptr expression based on the given
-// source address and element indices.
+// source address and element indices. It panics if the source address is not
+// of pointer type, or if the element indices are invalid for the indexed types
+// (see NewGetElementPtrChecked).
 func NewGetElementPtr(src Constant, indices ...Constant) *ExprGetElementPtr {
+	expr, err := NewGetElementPtrChecked(src, indices...)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// NewGetElementPtrChecked returns a new getelementptr expression based on the
+// given source address and element indices. An error is returned if the source
+// address is not of pointer type, or if the element indices are invalid for
+// the indexed types.
+func NewGetElementPtrChecked(src Constant, indices ...Constant) (*ExprGetElementPtr, error) {
 	srcType, ok := src.Type().(*types.PointerType)
 	if !ok {
-		panic(fmt.Errorf("invalid source address type; expected *types.PointerType, got %T", src.Type()))
+		return nil, errors.Errorf("invalid source address type; expected *types.PointerType, got %T", src.Type())
 	}
 	elem := srcType.Elem
 	e := elem
@@ -49,26 +64,32 @@ func NewGetElementPtr(src Constant, indices ...Constant) *ExprGetElementPtr {
 		switch t := e.(type) {
 		case *types.PointerType:
 			// ref: http://llvm.org/docs/GetElementPtr.html#what-is-dereferenced-by-gep
-			panic("unable to index into element of pointer type; for more information, see http://llvm.org/docs/GetElementPtr.html#what-is-dereferenced-by-gep")
+			return nil, errors.Errorf("unable to index into element of pointer type %v at index %d; for more information, see http://llvm.org/docs/GetElementPtr.html#what-is-dereferenced-by-gep", t, i)
 		case *types.ArrayType:
 			e = t.Elem
+		case *types.VectorType:
+			e = t.Elem
 		case *types.StructType:
 			idx, ok := index.(*Int)
 			if !ok {
-				panic(fmt.Errorf("invalid index type for structure element; expected *constant.Int, got %T", index))
+				return nil, errors.Errorf("invalid index type for structure element at index %d; expected *constant.Int, got %T", i, index)
+			}
+			if !idx.X.IsInt64() || idx.Int64() < 0 || idx.Int64() >= int64(len(t.Fields)) {
+				return nil, errors.Errorf("invalid structure element index %v at index %d; expected index in range [0, %d) of struct type %v", idx.X, i, len(t.Fields), t)
 			}
 			e = t.Fields[idx.Int64()]
 		default:
-			panic(fmt.Errorf("support for indexing element type %T not yet implemented", e))
+			return nil, errors.Errorf("unable to index into element of type %v at index %d; expected array, vector or struct type", e, i)
 		}
 	}
 	typ := types.NewPointer(e)
-	return &ExprGetElementPtr{
+	expr := &ExprGetElementPtr{
 		Typ:     typ,
 		Elem:    elem,
 		Src:     src,
 		Indices: indices,
 	}
+	return expr, nil
 }
 
 // Type returns the type of the constant expression.
@@ -98,9 +119,18 @@ func (*ExprGetElementPtr) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprGetElementPtr) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprGetElementPtr) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprGetElementPtr) Operands() []*Constant {
+	operands := []*Constant{&expr.Src}
+	for i := range expr.Indices {
+		operands = append(operands, &expr.Indices[i])
+	}
+	return operands
+}
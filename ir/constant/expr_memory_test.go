@@ -0,0 +1,44 @@
+package constant_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestNewGetElementPtrChecked(t *testing.T) {
+	i32 := func(x int64) *constant.Int { return constant.NewInt(x, types.I32) }
+	st := types.NewStruct(types.I8, types.I32)
+	src := constant.NewNull(types.NewPointer(st))
+	golden := []struct {
+		src     constant.Constant
+		indices []constant.Constant
+		// Expected result type, or substring of the expected error.
+		want, err string
+	}{
+		{src: src, indices: []constant.Constant{i32(0), i32(1)}, want: "i32*"},
+		{src: src, indices: []constant.Constant{i32(0), i32(2)}, err: "invalid structure element index 2 at index 1"},
+		{src: src, indices: []constant.Constant{i32(0), i32(-1)}, err: "invalid structure element index -1 at index 1"},
+		{src: src, indices: []constant.Constant{i32(0), i32(1), i32(0)}, err: "unable to index into element of type i32 at index 2"},
+		{src: constant.NewNull(types.NewPointer(types.NewPointer(types.I8))), indices: []constant.Constant{i32(0), i32(0)}, err: "unable to index into element of pointer type i8*"},
+		{src: i32(0), err: "invalid source address type"},
+	}
+	for _, g := range golden {
+		expr, err := constant.NewGetElementPtrChecked(g.src, g.indices...)
+		if len(g.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), g.err) {
+				t.Errorf("error mismatch; expected %q, got %v", g.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error; %v", err)
+			continue
+		}
+		if got := expr.Type().String(); got != g.want {
+			t.Errorf("type mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
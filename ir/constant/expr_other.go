@@ -62,13 +62,18 @@ func (*ExprICmp) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprICmp) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprICmp) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprICmp) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // IntPred represents the set of integer predicates of the icmp expression.
 type IntPred int
 
@@ -157,13 +162,18 @@ func (*ExprFCmp) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprFCmp) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprFCmp) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprFCmp) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y}
+}
+
 // FloatPred represents the set of floating-point predicates of the fcmp
 // expression.
 type FloatPred int
@@ -260,9 +270,14 @@ func (*ExprSelect) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprSelect) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprSelect) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprSelect) Operands() []*Constant {
+	return []*Constant{&expr.Cond, &expr.X, &expr.Y}
+}
@@ -60,13 +60,18 @@ func (*ExprExtractElement) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprExtractElement) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprExtractElement) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprExtractElement) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Index}
+}
+
 // --- [ insertelement ] -------------------------------------------------------
 
 // ExprInsertElement represents an insertelement expression.
@@ -114,13 +119,18 @@ func (*ExprInsertElement) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprInsertElement) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprInsertElement) MetadataNode() {}
 
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprInsertElement) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Elem, &expr.Index}
+}
+
 // --- [ shufflevector ] ------------------------------------------------------
 
 // ExprShuffleVector represents an shufflevector expression.
@@ -128,6 +138,8 @@ func (*ExprInsertElement) MetadataNode() {}
 // References:
 //    http://llvm.org/docs/LangRef.html#shufflevector-instruction
 type ExprShuffleVector struct {
+	// Type of the constant expression.
+	Typ *types.VectorType
 	// Vector 1.
 	X Constant
 	// Vector 2.
@@ -140,6 +152,7 @@ type ExprShuffleVector struct {
 // vectors and shuffle mask.
 func NewShuffleVector(x, y, mask Constant) *ExprShuffleVector {
 	return &ExprShuffleVector{
+		Typ:  shuffleVectorType(x.Type(), mask.Type()),
 		X:    x,
 		Y:    y,
 		Mask: mask,
@@ -148,7 +161,7 @@ func NewShuffleVector(x, y, mask Constant) *ExprShuffleVector {
 
 // Type returns the type of the constant expression.
 func (expr *ExprShuffleVector) Type() types.Type {
-	return expr.Mask.Type()
+	return expr.Typ
 }
 
 // Ident returns the string representation of the constant expression.
@@ -168,9 +181,73 @@ func (*ExprShuffleVector) Immutable() {}
 
 // Simplify returns a simplified version of the constant expression.
 func (expr *ExprShuffleVector) Simplify() Constant {
-	panic("not yet implemented")
+	return Fold(expr, nil)
 }
 
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*ExprShuffleVector) MetadataNode() {}
+
+// Operands returns a mutable list of the operands of the constant expression.
+func (expr *ExprShuffleVector) Operands() []*Constant {
+	return []*Constant{&expr.X, &expr.Y, &expr.Mask}
+}
+
+// UndefMaskElem is the shuffle mask index of undefined elements, as used by
+// NewShuffleMask and ShuffleMaskIndices.
+const UndefMaskElem = -1
+
+// NewShuffleMask returns a new shufflevector mask based on the given element
+// indices. Indices smaller than zero, such as UndefMaskElem, denote undefined
+// elements of the shuffled vector.
+func NewShuffleMask(indices ...int64) *Vector {
+	elems := make([]Constant, len(indices))
+	for i, index := range indices {
+		if index < 0 {
+			elems[i] = NewUndef(types.I32)
+		} else {
+			elems[i] = NewInt(index, types.I32)
+		}
+	}
+	return NewVector(elems...)
+}
+
+// ShuffleMaskIndices returns the element indices of the given shufflevector
+// mask, where UndefMaskElem denotes undefined and poison elements. The boolean
+// return value indicates whether mask is a constant vector of integer and
+// undefined elements.
+func ShuffleMaskIndices(mask Constant) ([]int64, bool) {
+	elems, ok := vectorElems(mask)
+	if !ok {
+		return nil, false
+	}
+	indices := make([]int64, len(elems))
+	for i, elem := range elems {
+		switch elem := elem.(type) {
+		case *Undef, *Poison:
+			indices[i] = UndefMaskElem
+		case *Int:
+			indices[i] = elem.Unsigned().Int64()
+		default:
+			return nil, false
+		}
+	}
+	return indices, true
+}
+
+// ### [ Helper functions ] ####################################################
+
+// shuffleVectorType returns the type of a shufflevector of vectors of type x
+// with a shuffle mask of type mask; i.e. a vector with the element type of x
+// and the length of mask.
+func shuffleVectorType(x, mask types.Type) *types.VectorType {
+	xt, ok := x.(*types.VectorType)
+	if !ok {
+		panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", x))
+	}
+	mt, ok := mask.(*types.VectorType)
+	if !ok {
+		panic(fmt.Errorf("invalid shuffle mask type; expected *types.VectorType, got %T", mask))
+	}
+	return types.NewVector(xt.Elem, mt.Len)
+}
@@ -87,4 +87,31 @@ type Expr interface {
 	Constant
 	// Simplify returns a simplified version of the constant expression.
 	Simplify() Constant
+	// Operands returns a mutable list of the operands of the constant
+	// expression.
+	Operands() []*Constant
+}
+
+// ### [ Helper functions ] ####################################################
+
+// overflowFlags returns the string representation of the given nuw and nsw
+// overflow flags, with a leading space if non-empty.
+func overflowFlags(nuw, nsw bool) string {
+	s := ""
+	if nuw {
+		s += " nuw"
+	}
+	if nsw {
+		s += " nsw"
+	}
+	return s
+}
+
+// exactFlag returns the string representation of the given exact flag, with a
+// leading space if non-empty.
+func exactFlag(exact bool) string {
+	if exact {
+		return " exact"
+	}
+	return ""
 }
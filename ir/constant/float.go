@@ -7,6 +7,7 @@ package constant
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
 
@@ -20,85 +21,109 @@ import (
 type Float struct {
 	// Floating-point type.
 	Typ *types.FloatType
-	// Floating-point value.
+	// Floating-point value; nil if NaN.
 	X *big.Float
+	// NaN specifies whether the floating-point constant is Not-a-Number (NaN).
+	NaN bool
+	// Binary representation of NaN values in the format of the floating-point
+	// type, which preserves the sign and payload of the NaN.
+	NaNBits *big.Int
 }
 
 // NewFloat returns a new floating-point constant based on the given
-// floating-point value and type.
+// floating-point value and type. The value is rounded to the nearest value
+// representable by the floating-point type.
 func NewFloat(x float64, typ types.Type) *Float {
 	t, ok := typ.(*types.FloatType)
 	if !ok {
 		panic(fmt.Errorf("invalid floating-point constant type; expected *types.FloatType, got %T", typ))
 	}
-	return &Float{Typ: t, X: big.NewFloat(x)}
+	if math.IsNaN(x) {
+		bits := new(big.Int).SetUint64(math.Float64bits(x))
+		return newFloatFromBits(t, convertNaN(bits, types.FloatKindIEEE_64, t.Kind))
+	}
+	return newFloatFromBig(t, big.NewFloat(x))
 }
 
 // NewFloatFromString returns a new floating-point constant based on the given
 // floating-point string and type.
+//
+// Decimal floating-point literals are rounded to the nearest value
+// representable by the floating-point type. Hexadecimal floating-point
+// literals specify the binary representation of the floating-point value, and
+// are parsed exactly.
 func NewFloatFromString(s string, typ types.Type) *Float {
 	// Parse floating-point constant.
 	t, ok := typ.(*types.FloatType)
 	if !ok {
 		panic(fmt.Errorf("invalid floating-point constant type; expected *types.FloatType, got %T", typ))
 	}
-	c := &Float{Typ: t}
 
 	// Parse floating-point literal in hexadecimal format.
+	kind := t.Kind
+	hexKind := func(want types.FloatKind, ndigits int) string {
+		if kind != want {
+			panic(fmt.Errorf("invalid floating-point constant %q for type %v", s, t))
+		}
+		hex := s[len("0xK"):]
+		if len(hex) != ndigits {
+			panic(fmt.Errorf("invalid length of hexadecimal floating-point constant %q; expected %d hexadecimal digits, got %d", s, ndigits, len(hex)))
+		}
+		return hex
+	}
 	switch {
 	case strings.HasPrefix(s, "0xK"):
 		//   HexFP80Constant   0xK[0-9A-Fa-f]+    // 20 hex digits
-
-		// TODO: Implement support for the 0xK floating-point representation format.
-		c.X = &big.Float{}
-		c.X.SetString("0.0") // TODO: Remove placehold zero value.
-		//panic(fmt.Errorf("support for floating-point constant 0xK representation not yet implemented; unable to parse floating-point constant %q", s))
-		s = s[len("0xK"):]
-		return c
+		//
+		// The sign and exponent are followed by the integer part and fraction.
+		f := floats.NewFloat80FromString(hexKind(types.FloatKindDoubleExtended_80, 20))
+		se, m := f.Bits()
+		return newFloatFromBits(t, joinBits(uint64(se), m))
 	case strings.HasPrefix(s, "0xL"):
 		//   HexFP128Constant  0xL[0-9A-Fa-f]+    // 32 hex digits
-
-		// TODO: Implement support for the 0xL floating-point representation format.
-		c.X = &big.Float{}
-		c.X.SetString("0.0") // TODO: Remove placehold zero value.
-		//panic(fmt.Errorf("support for floating-point constant 0xL representation not yet implemented; unable to parse floating-point constant %q", s))
-		s = s[len("0xL"):]
-		return c
+		//
+		// The least significant 64 bits precede the most significant 64 bits.
+		hex := hexKind(types.FloatKindIEEE_128, 32)
+		f := floats.NewFloat128FromString(hex[16:] + hex[:16])
+		return newFloatFromBits(t, joinBits(f.Bits()))
 	case strings.HasPrefix(s, "0xM"):
 		//   HexPPC128Constant 0xM[0-9A-Fa-f]+    // 32 hex digits
-
-		// TODO: Implement support for the 0xM floating-point representation format.
-		c.X = &big.Float{}
-		c.X.SetString("0.0") // TODO: Remove placehold zero value.
-		//panic(fmt.Errorf("support for floating-point constant 0xM representation not yet implemented; unable to parse floating-point constant %q", s))
-		s = s[len("0xM"):]
-		return c
+		//
+		// The high-order double precedes the low-order double.
+		f := floats.NewDoubleDoubleFromString(hexKind(types.FloatKindDoubleDouble_128, 32))
+		return newFloatFromBits(t, joinBits(f.Bits()))
 	case strings.HasPrefix(s, "0xH"):
 		//   HexHalfConstant   0xH[0-9A-Fa-f]+    // 4 hex digits
-
-		str := s[len("0xH"):]
-		x := floats.NewFloat16FromString(str)
-		c.X = big.NewFloat(x.Float64())
-		return c
+		f := floats.NewFloat16FromString(hexKind(types.FloatKindIEEE_16, 4))
+		return newFloatFromBits(t, new(big.Int).SetUint64(uint64(f.Bits())))
 	case strings.HasPrefix(s, "0x"):
 		//   HexFPConstant     0x[0-9A-Fa-f]+     // 16 hex digits
-
-		// TODO: Implement support for the 0x floating-point representation format.
-		c.X = &big.Float{}
-		c.X.SetString("0.0") // TODO: Remove placehold zero value.
-		//panic(fmt.Errorf("support for floating-point constant 0x representation not yet implemented; unable to parse floating-point constant %q", s))
-		s = s[len("0x"):]
-		return c
+		//
+		// The binary representation is in double precision format, regardless
+		// of floating-point type.
+		bits := parseHex(s, s[len("0x"):])
+		if kind == types.FloatKindIEEE_64 {
+			return newFloatFromBits(t, bits)
+		}
+		x := math.Float64frombits(bits.Uint64())
+		if math.IsNaN(x) {
+			return newFloatFromBits(t, convertNaN(bits, types.FloatKindIEEE_64, kind))
+		}
+		return newFloatFromBig(t, big.NewFloat(x))
 	}
 
 	// Parse floating-point literal.
 	//
 	//   FPConstant        [-+]?[0-9]+[.][0-9]*([eE][-+]?[0-9]+)?
-	c.X = &big.Float{}
-	if _, ok := c.X.SetString(s); !ok {
-		panic(fmt.Errorf("unable to parse floating-point constant %q", s))
+	//
+	// Parse with a precision well beyond that of the floating-point types, before
+	// rounding to the precision of the floating-point type.
+	const prec = 256
+	x, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		panic(fmt.Errorf("unable to parse floating-point constant %q; %v", s, err))
 	}
-	return c
+	return newFloatFromBig(t, x)
 }
 
 // Type returns the type of the constant.
@@ -107,54 +132,49 @@ func (c *Float) Type() types.Type {
 }
 
 // Ident returns the string representation of the constant.
+//
+// Decimal notation is used for finite float and double values, and hexadecimal
+// notation is used otherwise.
 func (c *Float) Ident() string {
-	// Hexadecimal format is always used for long double, and there are three
-	// forms of long double.
+	// Hexadecimal format is always used for half and long double, and there are
+	// three forms of long double.
 	kind := c.Typ.Kind
+	bits := c.bits()
 	switch kind {
+	case types.FloatKindIEEE_16:
+		// The IEEE 16-bit format is represented by 0xH followed by 4 hexadecimal
+		// digits.
+		return fmt.Sprintf("0xH%04X", bits)
 	case types.FloatKindIEEE_128:
 		// The IEEE 128-bit format is represented by 0xL followed by 32
-		// hexadecimal digits.
-
-		// TODO: Implement.
-		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+		// hexadecimal digits; the least significant 64 bits followed by the most
+		// significant 64 bits.
+		f := floats.NewFloat128FromBits(splitBits(bits))
+		hex := f.String()
+		return "0xL" + hex[16:] + hex[:16]
 	case types.FloatKindDoubleExtended_80:
 		// The 80-bit format used by x86 is represented as 0xK followed by 20
 		// hexadecimal digits.
-
-		// TODO: Represent precise float80 using c.X.MantExp and
-		// floats.NewFloat80FromBits.
-		f := floats.NewFloat80FromFloat64(c.Float64())
+		se, m := splitBits(bits)
+		f := floats.NewFloat80FromBits(uint16(se), m)
 		return "0xK" + f.String()
 	case types.FloatKindDoubleDouble_128:
 		// The 128-bit format used by PowerPC (two adjacent doubles) is
 		// represented by 0xM followed by 32 hexadecimal digits.
-
-		// TODO: Implement.
-		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+		return fmt.Sprintf("0xM%032X", bits)
 	}
 
-	// TODO: Handle NaN, special values (e.g. Pi). Print those in hexadecimal
-	// representation.
-
-	// Use hexadecimal representation for +Inf and -Inf.
-	printHex := c.X.IsInf()
-	if printHex {
-		switch kind {
-		case types.FloatKindIEEE_16:
-			// TODO: Implement.
-			x, _ := c.X.Float64()
-			f16, _ := floats.NewFloat16FromFloat64(x)
-			return "0xH" + f16.String()
-		case types.FloatKindIEEE_32:
-			// TODO: Implement.
-			panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
-		case types.FloatKindIEEE_64:
-			// TODO: Implement.
-			panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
-		default:
-			panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	// Use hexadecimal representation of double precision format for NaN, +Inf
+	// and -Inf.
+	if c.NaN || c.X.IsInf() {
+		if kind == types.FloatKindIEEE_32 {
+			if c.NaN {
+				bits = convertNaN(bits, kind, types.FloatKindIEEE_64)
+			} else {
+				bits.SetUint64(math.Float64bits(c.Float64()))
+			}
 		}
+		return fmt.Sprintf("0x%016X", bits)
 	}
 
 	// Insert decimal point if not present.
@@ -184,6 +204,201 @@ func (*Float) MetadataNode() {}
 
 // Float64 returns the float64 representation of the floating-point constant.
 func (c *Float) Float64() float64 {
+	if c.NaN {
+		bits := convertNaN(c.NaNBits, c.Typ.Kind, types.FloatKindIEEE_64)
+		return math.Float64frombits(bits.Uint64())
+	}
 	x, _ := c.X.Float64()
 	return x
 }
+
+// bits returns the binary representation of the floating-point constant in
+// the format of its floating-point type.
+func (c *Float) bits() *big.Int {
+	if c.NaN {
+		return new(big.Int).Set(c.NaNBits)
+	}
+	return encodeFloat(c.Typ.Kind, c.X)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// newFloatFromBig returns a new floating-point constant of the given type, with
+// the value of x rounded to the nearest value representable by the type.
+func newFloatFromBig(t *types.FloatType, x *big.Float) *Float {
+	return newFloatFromBits(t, encodeFloat(t.Kind, x))
+}
+
+// newFloatFromBits returns a new floating-point constant of the given type,
+// based on its binary representation in the format of the type.
+func newFloatFromBits(t *types.FloatType, bits *big.Int) *Float {
+	nan := &Float{Typ: t, NaN: true, NaNBits: bits}
+	switch t.Kind {
+	case types.FloatKindIEEE_16:
+		f := floats.NewFloat16FromBits(uint16(bits.Uint64()))
+		if f.IsNaN() {
+			return nan
+		}
+		return &Float{Typ: t, X: f.Big()}
+	case types.FloatKindIEEE_32:
+		x := math.Float32frombits(uint32(bits.Uint64()))
+		if math.IsNaN(float64(x)) {
+			return nan
+		}
+		// Use double precision, as float values are printed in decimal notation
+		// as the double value they represent.
+		return &Float{Typ: t, X: big.NewFloat(float64(x))}
+	case types.FloatKindIEEE_64:
+		x := math.Float64frombits(bits.Uint64())
+		if math.IsNaN(x) {
+			return nan
+		}
+		return &Float{Typ: t, X: big.NewFloat(x)}
+	case types.FloatKindIEEE_128:
+		f := floats.NewFloat128FromBits(splitBits(bits))
+		if f.IsNaN() {
+			return nan
+		}
+		return &Float{Typ: t, X: f.Big()}
+	case types.FloatKindDoubleExtended_80:
+		se, m := splitBits(bits)
+		f := floats.NewFloat80FromBits(uint16(se), m)
+		if f.IsNaN() {
+			return nan
+		}
+		return &Float{Typ: t, X: f.Big()}
+	case types.FloatKindDoubleDouble_128:
+		f := floats.NewDoubleDoubleFromBits(splitBits(bits))
+		if f.IsNaN() {
+			return nan
+		}
+		return &Float{Typ: t, X: f.Big()}
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", t.Kind))
+	}
+}
+
+// encodeFloat returns the binary representation, in the format of the given
+// floating-point kind, of the nearest value of x representable by the
+// floating-point kind.
+//
+// The PowerPC double-double representation of x is the nearest double of x,
+// followed by the nearest double of the remainder. Only canonical double-double
+// representations are thus preserved by parsing and printing.
+func encodeFloat(kind types.FloatKind, x *big.Float) *big.Int {
+	switch kind {
+	case types.FloatKindIEEE_16:
+		f, _ := floats.NewFloat16FromBig(x)
+		return new(big.Int).SetUint64(uint64(f.Bits()))
+	case types.FloatKindIEEE_32:
+		f, _ := x.Float32()
+		return new(big.Int).SetUint64(uint64(math.Float32bits(f)))
+	case types.FloatKindIEEE_64:
+		f, _ := x.Float64()
+		return new(big.Int).SetUint64(math.Float64bits(f))
+	case types.FloatKindIEEE_128:
+		f, _ := floats.NewFloat128FromBig(x)
+		return joinBits(f.Bits())
+	case types.FloatKindDoubleExtended_80:
+		f, _ := floats.NewFloat80FromBig(x)
+		se, m := f.Bits()
+		return joinBits(uint64(se), m)
+	case types.FloatKindDoubleDouble_128:
+		f, _ := floats.NewDoubleDoubleFromBig(x)
+		return joinBits(f.Bits())
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	}
+}
+
+// convertNaN converts the binary representation of the given NaN value from
+// the format of one floating-point kind to another, preserving the sign, the
+// quiet bit and the most significant bits of the payload.
+func convertNaN(bits *big.Int, from, to types.FloatKind) *big.Int {
+	// The high-order double of a double-double determines its NaN value.
+	if from == types.FloatKindDoubleDouble_128 {
+		bits = new(big.Int).Rsh(bits, 64)
+		from = types.FloatKindIEEE_64
+	}
+	if to == types.FloatKindDoubleDouble_128 {
+		z := convertNaN(bits, from, types.FloatKindIEEE_64)
+		return z.Lsh(z, 64)
+	}
+	// Number of exponent and fraction bits.
+	_, fromFrac := nanFormat(from)
+	toExp, toFrac := nanFormat(to)
+	// Sign.
+	sign := bits.Bit(int(floatBits(from) - 1))
+	// Fraction; the most significant bit of which is the quiet bit.
+	frac := new(big.Int).Lsh(big.NewInt(1), fromFrac)
+	frac.Sub(frac, big.NewInt(1))
+	frac.And(frac, bits)
+	if toFrac >= fromFrac {
+		frac.Lsh(frac, toFrac-fromFrac)
+	} else {
+		frac.Rsh(frac, fromFrac-toFrac)
+	}
+	if frac.Sign() == 0 {
+		// Set quiet bit if the payload is lost.
+		frac.SetBit(frac, int(toFrac-1), 1)
+	}
+	// Exponent, all ones.
+	z := new(big.Int).Lsh(big.NewInt(1), toExp)
+	z.Sub(z, big.NewInt(1))
+	sigBits := toFrac
+	if to == types.FloatKindDoubleExtended_80 {
+		// Explicit integer part.
+		z.Lsh(z, 1)
+		z.SetBit(z, 0, 1)
+		sigBits++
+	}
+	z.Lsh(z, sigBits)
+	z.Or(z, frac)
+	return z.SetBit(z, int(floatBits(to)-1), sign)
+}
+
+// nanFormat returns the number of exponent and fraction bits of the given IEEE
+// floating-point kind.
+func nanFormat(kind types.FloatKind) (exp, frac uint) {
+	switch kind {
+	case types.FloatKindIEEE_16:
+		return 5, 10
+	case types.FloatKindIEEE_32:
+		return 8, 23
+	case types.FloatKindIEEE_64:
+		return 11, 52
+	case types.FloatKindIEEE_128:
+		return 15, 112
+	case types.FloatKindDoubleExtended_80:
+		return 15, 63
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	}
+}
+
+// joinBits returns the 128-bit integer with the given most significant and
+// least significant 64 bits.
+func joinBits(hi, lo uint64) *big.Int {
+	z := new(big.Int).SetUint64(hi)
+	z.Lsh(z, 64)
+	return z.Or(z, new(big.Int).SetUint64(lo))
+}
+
+// splitBits returns the most significant and least significant 64 bits of the
+// given 128-bit integer.
+func splitBits(z *big.Int) (hi, lo uint64) {
+	mask := new(big.Int).SetUint64(math.MaxUint64)
+	lo = new(big.Int).And(z, mask).Uint64()
+	hi = new(big.Int).Rsh(z, 64).Uint64()
+	return hi, lo
+}
+
+// parseHex returns the integer value of the given hexadecimal digits of the
+// floating-point literal s.
+func parseHex(s, hex string) *big.Int {
+	z, ok := new(big.Int).SetString(hex, 16)
+	if !ok {
+		panic(fmt.Errorf("unable to parse hexadecimal floating-point constant %q", s))
+	}
+	return z
+}
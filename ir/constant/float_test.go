@@ -0,0 +1,55 @@
+package constant_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestNewFloatFromString(t *testing.T) {
+	golden := []struct {
+		in   string
+		typ  *types.FloatType
+		want string
+	}{
+		// Decimal literals are rounded to the floating-point type.
+		{in: "1.5", typ: types.Double, want: "1.5"},
+		{in: "0.1", typ: types.Float, want: "0.10000000149011612"},
+		{in: "1e300", typ: types.Float, want: "0x7FF0000000000000"},
+		{in: "0.1", typ: types.Half, want: "0xH2E66"},
+		{in: "0.1", typ: types.X86_FP80, want: "0xK3FFBCCCCCCCCCCCCCCCD"},
+		{in: "0.1", typ: types.FP128, want: "0xL999999999999999A3FFB999999999999"},
+		{in: "0.1", typ: types.PPC_FP128, want: "0xM3FB999999999999ABC5999999999999A"},
+		{in: "-0.0", typ: types.FP128, want: "0xL00000000000000008000000000000000"},
+		// Hexadecimal literals of double precision format.
+		{in: "0x3FF8000000000000", typ: types.Double, want: "1.5"},
+		{in: "0x3FF8000000000000", typ: types.Float, want: "1.5"},
+		{in: "0x3FF8000000000000", typ: types.X86_FP80, want: "0xK3FFFC000000000000000"},
+		{in: "0x7FF8000000000000", typ: types.Half, want: "0xH7E00"},
+		{in: "0xFFF0000000000000", typ: types.Double, want: "0xFFF0000000000000"},
+		// Signaling NaN payloads are preserved.
+		{in: "0x7FF0000020000000", typ: types.Float, want: "0x7FF0000020000000"},
+		{in: "0xK7FFF8000000000000001", typ: types.X86_FP80, want: "0xK7FFF8000000000000001"},
+	}
+	for _, g := range golden {
+		c := constant.NewFloatFromString(g.in, g.typ)
+		if got := c.Ident(); got != g.want {
+			t.Errorf("%v constant %q mismatch; expected %q, got %q", g.typ, g.in, g.want, got)
+		}
+	}
+}
+
+func TestNewFloatNaN(t *testing.T) {
+	c := constant.NewFloat(math.NaN(), types.Double)
+	if !c.NaN || c.X != nil {
+		t.Fatalf("expected NaN constant, got %q", c.Ident())
+	}
+	if got, want := c.Ident(), "0x7FF8000000000001"; got != want {
+		t.Errorf("NaN mismatch; expected %q, got %q", want, got)
+	}
+	if !math.IsNaN(c.Float64()) {
+		t.Errorf("expected NaN, got %v", c.Float64())
+	}
+}
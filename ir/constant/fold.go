@@ -0,0 +1,1476 @@
+// === [ Constant folding ] ====================================================
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#constant-expressions
+
+package constant
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/ir/datalayout"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Fold returns a simplified version of the given constant. Constant
+// expressions are folded as far as possible, and other constants are returned
+// as is.
+//
+// The data layout dl is used to fold target-dependent address computations,
+// such as nested getelementptr expressions on global variables and the sizeof
+// idiom `ptrtoint (T* getelementptr (T, T* null, i64 1) to i64)`. Such
+// expressions are left unfolded if dl is nil.
+func Fold(c Constant, dl *datalayout.DataLayout) Constant {
+	f := &folder{dl: dl}
+	return f.simplify(c)
+}
+
+// folder folds constant expressions based on an optional data layout.
+type folder struct {
+	// Data layout of the target; or nil if unknown.
+	dl *datalayout.DataLayout
+}
+
+// simplify returns a simplified version of the given constant. Constant
+// expressions are folded as far as possible, and other constants are returned
+// as is.
+func (f *folder) simplify(c Constant) Constant {
+	switch expr := c.(type) {
+	// Binary and bitwise expressions
+	case *ExprAdd, *ExprFAdd, *ExprSub, *ExprFSub, *ExprMul, *ExprFMul,
+		*ExprUDiv, *ExprSDiv, *ExprFDiv, *ExprURem, *ExprSRem, *ExprFRem,
+		*ExprShl, *ExprLShr, *ExprAShr, *ExprAnd, *ExprOr, *ExprXor:
+		ops := expr.(Expr).Operands()
+		return f.foldBinary(expr.(Expr), *ops[0], *ops[1])
+	// Conversion expressions
+	case *ExprTrunc, *ExprZExt, *ExprSExt, *ExprFPTrunc, *ExprFPExt,
+		*ExprFPToUI, *ExprFPToSI, *ExprUIToFP, *ExprSIToFP, *ExprPtrToInt,
+		*ExprIntToPtr, *ExprBitCast, *ExprAddrSpaceCast:
+		ops := expr.(Expr).Operands()
+		return f.foldConversion(expr.(Expr), *ops[0], expr.Type())
+	// Other expressions
+	case *ExprICmp:
+		return f.foldICmp(expr, expr.X, expr.Y)
+	case *ExprFCmp:
+		return f.foldFCmp(expr, expr.X, expr.Y)
+	case *ExprSelect:
+		return f.foldSelect(expr)
+	// Vector expressions
+	case *ExprExtractElement:
+		return f.foldExtractElement(expr)
+	case *ExprInsertElement:
+		return f.foldInsertElement(expr)
+	case *ExprShuffleVector:
+		return f.foldShuffleVector(expr)
+	// Aggregate expressions
+	case *ExprExtractValue:
+		return f.foldExtractValue(expr)
+	case *ExprInsertValue:
+		return f.foldInsertValue(expr)
+	// Memory expressions
+	case *ExprGetElementPtr:
+		return f.foldGetElementPtr(expr)
+	}
+	return c
+}
+
+// --- [ Binary and bitwise expressions ] --------------------------------------
+
+// foldBinary folds the given binary or bitwise expression with operands x and
+// y. Vector operands are folded element-wise. The expression is returned
+// unchanged if it cannot be folded.
+func (f *folder) foldBinary(expr Expr, x, y Constant) Constant {
+	x, y = f.simplify(x), f.simplify(y)
+	if xs, ok := vectorElems(x); ok {
+		ys, ok := vectorElems(y)
+		if !ok || len(xs) != len(ys) {
+			return expr
+		}
+		elems := make([]Constant, len(xs))
+		for i := range xs {
+			elem := foldScalarBinary(expr, xs[i], ys[i])
+			if elem == nil {
+				return expr
+			}
+			elems[i] = elem
+		}
+		return newVector(x.Type(), elems)
+	}
+	if c := foldScalarBinary(expr, x, y); c != nil {
+		return c
+	}
+	return expr
+}
+
+// foldScalarBinary folds the given binary or bitwise expression with scalar
+// operands x and y. It returns nil if the expression cannot be folded.
+//
+// Poison operands yield poison, as do integer operations which would otherwise
+// be undefined behaviour (division by zero and signed overflow) or produce
+// poison (shift amounts larger than or equal to the bit size, overflow with the
+// nuw or nsw flag set, and inexact results with the exact flag set).
+func foldScalarBinary(expr Expr, x, y Constant) Constant {
+	if isPoison(x) || isPoison(y) {
+		return NewPoison(x.Type())
+	}
+	_, xUndef := x.(*Undef)
+	_, yUndef := y.(*Undef)
+	if xUndef || yUndef {
+		return foldUndefBinary(expr, x.Type(), xUndef, yUndef)
+	}
+	switch expr := expr.(type) {
+	// Integer operations.
+	case *ExprAdd:
+		return foldInt(x, y, noWrap(wrap((*Int).Add), (*Int).AddOverflows, expr.NUW, expr.NSW))
+	case *ExprSub:
+		return foldInt(x, y, noWrap(wrap((*Int).Sub), (*Int).SubOverflows, expr.NUW, expr.NSW))
+	case *ExprMul:
+		return foldInt(x, y, noWrap(wrap((*Int).Mul), (*Int).MulOverflows, expr.NUW, expr.NSW))
+	case *ExprUDiv:
+		return foldInt(x, y, exact((*Int).UDiv, udivInexact, expr.Exact))
+	case *ExprSDiv:
+		return foldInt(x, y, exact((*Int).SDiv, sdivInexact, expr.Exact))
+	case *ExprURem:
+		return foldInt(x, y, (*Int).URem)
+	case *ExprSRem:
+		return foldInt(x, y, (*Int).SRem)
+	case *ExprShl:
+		return foldInt(x, y, noWrap((*Int).Shl, (*Int).ShlOverflows, expr.NUW, expr.NSW))
+	case *ExprLShr:
+		return foldInt(x, y, exact((*Int).LShr, (*Int).ShrInexact, expr.Exact))
+	case *ExprAShr:
+		return foldInt(x, y, exact((*Int).AShr, (*Int).ShrInexact, expr.Exact))
+	case *ExprAnd:
+		return foldInt(x, y, wrap((*Int).And))
+	case *ExprOr:
+		return foldInt(x, y, wrap((*Int).Or))
+	case *ExprXor:
+		return foldInt(x, y, wrap((*Int).Xor))
+	// Floating-point operations.
+	case *ExprFAdd:
+		return foldFloat(x, y, func(a, b float64) float64 { return a + b }, (*big.Float).Add)
+	case *ExprFSub:
+		return foldFloat(x, y, func(a, b float64) float64 { return a - b }, (*big.Float).Sub)
+	case *ExprFMul:
+		return foldFloat(x, y, func(a, b float64) float64 { return a * b }, (*big.Float).Mul)
+	case *ExprFDiv:
+		return foldFloat(x, y, func(a, b float64) float64 { return a / b }, (*big.Float).Quo)
+	case *ExprFRem:
+		return foldFloat(x, y, math.Mod, nil)
+	}
+	return nil
+}
+
+// foldUndefBinary folds the given binary or bitwise expression of the given
+// scalar type, with at least one undefined operand.
+//
+// An undefined operand may be assumed to hold any value, which is chosen so
+// that the result is either undefined or a known constant.
+func foldUndefBinary(expr Expr, t types.Type, xUndef, yUndef bool) Constant {
+	if xUndef && yUndef {
+		return NewUndef(t)
+	}
+	switch expr.(type) {
+	case *ExprMul, *ExprAnd:
+		// undef * x -> 0
+		// undef & x -> 0
+		return zeroValue(t)
+	case *ExprOr:
+		// undef | x -> -1
+		if t, ok := t.(*types.IntType); ok {
+			return newInt(t, big.NewInt(-1))
+		}
+	case *ExprUDiv, *ExprSDiv, *ExprURem, *ExprSRem, *ExprShl, *ExprLShr, *ExprAShr:
+		// x / undef -> poison (the divisor may be zero)
+		// x << undef -> poison (the shift amount may exceed the bit size)
+		if yUndef {
+			return NewPoison(t)
+		}
+		// undef / x -> 0
+		// undef << x -> 0
+		return zeroValue(t)
+	}
+	// undef + x -> undef
+	// undef ^ x -> undef
+	// fadd undef, x -> undef
+	return NewUndef(t)
+}
+
+// foldInt folds the integer operation f on operands x and y. It returns nil if
+// x and y are not integer constants of the same type, and poison if f reports
+// that the result is undefined or poison.
+func foldInt(x, y Constant, f func(a, b *Int) (*Int, bool)) Constant {
+	a, ok := x.(*Int)
+	if !ok {
+		return nil
+	}
+	b, ok := y.(*Int)
+	if !ok || !a.Typ.Equal(b.Typ) {
+		return nil
+	}
+	z, ok := f(a, b)
+	if !ok {
+		return NewPoison(a.Typ)
+	}
+	return z
+}
+
+// wrap returns the integer operation f, which is defined for all operands, in
+// the form used by foldInt.
+func wrap(f func(a, b *Int) *Int) func(a, b *Int) (*Int, bool) {
+	return func(a, b *Int) (*Int, bool) {
+		return f(a, b), true
+	}
+}
+
+// noWrap returns the integer operation f, extended to report poison if the nuw
+// or nsw flag is set and overflows reports an unsigned or signed overflow
+// respectively.
+func noWrap(f func(a, b *Int) (*Int, bool), overflows func(a, b *Int) (unsigned, signed bool), nuw, nsw bool) func(a, b *Int) (*Int, bool) {
+	if !nuw && !nsw {
+		return f
+	}
+	return func(a, b *Int) (*Int, bool) {
+		unsigned, signed := overflows(a, b)
+		if (nuw && unsigned) || (nsw && signed) {
+			return nil, false
+		}
+		return f(a, b)
+	}
+}
+
+// exact returns the integer operation f, extended to report poison if the
+// exact flag is set and inexact reports that the result is inexact.
+func exact(f func(a, b *Int) (*Int, bool), inexact func(a, b *Int) bool, isExact bool) func(a, b *Int) (*Int, bool) {
+	if !isExact {
+		return f
+	}
+	return func(a, b *Int) (*Int, bool) {
+		z, ok := f(a, b)
+		if !ok || inexact(a, b) {
+			return nil, false
+		}
+		return z, true
+	}
+}
+
+// udivInexact reports whether the unsigned quotient x/y is inexact; i.e.
+// whether the result of a udiv with the exact flag is poison.
+func udivInexact(x, y *Int) bool {
+	r, ok := x.URem(y)
+	return !ok || !r.isZero()
+}
+
+// sdivInexact reports whether the signed quotient x/y is inexact; i.e. whether
+// the result of an sdiv with the exact flag is poison.
+func sdivInexact(x, y *Int) bool {
+	r, ok := x.SRem(y)
+	return !ok || !r.isZero()
+}
+
+// foldFloat folds the floating-point operation on operands x and y, using f
+// for half, float and double operands, and g for operands of larger
+// floating-point types. It returns nil if x and y are not floating-point
+// constants, if g is nil for operands of larger floating-point types, or if
+// either operand or the result is NaN.
+func foldFloat(x, y Constant, f func(a, b float64) float64, g func(z, a, b *big.Float) *big.Float) Constant {
+	a, ok := x.(*Float)
+	if !ok {
+		return nil
+	}
+	b, ok := y.(*Float)
+	if !ok || a.NaN || b.NaN {
+		return nil
+	}
+	switch a.Typ.Kind {
+	case types.FloatKindIEEE_16, types.FloatKindIEEE_32, types.FloatKindIEEE_64:
+		// The result of the float64 operation rounded to half or float is
+		// identical to the result of the operation in the smaller precision,
+		// as float64 has more than twice the precision of float.
+		return newFloat(a.Typ, f(a.Float64(), b.Float64()))
+	default:
+		if g == nil {
+			return nil
+		}
+		return newBigFloat(a.Typ, func(z *big.Float) *big.Float {
+			return g(z, a.X, b.X)
+		})
+	}
+}
+
+// --- [ Conversion expressions ] ----------------------------------------------
+
+// foldConversion folds the given conversion expression of the given constant
+// to the given type. Vector constants are converted element-wise, except for
+// bitcast. The expression is returned unchanged if it cannot be folded.
+func (f *folder) foldConversion(expr Expr, from Constant, to types.Type) Constant {
+	from = f.simplify(from)
+	if _, ok := expr.(*ExprBitCast); !ok {
+		if elems, ok := vectorElems(from); ok {
+			t, ok := to.(*types.VectorType)
+			if !ok || int64(len(elems)) != t.Len {
+				return expr
+			}
+			results := make([]Constant, len(elems))
+			for i, elem := range elems {
+				result := f.foldScalarConversion(expr, elem, t.Elem)
+				if result == nil {
+					return expr
+				}
+				results[i] = result
+			}
+			return newVector(t, results)
+		}
+	}
+	if c := f.foldScalarConversion(expr, from, to); c != nil {
+		return c
+	}
+	return expr
+}
+
+// foldScalarConversion folds the given conversion expression of the given
+// scalar constant to the given type. It returns nil if the expression cannot
+// be folded.
+func (f *folder) foldScalarConversion(expr Expr, from Constant, to types.Type) Constant {
+	if isPoison(from) {
+		return NewPoison(to)
+	}
+	if _, ok := from.(*Undef); ok {
+		switch expr.(type) {
+		case *ExprZExt, *ExprSExt:
+			// The extended bits are defined; zext undef -> 0
+			return zeroValue(to)
+		}
+		return NewUndef(to)
+	}
+	switch expr.(type) {
+	case *ExprTrunc:
+		a, ok := from.(*Int)
+		t, ok2 := to.(*types.IntType)
+		if ok && ok2 && t.Size < a.Typ.Size {
+			return a.Trunc(t)
+		}
+	case *ExprZExt:
+		a, ok := from.(*Int)
+		t, ok2 := to.(*types.IntType)
+		if ok && ok2 && t.Size > a.Typ.Size {
+			return a.ZExt(t)
+		}
+	case *ExprSExt:
+		a, ok := from.(*Int)
+		t, ok2 := to.(*types.IntType)
+		if ok && ok2 && t.Size > a.Typ.Size {
+			return a.SExt(t)
+		}
+	case *ExprFPTrunc, *ExprFPExt:
+		a, ok := from.(*Float)
+		t, ok2 := to.(*types.FloatType)
+		if !ok || !ok2 {
+			return nil
+		}
+		if a.NaN {
+			return newFloatFromBits(t, convertNaN(a.NaNBits, a.Typ.Kind, t.Kind))
+		}
+		return newFloatFromBig(t, a.X)
+	case *ExprFPToUI, *ExprFPToSI:
+		a, ok := from.(*Float)
+		t, ok2 := to.(*types.IntType)
+		if !ok || !ok2 {
+			return nil
+		}
+		// NaN, infinity and values out of range of the integer type yield
+		// poison.
+		if a.NaN || a.X.IsInf() {
+			return NewPoison(t)
+		}
+		// Round toward zero.
+		z, _ := a.X.Int(nil)
+		var min, max *big.Int
+		if _, ok := expr.(*ExprFPToUI); ok {
+			min, max = new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+		} else {
+			max = new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+			min = new(big.Int).Neg(max)
+		}
+		if z.Cmp(min) < 0 || z.Cmp(max) >= 0 {
+			return NewPoison(t)
+		}
+		return newInt(t, z)
+	case *ExprUIToFP, *ExprSIToFP:
+		a, ok := from.(*Int)
+		t, ok2 := to.(*types.FloatType)
+		if !ok || !ok2 {
+			return nil
+		}
+		z := a.Unsigned()
+		if _, ok := expr.(*ExprSIToFP); ok {
+			z = a.Signed()
+		}
+		return newFloatFromBig(t, new(big.Float).SetInt(z))
+	case *ExprPtrToInt:
+		t, ok := to.(*types.IntType)
+		if !ok {
+			return nil
+		}
+		if _, ok := from.(*Null); ok {
+			return zeroValue(t)
+		}
+		return f.foldPtrToInt(from, t)
+	case *ExprIntToPtr:
+		t, ok := to.(*types.PointerType)
+		if !ok {
+			return nil
+		}
+		if a, ok := from.(*Int); ok && a.Unsigned().Sign() == 0 {
+			return NewNull(t)
+		}
+		return f.foldIntToPtr(from, t)
+	case *ExprBitCast:
+		return foldBitCast(from, to)
+	}
+	return nil
+}
+
+// foldPtrToInt folds the conversion of the given constant pointer to the given
+// integer type, based on the data layout. It returns nil if the conversion
+// cannot be folded.
+func (f *folder) foldPtrToInt(from Constant, to *types.IntType) Constant {
+	pt, ok := from.Type().(*types.PointerType)
+	if !ok || f.dl == nil || f.dl.IsNonIntegral(pt.AddrSpace) {
+		return nil
+	}
+	ptrSize := int(f.dl.Pointer(pt.AddrSpace).Size)
+	// ptrtoint (inttoptr (iN x to T*) to iM) -> x, zero-extended or truncated
+	// to iM, if N is at most the pointer size.
+	if from, ok := from.(*ExprIntToPtr); ok {
+		x := f.simplify(from.From)
+		xt, ok := x.Type().(*types.IntType)
+		if !ok || xt.Size > ptrSize {
+			return nil
+		}
+		switch {
+		case xt.Size < to.Size:
+			return f.simplify(NewZExt(x, to))
+		case xt.Size > to.Size:
+			return f.simplify(NewTrunc(x, to))
+		}
+		return x
+	}
+	// Addresses relative to null (e.g. the sizeof idiom
+	// `ptrtoint (T* getelementptr (T, T* null, i64 1) to i64)`) are converted
+	// to their offset.
+	base, offset, ok := f.addrOffset(from)
+	if !ok {
+		return nil
+	}
+	if _, ok := base.(*Null); !ok {
+		return nil
+	}
+	return newInt(to, wrapUnsigned(big.NewInt(offset), ptrSize))
+}
+
+// foldIntToPtr folds the conversion of the given integer constant to the given
+// pointer type, based on the data layout. It returns nil if the conversion
+// cannot be folded.
+func (f *folder) foldIntToPtr(from Constant, to *types.PointerType) Constant {
+	if f.dl == nil || f.dl.IsNonIntegral(to.AddrSpace) {
+		return nil
+	}
+	// inttoptr (ptrtoint (T* x to iN) to U*) -> bitcast (T* x to U*), if N is at
+	// least the pointer size.
+	p, ok := from.(*ExprPtrToInt)
+	if !ok {
+		return nil
+	}
+	x := f.simplify(p.From)
+	xt, ok := x.Type().(*types.PointerType)
+	if !ok || xt.AddrSpace != to.AddrSpace {
+		return nil
+	}
+	if t, ok := p.To.(*types.IntType); !ok || int64(t.Size) < f.dl.Pointer(xt.AddrSpace).Size {
+		return nil
+	}
+	return f.pointerCast(x, to)
+}
+
+// foldBitCast folds the bitcast of the given constant to the given type. It
+// returns nil if the bitcast cannot be folded.
+func foldBitCast(from Constant, to types.Type) Constant {
+	if from.Type().Equal(to) {
+		return from
+	}
+	switch from := from.(type) {
+	case *Null:
+		if t, ok := to.(*types.PointerType); ok {
+			return NewNull(t)
+		}
+	case *Int:
+		t, ok := to.(*types.FloatType)
+		if !ok || int64(from.Typ.Size) != floatBits(t.Kind) {
+			return nil
+		}
+		return newFloatFromBits(t, from.Unsigned())
+	case *Float:
+		t, ok := to.(*types.IntType)
+		if !ok || int64(t.Size) != floatBits(from.Typ.Kind) {
+			return nil
+		}
+		return newInt(t, from.bits())
+	}
+	return nil
+}
+
+// --- [ Comparison and select expressions ] -----------------------------------
+
+// foldICmp folds the given icmp expression with operands x and y. Vector
+// operands are compared element-wise. The expression is returned unchanged if
+// it cannot be folded.
+func (f *folder) foldICmp(expr *ExprICmp, x, y Constant) Constant {
+	return f.foldCmp(expr, expr.Typ, x, y, func(x, y Constant) Constant {
+		return f.foldScalarICmp(expr.Pred, x, y)
+	})
+}
+
+// foldFCmp folds the given fcmp expression with operands x and y. Vector
+// operands are compared element-wise. The expression is returned unchanged if
+// it cannot be folded.
+func (f *folder) foldFCmp(expr *ExprFCmp, x, y Constant) Constant {
+	return f.foldCmp(expr, expr.Typ, x, y, func(x, y Constant) Constant {
+		return foldScalarFCmp(expr.Pred, x, y)
+	})
+}
+
+// foldCmp folds the given comparison expression of the given type with
+// operands x and y, using cmp to compare scalar operands.
+func (f *folder) foldCmp(expr Expr, typ types.Type, x, y Constant, cmp func(x, y Constant) Constant) Constant {
+	x, y = f.simplify(x), f.simplify(y)
+	if xs, ok := vectorElems(x); ok {
+		ys, ok := vectorElems(y)
+		if !ok || len(xs) != len(ys) {
+			return expr
+		}
+		elems := make([]Constant, len(xs))
+		for i := range xs {
+			elem := cmp(xs[i], ys[i])
+			if elem == nil {
+				return expr
+			}
+			elems[i] = elem
+		}
+		return newVector(typ, elems)
+	}
+	if c := cmp(x, y); c != nil {
+		return c
+	}
+	return expr
+}
+
+// foldScalarICmp compares the scalar operands x and y based on the given
+// integer predicate. It returns nil if the operands cannot be compared.
+func (f *folder) foldScalarICmp(pred IntPred, x, y Constant) Constant {
+	if isPoison(x) || isPoison(y) {
+		return NewPoison(types.I1)
+	}
+	_, xUndef := x.(*Undef)
+	_, yUndef := y.(*Undef)
+	if xUndef || yUndef {
+		return NewUndef(types.I1)
+	}
+	switch x := x.(type) {
+	case *Int:
+		y, ok := y.(*Int)
+		if !ok {
+			return nil
+		}
+		if !x.Typ.Equal(y.Typ) {
+			return nil
+		}
+		return newBool(x.Compare(pred, y))
+	case *Null:
+		if _, ok := y.(*Null); ok {
+			// Null pointers are equal.
+			return newBool(intPredHolds(pred, 0, 0))
+		}
+	}
+	if _, ok := x.Type().(*types.PointerType); ok {
+		return f.foldScalarPtrCmp(pred, x, y)
+	}
+	return nil
+}
+
+// intPredHolds reports whether the given integer predicate holds, based on the
+// unsigned and signed comparison results of its operands (-1, 0 or +1).
+func intPredHolds(pred IntPred, ucmp, scmp int) bool {
+	switch pred {
+	case IntEQ:
+		return ucmp == 0
+	case IntNE:
+		return ucmp != 0
+	case IntUGT:
+		return ucmp > 0
+	case IntUGE:
+		return ucmp >= 0
+	case IntULT:
+		return ucmp < 0
+	case IntULE:
+		return ucmp <= 0
+	case IntSGT:
+		return scmp > 0
+	case IntSGE:
+		return scmp >= 0
+	case IntSLT:
+		return scmp < 0
+	case IntSLE:
+		return scmp <= 0
+	default:
+		panic(fmt.Errorf("support for integer predicate %v not yet implemented", pred))
+	}
+}
+
+// foldScalarFCmp compares the scalar operands x and y based on the given
+// floating-point predicate. It returns nil if the operands cannot be compared.
+func foldScalarFCmp(pred FloatPred, x, y Constant) Constant {
+	switch pred {
+	case FloatFalse:
+		return newBool(false)
+	case FloatTrue:
+		return newBool(true)
+	}
+	if isPoison(x) || isPoison(y) {
+		return NewPoison(types.I1)
+	}
+	_, xUndef := x.(*Undef)
+	_, yUndef := y.(*Undef)
+	if xUndef || yUndef {
+		return NewUndef(types.I1)
+	}
+	a, ok := x.(*Float)
+	if !ok {
+		return nil
+	}
+	b, ok := y.(*Float)
+	if !ok {
+		return nil
+	}
+	if a.NaN || b.NaN {
+		// Ordered predicates are false and unordered predicates are true if
+		// either operand is NaN.
+		switch pred {
+		case FloatOEQ, FloatOGT, FloatOGE, FloatOLT, FloatOLE, FloatONE, FloatORD:
+			return newBool(false)
+		default:
+			return newBool(true)
+		}
+	}
+	cmp := a.X.Cmp(b.X)
+	switch pred {
+	case FloatOEQ, FloatUEQ:
+		return newBool(cmp == 0)
+	case FloatOGT, FloatUGT:
+		return newBool(cmp > 0)
+	case FloatOGE, FloatUGE:
+		return newBool(cmp >= 0)
+	case FloatOLT, FloatULT:
+		return newBool(cmp < 0)
+	case FloatOLE, FloatULE:
+		return newBool(cmp <= 0)
+	case FloatONE, FloatUNE:
+		return newBool(cmp != 0)
+	case FloatORD:
+		return newBool(true)
+	case FloatUNO:
+		return newBool(false)
+	default:
+		panic(fmt.Errorf("support for floating-point predicate %v not yet implemented", pred))
+	}
+}
+
+// foldSelect folds the given select expression. A vector condition selects
+// element-wise. The expression is returned unchanged if it cannot be folded.
+func (f *folder) foldSelect(expr *ExprSelect) Constant {
+	cond, x, y := f.simplify(expr.Cond), f.simplify(expr.X), f.simplify(expr.Y)
+	if conds, ok := vectorElems(cond); ok {
+		xs, ok := vectorElems(x)
+		if !ok {
+			return expr
+		}
+		ys, ok := vectorElems(y)
+		if !ok || len(xs) != len(conds) || len(ys) != len(conds) {
+			return expr
+		}
+		elems := make([]Constant, len(conds))
+		for i := range conds {
+			elem := foldScalarSelect(conds[i], xs[i], ys[i])
+			if elem == nil {
+				return expr
+			}
+			elems[i] = elem
+		}
+		return newVector(x.Type(), elems)
+	}
+	if c := foldScalarSelect(cond, x, y); c != nil {
+		return c
+	}
+	return expr
+}
+
+// foldScalarSelect selects between x and y based on the given scalar
+// condition. It returns nil if the condition is not constant.
+func foldScalarSelect(cond, x, y Constant) Constant {
+	switch cond := cond.(type) {
+	case *Int:
+		if cond.X.Sign() != 0 {
+			return x
+		}
+		return y
+	case *Poison:
+		return NewPoison(x.Type())
+	case *Undef:
+		// select undef, poison, y -> y
+		// select undef, undef, y -> y
+		if isPoison(x) {
+			return y
+		}
+		if _, ok := x.(*Undef); ok && !isPoison(y) {
+			return y
+		}
+		return x
+	}
+	return nil
+}
+
+// --- [ Vector expressions ] --------------------------------------------------
+
+// foldExtractElement folds the given extractelement expression. Elements are
+// extracted from constant vectors, and traced through the insertelement and
+// shufflevector expressions of non-constant vectors. The expression is
+// returned unchanged if it cannot be folded.
+func (f *folder) foldExtractElement(expr *ExprExtractElement) Constant {
+	x, index := f.simplify(expr.X), f.simplify(expr.Index)
+	switch index := index.(type) {
+	case *Undef, *Poison:
+		// Undefined indices may be out of range.
+		return NewPoison(expr.Typ)
+	case *Int:
+		xt, ok := x.Type().(*types.VectorType)
+		if !ok {
+			return expr
+		}
+		i, ok := elemIndex(index, int(xt.Len))
+		if !ok {
+			// Out of range indices yield poison.
+			return NewPoison(expr.Typ)
+		}
+		if elem := f.vectorElem(x, i); elem != nil {
+			return elem
+		}
+	}
+	return expr
+}
+
+// foldInsertElement folds the given insertelement expression. The expression
+// is returned unchanged if it cannot be folded.
+func (f *folder) foldInsertElement(expr *ExprInsertElement) Constant {
+	x, elem, index := f.simplify(expr.X), f.simplify(expr.Elem), f.simplify(expr.Index)
+	switch index := index.(type) {
+	case *Undef, *Poison:
+		// Undefined indices may be out of range.
+		return NewPoison(x.Type())
+	case *Int:
+		xt, ok := x.Type().(*types.VectorType)
+		if !ok {
+			return expr
+		}
+		i, ok := elemIndex(index, int(xt.Len))
+		if !ok {
+			// Out of range indices yield poison.
+			return NewPoison(x.Type())
+		}
+		elems, ok := vectorElems(x)
+		if !ok {
+			return expr
+		}
+		newElems := make([]Constant, len(elems))
+		copy(newElems, elems)
+		newElems[i] = elem
+		return newVector(x.Type(), newElems)
+	}
+	return expr
+}
+
+// foldShuffleVector folds the given shufflevector expression. Shuffles of
+// known elements are folded to constant vectors, and identity shuffles are
+// folded to the shuffled vector. The expression is returned unchanged if it
+// cannot be folded.
+func (f *folder) foldShuffleVector(expr *ExprShuffleVector) Constant {
+	x, y, mask := f.simplify(expr.X), f.simplify(expr.Y), f.simplify(expr.Mask)
+	masks, ok := vectorElems(mask)
+	if !ok {
+		return expr
+	}
+	xt, ok := x.Type().(*types.VectorType)
+	if !ok {
+		return expr
+	}
+	n := int(xt.Len)
+	elems := make([]Constant, len(masks))
+	// Index of each element in the concatenation of x and y; or -1 if
+	// undefined.
+	lanes := make([]int, len(masks))
+	for i, m := range masks {
+		lanes[i] = -1
+		switch m := m.(type) {
+		case *Undef:
+			elems[i] = NewUndef(xt.Elem)
+		case *Poison:
+			elems[i] = NewPoison(xt.Elem)
+		case *Int:
+			j, ok := elemIndex(m, 2*n)
+			switch {
+			case !ok:
+				// Out of range mask elements are undefined.
+				elems[i] = NewUndef(xt.Elem)
+			case j < n:
+				lanes[i] = j
+				elems[i] = f.vectorElem(x, j)
+			default:
+				lanes[i] = j
+				elems[i] = f.vectorElem(y, j-n)
+			}
+		default:
+			return expr
+		}
+	}
+	if src := identityShuffle(x, y, lanes); src != nil {
+		return src
+	}
+	for _, elem := range elems {
+		if elem == nil {
+			return expr
+		}
+	}
+	return newVector(expr.Typ, elems)
+}
+
+// vectorElem returns the element at index i of the given vector; which is
+// either a constant vector or an insertelement or shufflevector expression. The
+// index must be in range. The returned constant is nil if the element is
+// unknown.
+func (f *folder) vectorElem(x Constant, i int) Constant {
+	if elems, ok := vectorElems(x); ok {
+		return elems[i]
+	}
+	xt, ok := x.Type().(*types.VectorType)
+	if !ok {
+		return nil
+	}
+	switch x := x.(type) {
+	case *ExprInsertElement:
+		index, ok := f.simplify(x.Index).(*Int)
+		if !ok {
+			return nil
+		}
+		if j, ok := elemIndex(index, int(xt.Len)); ok && j == i {
+			return f.simplify(x.Elem)
+		}
+		return f.vectorElem(f.simplify(x.X), i)
+	case *ExprShuffleVector:
+		masks, ok := vectorElems(f.simplify(x.Mask))
+		if !ok {
+			return nil
+		}
+		n := int(x.X.Type().(*types.VectorType).Len)
+		switch m := masks[i].(type) {
+		case *Undef:
+			return NewUndef(xt.Elem)
+		case *Poison:
+			return NewPoison(xt.Elem)
+		case *Int:
+			j, ok := elemIndex(m, 2*n)
+			switch {
+			case !ok:
+				return NewUndef(xt.Elem)
+			case j < n:
+				return f.vectorElem(f.simplify(x.X), j)
+			default:
+				return f.vectorElem(f.simplify(x.Y), j-n)
+			}
+		}
+	}
+	return nil
+}
+
+// --- [ Aggregate expressions ] -----------------------------------------------
+
+// foldExtractValue folds the given extractvalue expression. The expression is
+// returned unchanged if it cannot be folded.
+func (f *folder) foldExtractValue(expr *ExprExtractValue) Constant {
+	c := f.simplify(expr.X)
+	for _, index := range expr.Indices {
+		elem, ok := aggregateElem(c, index)
+		if !ok {
+			return expr
+		}
+		c = elem
+	}
+	return c
+}
+
+// foldInsertValue folds the given insertvalue expression. The expression is
+// returned unchanged if it cannot be folded.
+func (f *folder) foldInsertValue(expr *ExprInsertValue) Constant {
+	c, ok := insertAggregateElem(f.simplify(expr.X), f.simplify(expr.Elem), expr.Indices)
+	if !ok {
+		return expr
+	}
+	return c
+}
+
+// aggregateElem returns the element at the given index of the aggregate
+// constant c. The boolean return value indicates success.
+func aggregateElem(c Constant, index int64) (Constant, bool) {
+	if index < 0 {
+		return nil, false
+	}
+	switch c := c.(type) {
+	case *Struct:
+		if index < int64(len(c.Fields)) {
+			return c.Fields[index], true
+		}
+	case *Array:
+		if index < int64(len(c.Elems)) {
+			return c.Elems[index], true
+		}
+	case *CharArray:
+		if index < int64(len(c.X)) {
+			return NewInt(int64(c.X[index]), types.I8), true
+		}
+	case *ZeroInitializer:
+		if t, err := aggregateElemType(c.Typ, []int64{index}); err == nil {
+			return zeroValue(t), true
+		}
+	case *Undef:
+		if t, err := aggregateElemType(c.Typ, []int64{index}); err == nil {
+			return NewUndef(t), true
+		}
+	case *Poison:
+		if t, err := aggregateElemType(c.Typ, []int64{index}); err == nil {
+			return NewPoison(t), true
+		}
+	}
+	return nil, false
+}
+
+// insertAggregateElem returns a copy of the aggregate constant c with the
+// element at the given indices replaced by elem. The boolean return value
+// indicates success.
+func insertAggregateElem(c, elem Constant, indices []int64) (Constant, bool) {
+	if len(indices) == 0 {
+		return elem, true
+	}
+	var elems []Constant
+	switch t := c.Type().(type) {
+	case *types.StructType:
+		for i := range t.Fields {
+			e, ok := aggregateElem(c, int64(i))
+			if !ok {
+				return nil, false
+			}
+			elems = append(elems, e)
+		}
+	case *types.ArrayType:
+		for i := int64(0); i < t.Len; i++ {
+			e, ok := aggregateElem(c, i)
+			if !ok {
+				return nil, false
+			}
+			elems = append(elems, e)
+		}
+	default:
+		return nil, false
+	}
+	index := indices[0]
+	if index < 0 || index >= int64(len(elems)) {
+		return nil, false
+	}
+	e, ok := insertAggregateElem(elems[index], elem, indices[1:])
+	if !ok {
+		return nil, false
+	}
+	elems[index] = e
+	switch t := c.Type().(type) {
+	case *types.StructType:
+		return &Struct{Typ: t, Fields: elems}, true
+	case *types.ArrayType:
+		return &Array{Typ: t, Elems: elems}, true
+	}
+	return nil, false
+}
+
+// --- [ Memory expressions ] --------------------------------------------------
+
+// foldGetElementPtr folds the given getelementptr expression. Address
+// computations with zero indices are folded to the source address. Given a
+// data layout, address computations on null pointers with a zero offset are
+// folded to null, and nested address computations on global variables are
+// flattened to base+offset form `getelementptr (i8, i8* base, iN offset)`.
+// A single address computation on a global variable, such as
+// `getelementptr ([4 x i32], [4 x i32]* @x, i64 0, i64 1)`, is already in
+// canonical form and is left as is. The expression is otherwise returned
+// unchanged.
+func (f *folder) foldGetElementPtr(expr *ExprGetElementPtr) Constant {
+	src := f.simplify(expr.Src)
+	switch src.(type) {
+	case *Undef:
+		return NewUndef(expr.Typ)
+	case *Poison:
+		return NewPoison(expr.Typ)
+	}
+	indices := make([]*Int, len(expr.Indices))
+	zero := true
+	for i, index := range expr.Indices {
+		index, ok := f.simplify(index).(*Int)
+		if !ok {
+			return expr
+		}
+		if index.X.Sign() != 0 {
+			zero = false
+		}
+		indices[i] = index
+	}
+	// getelementptr (T, T* x, 0, ...) -> x, if of type T*.
+	if zero && src.Type().Equal(expr.Typ) {
+		return src
+	}
+	base, offset, ok := f.gepOffset(expr.Elem, src, indices)
+	if !ok {
+		return expr
+	}
+	switch {
+	case offset == 0:
+		if _, ok := base.(*Null); ok {
+			return NewNull(expr.Typ)
+		}
+		if c := f.pointerCast(base, expr.Typ); c != nil {
+			return c
+		}
+	case isGlobal(base):
+		// Flatten nested address computations; e.g.
+		//
+		//    getelementptr (i32, i32* getelementptr ([4 x i32], [4 x i32]* @x, i64 0, i64 1), i64 2)
+		//
+		// is folded to
+		//
+		//    bitcast (i8* getelementptr (i8, i8* bitcast ([4 x i32]* @x to i8*), i64 12) to i32*)
+		if _, ok := stripPointerCasts(src).(*ExprGetElementPtr); ok {
+			if c := f.addrAt(base, offset, expr.Typ); c != nil {
+				return c
+			}
+		}
+	}
+	return expr
+}
+
+// gepOffset returns the base address and byte offset of the address computed
+// by a getelementptr expression with the given source element type, simplified
+// source address and constant indices. The boolean return value indicates
+// success.
+func (f *folder) gepOffset(elem types.Type, src Constant, indices []*Int) (base Constant, offset int64, ok bool) {
+	if f.dl == nil || !isSized(elem) {
+		return nil, 0, false
+	}
+	base, offset, ok = f.addrOffset(src)
+	if !ok {
+		return nil, 0, false
+	}
+	is := make([]int64, len(indices))
+	for i, index := range indices {
+		x := index.Signed()
+		if !x.IsInt64() {
+			return nil, 0, false
+		}
+		is[i] = x.Int64()
+	}
+	return base, offset + f.dl.IndexedOffset(elem, is), true
+}
+
+// addrOffset returns the base address and byte offset of the given simplified
+// constant pointer. The base address is either a null pointer or the address
+// of a global variable or function. The boolean return value indicates success.
+func (f *folder) addrOffset(c Constant) (base Constant, offset int64, ok bool) {
+	switch c := c.(type) {
+	case *Null:
+		return c, 0, true
+	case *ExprBitCast:
+		return f.addrOffset(f.simplify(c.From))
+	case *ExprGetElementPtr:
+		indices := make([]*Int, len(c.Indices))
+		for i, index := range c.Indices {
+			index, ok := f.simplify(index).(*Int)
+			if !ok {
+				return nil, 0, false
+			}
+			indices[i] = index
+		}
+		return f.gepOffset(c.Elem, f.simplify(c.Src), indices)
+	}
+	if isGlobal(c) {
+		return c, 0, true
+	}
+	return nil, 0, false
+}
+
+// addrAt returns a constant pointer of the given type to the given byte offset
+// from the given base address, in base+offset form. It returns nil if the
+// address space of the base address differs from the pointer type.
+func (f *folder) addrAt(base Constant, offset int64, t *types.PointerType) Constant {
+	addrSpace := base.Type().(*types.PointerType).AddrSpace
+	i8Ptr := types.NewPointer(types.I8)
+	i8Ptr.AddrSpace = addrSpace
+	src := f.pointerCast(base, i8Ptr)
+	if src == nil {
+		return nil
+	}
+	index := newInt(types.NewInt(int(f.dl.Pointer(addrSpace).IndexSize)), big.NewInt(offset))
+	gep := NewGetElementPtr(src, index)
+	gep.Typ = i8Ptr
+	return f.pointerCast(gep, t)
+}
+
+// pointerCast returns the given constant pointer converted to the given
+// pointer type. It returns nil if the address spaces of the pointer types
+// differ.
+func (f *folder) pointerCast(c Constant, t *types.PointerType) Constant {
+	if c.Type().Equal(t) {
+		return c
+	}
+	if c.Type().(*types.PointerType).AddrSpace != t.AddrSpace {
+		return nil
+	}
+	return NewBitCast(c, t)
+}
+
+// foldScalarPtrCmp compares the constant pointers x and y based on the given
+// integer predicate. Only equality comparisons of addresses with known base
+// addresses are folded; it returns nil otherwise.
+func (f *folder) foldScalarPtrCmp(pred IntPred, x, y Constant) Constant {
+	if pred != IntEQ && pred != IntNE {
+		return nil
+	}
+	xBase, xOffset, ok := f.addrOffset(x)
+	if !ok {
+		return nil
+	}
+	yBase, yOffset, ok := f.addrOffset(y)
+	if !ok {
+		return nil
+	}
+	_, xNull := xBase.(*Null)
+	_, yNull := yBase.(*Null)
+	var eq bool
+	switch {
+	case xBase == yBase || (xNull && yNull):
+		eq = xOffset == yOffset
+	case f.inBounds(xBase, xOffset) && f.inBounds(yBase, yOffset):
+		// Distinct objects have distinct addresses, and no object is allocated
+		// at null.
+		eq = false
+	default:
+		return nil
+	}
+	return newBool(eq == (pred == IntEQ))
+}
+
+// inBounds reports whether the given byte offset from the given base address
+// is known to be within the bounds of the object at the base address; in
+// which case the address differs from the addresses of all other objects.
+//
+// One-past-the-end addresses are not in bounds, as they may coincide with the
+// address of another object. Null pointers are only in bounds with offset 0
+// in the default address space.
+func (f *folder) inBounds(base Constant, offset int64) bool {
+	t := base.Type().(*types.PointerType)
+	if _, ok := base.(*Null); ok {
+		return offset == 0 && t.AddrSpace == 0
+	}
+	if _, ok := t.Elem.(*types.FuncType); ok {
+		return offset == 0
+	}
+	if !isSized(t.Elem) {
+		return false
+	}
+	if f.dl == nil {
+		return offset == 0 && !isZeroSized(t.Elem)
+	}
+	return offset >= 0 && offset < f.dl.TypeSize(t.Elem)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// newInt returns a new integer constant of the given type, with the value of x
+// wrapped around to the bit size of the type.
+//
+// Integers are represented in signed form (e.g. -1 for i8 255), except for
+// boolean integers (e.g. 1 for true).
+func newInt(t *types.IntType, x *big.Int) *Int {
+	z := wrapUnsigned(x, t.Size)
+	if t.Size > 1 && z.Bit(t.Size-1) == 1 {
+		z.Sub(z, new(big.Int).Lsh(big.NewInt(1), uint(t.Size)))
+	}
+	return &Int{Typ: t, X: z}
+}
+
+// newBool returns a new boolean integer constant of the given value.
+func newBool(x bool) *Int {
+	if x {
+		return NewInt(1, types.I1)
+	}
+	return NewInt(0, types.I1)
+}
+
+// wrapUnsigned returns x truncated to the given bit size, in unsigned form.
+func wrapUnsigned(x *big.Int, size int) *big.Int {
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(size))
+	mask.Sub(mask, big.NewInt(1))
+	// And operates on the two's complement representation of negative x.
+	return new(big.Int).And(x, mask)
+}
+
+// newFloat returns a new floating-point constant of the given half, float or
+// double type, with the value of x rounded to the precision of the type. It
+// returns nil if x is NaN, as the NaN payload of folded operations is
+// unspecified.
+func newFloat(t *types.FloatType, x float64) Constant {
+	if math.IsNaN(x) {
+		return nil
+	}
+	return NewFloat(x, t)
+}
+
+// newBigFloat returns a new floating-point constant of the given type, with
+// the value computed by f rounded to the nearest value representable by the
+// type. It returns nil if the result is NaN.
+func newBigFloat(t *types.FloatType, f func(z *big.Float) *big.Float) (c Constant) {
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(big.ErrNaN); !ok {
+				panic(e)
+			}
+			c = nil
+		}
+	}()
+	// Rounding the result of an arithmetic operation first to a precision of at
+	// least 2p+2 bits and then to the precision of the type is equivalent to
+	// rounding it directly to the precision of the type.
+	z := new(big.Float).SetPrec(2*floatPrec(t.Kind) + 2)
+	return newFloatFromBig(t, f(z))
+}
+
+// floatPrec returns the precision in bits of the significand of the given
+// floating-point kind.
+func floatPrec(kind types.FloatKind) uint {
+	switch kind {
+	case types.FloatKindIEEE_16:
+		return 11
+	case types.FloatKindIEEE_32:
+		return 24
+	case types.FloatKindIEEE_64:
+		return 53
+	case types.FloatKindDoubleExtended_80:
+		return 64
+	case types.FloatKindIEEE_128:
+		return 113
+	case types.FloatKindDoubleDouble_128:
+		return 106
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	}
+}
+
+// floatBits returns the size in bits of the given floating-point kind.
+func floatBits(kind types.FloatKind) int64 {
+	switch kind {
+	case types.FloatKindIEEE_16:
+		return 16
+	case types.FloatKindIEEE_32:
+		return 32
+	case types.FloatKindIEEE_64:
+		return 64
+	case types.FloatKindDoubleExtended_80:
+		return 80
+	case types.FloatKindIEEE_128, types.FloatKindDoubleDouble_128:
+		return 128
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	}
+}
+
+// zeroValue returns the zero value of the given type.
+func zeroValue(t types.Type) Constant {
+	switch t := t.(type) {
+	case *types.IntType:
+		return &Int{Typ: t, X: new(big.Int)}
+	case *types.FloatType:
+		return &Float{Typ: t, X: new(big.Float)}
+	case *types.PointerType:
+		return NewNull(t)
+	default:
+		return NewZeroInitializer(t)
+	}
+}
+
+// vectorElems returns the elements of the given vector constant. The boolean
+// return value indicates whether c is a vector constant, zeroinitializer,
+// undefined value or poison value of vector type.
+func vectorElems(c Constant) ([]Constant, bool) {
+	switch c := c.(type) {
+	case *Vector:
+		return c.Elems, true
+	case *ZeroInitializer:
+		if t, ok := c.Typ.(*types.VectorType); ok {
+			elems := make([]Constant, t.Len)
+			for i := range elems {
+				elems[i] = zeroValue(t.Elem)
+			}
+			return elems, true
+		}
+	case *Undef:
+		if t, ok := c.Typ.(*types.VectorType); ok {
+			elems := make([]Constant, t.Len)
+			for i := range elems {
+				elems[i] = NewUndef(t.Elem)
+			}
+			return elems, true
+		}
+	case *Poison:
+		if t, ok := c.Typ.(*types.VectorType); ok {
+			elems := make([]Constant, t.Len)
+			for i := range elems {
+				elems[i] = NewPoison(t.Elem)
+			}
+			return elems, true
+		}
+	}
+	return nil, false
+}
+
+// newVector returns a new vector constant of the given type and elements.
+func newVector(t types.Type, elems []Constant) *Vector {
+	vt, ok := t.(*types.VectorType)
+	if !ok {
+		panic(fmt.Errorf("invalid vector constant type; expected *types.VectorType, got %T", t))
+	}
+	return &Vector{Typ: vt, Elems: elems}
+}
+
+// elemIndex returns the integer value of the given element index. The boolean
+// return value is false if the index is out of range for the given number of
+// elements.
+func elemIndex(index *Int, n int) (int, bool) {
+	i := index.Unsigned()
+	if i.Cmp(big.NewInt(int64(n))) >= 0 {
+		return 0, false
+	}
+	return int(i.Int64()), true
+}
+
+// identityShuffle returns the vector selected by a shufflevector of x and y
+// with the given mask element indices into the concatenation of x and y, where
+// -1 denotes undefined elements; or nil if the mask is not an identity mask of
+// either x or y.
+func identityShuffle(x, y Constant, lanes []int) Constant {
+	n := int(x.Type().(*types.VectorType).Len)
+	if len(lanes) != n {
+		return nil
+	}
+	var src Constant
+	for i, j := range lanes {
+		var s Constant
+		switch j {
+		case -1:
+			continue
+		case i:
+			s = x
+		case n + i:
+			s = y
+		default:
+			return nil
+		}
+		if src != nil && src != s {
+			return nil
+		}
+		src = s
+	}
+	return src
+}
+
+// isPoison reports whether the given constant is a poison value.
+func isPoison(c Constant) bool {
+	_, ok := c.(*Poison)
+	return ok
+}
+
+// isGlobal reports whether the given constant is the address of a global
+// variable or function.
+func isGlobal(c Constant) bool {
+	if _, ok := c.(value.Named); !ok {
+		return false
+	}
+	_, ok := c.Type().(*types.PointerType)
+	return ok
+}
+
+// stripPointerCasts returns the given constant with any outer bitcasts
+// removed.
+func stripPointerCasts(c Constant) Constant {
+	for {
+		expr, ok := c.(*ExprBitCast)
+		if !ok {
+			return c
+		}
+		c = expr.From
+	}
+}
+
+// isSized reports whether values of the given type have a size in memory.
+func isSized(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.IntType, *types.FloatType, *types.PointerType:
+		return true
+	case *types.VectorType:
+		return isSized(t.Elem)
+	case *types.ArrayType:
+		return isSized(t.Elem)
+	case *types.StructType:
+		if t.Opaque {
+			return false
+		}
+		for _, field := range t.Fields {
+			if !isSized(field) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isZeroSized reports whether values of the given sized type have a size of
+// zero bytes in memory.
+func isZeroSized(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.ArrayType:
+		return t.Len == 0 || isZeroSized(t.Elem)
+	case *types.StructType:
+		for _, field := range t.Fields {
+			if !isZeroSized(field) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
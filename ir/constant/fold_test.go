@@ -0,0 +1,381 @@
+package constant_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/datalayout"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestSimplify(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	i32 := func(x int64) *constant.Int { return constant.NewInt(x, types.I32) }
+	f32 := func(x float64) *constant.Float { return constant.NewFloat(x, types.Float) }
+	f64 := func(x float64) *constant.Float { return constant.NewFloat(x, types.Double) }
+	undef32 := constant.NewUndef(types.I32)
+	poison32 := constant.NewPoison(types.I32)
+	v4 := types.NewVector(types.I32, 4)
+	vec := constant.NewVector(i32(1), i32(2), i32(3), i32(4))
+	i8ptr := types.NewPointer(types.I8)
+	null := constant.NewNull(i8ptr)
+	st := types.NewStruct(types.I32, types.Double)
+	golden := []struct {
+		in   constant.Expr
+		want string
+	}{
+		// Integer wrap-around.
+		{in: constant.NewAdd(i8(127), i8(1)), want: "-128"},
+		{in: constant.NewSub(i8(0), i8(1)), want: "-1"},
+		{in: constant.NewMul(i8(16), i8(16)), want: "0"},
+		{in: constant.NewUDiv(i8(-1), i8(2)), want: "127"},
+		{in: constant.NewSDiv(i8(-7), i8(2)), want: "-3"},
+		{in: constant.NewURem(i8(-1), i8(10)), want: "5"},
+		{in: constant.NewSRem(i8(-7), i8(2)), want: "-1"},
+		{in: constant.NewShl(i8(1), i8(7)), want: "-128"},
+		{in: constant.NewLShr(i8(-128), i8(7)), want: "1"},
+		{in: constant.NewAShr(i8(-128), i8(7)), want: "-1"},
+		{in: constant.NewAnd(i8(-1), i8(15)), want: "15"},
+		{in: constant.NewOr(i8(-128), i8(1)), want: "-127"},
+		{in: constant.NewXor(constant.True, constant.True), want: "false"},
+		{in: constant.NewAdd(constant.True, constant.True), want: "false"},
+		// Undefined behaviour and oversized shifts yield poison.
+		{in: constant.NewUDiv(i32(1), i32(0)), want: "poison"},
+		{in: constant.NewSDiv(i8(-128), i8(-1)), want: "poison"},
+		{in: constant.NewShl(i8(1), i8(8)), want: "poison"},
+		// Nested expressions.
+		{in: constant.NewMul(constant.NewAdd(i32(2), i32(3)), i32(4)), want: "20"},
+		// Undefined values.
+		{in: constant.NewAdd(undef32, i32(1)), want: "undef"},
+		{in: constant.NewAnd(undef32, i32(1)), want: "0"},
+		{in: constant.NewOr(undef32, i32(1)), want: "-1"},
+		{in: constant.NewUDiv(i32(1), undef32), want: "poison"},
+		{in: constant.NewZExt(constant.NewUndef(types.I8), types.I32), want: "0"},
+		// Poison values.
+		{in: constant.NewAdd(poison32, i32(1)), want: "poison"},
+		{in: constant.NewMul(poison32, i32(0)), want: "poison"},
+		{in: constant.NewAnd(poison32, undef32), want: "poison"},
+		{in: constant.NewZExt(constant.NewPoison(types.I8), types.I32), want: "poison"},
+		{in: constant.NewICmp(constant.IntEQ, poison32, i32(1)), want: "poison"},
+		{in: constant.NewFCmp(constant.FloatFalse, constant.NewPoison(types.Double), f64(1)), want: "false"},
+		{in: constant.NewSelect(constant.NewPoison(types.I1), i32(1), i32(2)), want: "poison"},
+		{in: constant.NewSelect(constant.NewUndef(types.I1), poison32, i32(2)), want: "2"},
+		{in: constant.NewAdd(vec, constant.NewVector(i32(1), poison32, i32(1), i32(1))), want: "<i32 2, i32 poison, i32 4, i32 5>"},
+		// Floating-point operations.
+		{in: constant.NewFAdd(f64(0.1), f64(0.2)), want: "0.30000000000000004"},
+		{in: constant.NewFAdd(f32(0.1), f32(0.2)), want: "0.30000001192092896"},
+		{in: constant.NewFDiv(f64(1), f64(4)), want: "0.25"},
+		{in: constant.NewFRem(f64(7), f64(4)), want: "3.0"},
+		{in: constant.NewFAdd(constant.NewFloat(1, types.X86_FP80), constant.NewFloat(0x1p-63, types.X86_FP80)), want: "0xK3FFF8000000000000001"},
+		{in: constant.NewFDiv(constant.NewFloat(1, types.FP128), constant.NewFloat(10, types.FP128)), want: "0xL999999999999999A3FFB999999999999"},
+		// Vector operations.
+		{in: constant.NewAdd(vec, vec), want: "<i32 2, i32 4, i32 6, i32 8>"},
+		{in: constant.NewMul(vec, constant.NewZeroInitializer(v4)), want: "<i32 0, i32 0, i32 0, i32 0>"},
+		// Conversions.
+		{in: constant.NewTrunc(i32(0x1FF), types.I8), want: "-1"},
+		{in: constant.NewZExt(i8(-1), types.I32), want: "255"},
+		{in: constant.NewSExt(i8(-1), types.I32), want: "-1"},
+		{in: constant.NewFPTrunc(f64(0.1), types.Float), want: "0.10000000149011612"},
+		{in: constant.NewFPToSI(f64(-3.9), types.I32), want: "-3"},
+		{in: constant.NewFPToUI(f64(256), types.I8), want: "poison"},
+		{in: constant.NewFPToSI(f64(math.Inf(-1)), types.I32), want: "poison"},
+		{in: constant.NewUIToFP(i8(-1), types.Double), want: "255.0"},
+		{in: constant.NewSIToFP(i8(-1), types.Double), want: "-1.0"},
+		{in: constant.NewBitCast(f32(1), types.I32), want: "1065353216"},
+		{in: constant.NewBitCast(i32(0x40490FDB), types.Float), want: "3.1415927410125732"},
+		{in: constant.NewPtrToInt(null, types.I64), want: "0"},
+		{in: constant.NewIntToPtr(i32(0), i8ptr), want: "null"},
+		{in: constant.NewBitCast(null, types.NewPointer(types.I32)), want: "null"},
+		// Comparisons and select.
+		{in: constant.NewICmp(constant.IntULT, i8(-1), i8(1)), want: "false"},
+		{in: constant.NewICmp(constant.IntSLT, i8(-1), i8(1)), want: "true"},
+		{in: constant.NewICmp(constant.IntEQ, null, null), want: "true"},
+		{in: constant.NewICmp(constant.IntSGT, vec, constant.NewZeroInitializer(v4)), want: "<i1 true, i1 true, i1 true, i1 true>"},
+		{in: constant.NewFCmp(constant.FloatOLT, f64(1), f64(2)), want: "true"},
+		{in: constant.NewFCmp(constant.FloatUNO, f64(1), f64(2)), want: "false"},
+		{in: constant.NewFCmp(constant.FloatUNE, f64(math.NaN()), f64(2)), want: "true"},
+		{in: constant.NewFCmp(constant.FloatOEQ, f64(math.NaN()), f64(math.NaN())), want: "false"},
+		{in: constant.NewSelect(constant.False, i32(1), i32(2)), want: "2"},
+		// Vector expressions.
+		{in: constant.NewExtractElement(vec, i32(2)), want: "3"},
+		{in: constant.NewExtractElement(vec, i32(4)), want: "poison"},
+		{in: constant.NewExtractElement(vec, undef32), want: "poison"},
+		{in: constant.NewInsertElement(vec, i32(0), i32(0)), want: "<i32 0, i32 2, i32 3, i32 4>"},
+		{in: constant.NewShuffleVector(vec, vec, constant.NewVector(i32(7), i32(0), constant.NewUndef(types.I32))), want: "<i32 4, i32 1, i32 undef>"},
+		// Aggregate expressions.
+		{in: constant.NewExtractValue(constant.NewStruct(i32(1), f64(2)), []int64{1}), want: "2.0"},
+		{in: constant.NewExtractValue(constant.NewZeroInitializer(st), []int64{0}), want: "0"},
+		{in: constant.NewExtractValue(constant.NewCharArrayFromString("hi"), []int64{1}), want: "105"},
+		{in: constant.NewInsertValue(constant.NewZeroInitializer(st), i32(42), []int64{0}), want: "{ i32 42, double 0.0 }"},
+		// Memory expressions.
+		{in: constant.NewGetElementPtr(null, i32(0)), want: "null"},
+	}
+	for _, g := range golden {
+		before := g.in.Ident()
+		got := g.in.Simplify()
+		if got.Ident() != g.want {
+			t.Errorf("simplification of %q mismatch; expected %q, got %q", before, g.want, got.Ident())
+		}
+		if !got.Type().Equal(g.in.Type()) {
+			t.Errorf("type of simplified %q mismatch; expected %v, got %v", before, g.in.Type(), got.Type())
+		}
+	}
+	// Expressions which cannot be folded are returned unchanged.
+	inf := f64(math.Inf(1))
+	nan := constant.NewFSub(inf, inf)
+	addr := constant.NewPtrToInt(ir.NewGlobalDecl("x", types.I32), types.I64)
+	for _, expr := range []constant.Expr{nan, addr} {
+		if got := expr.Simplify(); got != expr {
+			t.Errorf("expected unchanged expression, got %T", got)
+		}
+	}
+}
+
+func TestSimplifyFlags(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	add := func(x, y int64, nuw, nsw bool) constant.Expr {
+		expr := constant.NewAdd(i8(x), i8(y))
+		expr.NUW, expr.NSW = nuw, nsw
+		return expr
+	}
+	sub := func(x, y int64, nuw, nsw bool) constant.Expr {
+		expr := constant.NewSub(i8(x), i8(y))
+		expr.NUW, expr.NSW = nuw, nsw
+		return expr
+	}
+	mul := func(x, y int64, nuw, nsw bool) constant.Expr {
+		expr := constant.NewMul(i8(x), i8(y))
+		expr.NUW, expr.NSW = nuw, nsw
+		return expr
+	}
+	shl := func(x, y int64, nuw, nsw bool) constant.Expr {
+		expr := constant.NewShl(i8(x), i8(y))
+		expr.NUW, expr.NSW = nuw, nsw
+		return expr
+	}
+	udiv := func(x, y int64) constant.Expr {
+		expr := constant.NewUDiv(i8(x), i8(y))
+		expr.Exact = true
+		return expr
+	}
+	sdiv := func(x, y int64) constant.Expr {
+		expr := constant.NewSDiv(i8(x), i8(y))
+		expr.Exact = true
+		return expr
+	}
+	lshr := func(x, y int64) constant.Expr {
+		expr := constant.NewLShr(i8(x), i8(y))
+		expr.Exact = true
+		return expr
+	}
+	ashr := func(x, y int64) constant.Expr {
+		expr := constant.NewAShr(i8(x), i8(y))
+		expr.Exact = true
+		return expr
+	}
+	golden := []struct {
+		in    constant.Expr
+		ident string
+		want  string
+	}{
+		// Overflow flags.
+		{in: add(127, 1, true, false), ident: "add nuw (i8 127, i8 1)", want: "-128"},
+		{in: add(127, 1, false, true), ident: "add nsw (i8 127, i8 1)", want: "poison"},
+		{in: add(-1, 1, true, true), ident: "add nuw nsw (i8 -1, i8 1)", want: "poison"},
+		{in: add(-1, 1, false, true), ident: "add nsw (i8 -1, i8 1)", want: "0"},
+		{in: sub(0, 1, true, false), ident: "sub nuw (i8 0, i8 1)", want: "poison"},
+		{in: sub(0, 1, false, true), ident: "sub nsw (i8 0, i8 1)", want: "-1"},
+		{in: mul(16, 16, true, false), ident: "mul nuw (i8 16, i8 16)", want: "poison"},
+		{in: mul(-1, -1, true, true), ident: "mul nuw nsw (i8 -1, i8 -1)", want: "poison"},
+		{in: mul(-1, -1, false, true), ident: "mul nsw (i8 -1, i8 -1)", want: "1"},
+		{in: shl(1, 7, true, false), ident: "shl nuw (i8 1, i8 7)", want: "-128"},
+		{in: shl(1, 7, false, true), ident: "shl nsw (i8 1, i8 7)", want: "poison"},
+		{in: shl(-1, 7, false, true), ident: "shl nsw (i8 -1, i8 7)", want: "-128"},
+		// Exact flag.
+		{in: udiv(6, 3), ident: "udiv exact (i8 6, i8 3)", want: "2"},
+		{in: udiv(7, 3), ident: "udiv exact (i8 7, i8 3)", want: "poison"},
+		{in: sdiv(-6, 3), ident: "sdiv exact (i8 -6, i8 3)", want: "-2"},
+		{in: sdiv(-7, 2), ident: "sdiv exact (i8 -7, i8 2)", want: "poison"},
+		{in: lshr(4, 2), ident: "lshr exact (i8 4, i8 2)", want: "1"},
+		{in: lshr(5, 2), ident: "lshr exact (i8 5, i8 2)", want: "poison"},
+		{in: ashr(-128, 7), ident: "ashr exact (i8 -128, i8 7)", want: "-1"},
+		{in: ashr(-127, 7), ident: "ashr exact (i8 -127, i8 7)", want: "poison"},
+	}
+	for _, g := range golden {
+		if got := g.in.Ident(); got != g.ident {
+			t.Errorf("identifier mismatch; expected %q, got %q", g.ident, got)
+		}
+		if got := g.in.Simplify(); got.Ident() != g.want {
+			t.Errorf("simplification of %q mismatch; expected %q, got %q", g.ident, g.want, got.Ident())
+		}
+	}
+	// Flags distinguish otherwise equal constant expressions.
+	if constant.Equal(add(1, 2, false, false), add(1, 2, true, false)) {
+		t.Errorf("expected add and add nuw to be distinct")
+	}
+	if !constant.Equal(udiv(6, 3), udiv(6, 3)) {
+		t.Errorf("expected equal udiv exact expressions")
+	}
+}
+
+func TestSimplifyVector(t *testing.T) {
+	i32 := func(x int64) *constant.Int { return constant.NewInt(x, types.I32) }
+	const undef = constant.UndefMaskElem
+	vec := constant.NewVector(i32(1), i32(2), i32(3), i32(4))
+	splat := constant.NewSplat(i32(7), 4)
+	// Vector which cannot be folded.
+	addr := constant.NewPtrToInt(ir.NewGlobalDecl("x", types.I32), types.I64)
+	v := constant.NewBitCast(addr, types.NewVector(types.I32, 2))
+	ins := constant.NewInsertElement(v, i32(42), i32(1))
+	golden := []struct {
+		in   constant.Expr
+		want string
+	}{
+		// Shuffles of constant vectors.
+		{in: constant.NewShuffleVector(vec, splat, constant.NewShuffleMask(0, 4, 1, 5)), want: "<i32 1, i32 7, i32 2, i32 7>"},
+		{in: constant.NewShuffleVector(vec, vec, constant.NewShuffleMask(3, undef)), want: "<i32 4, i32 undef>"},
+		{in: constant.NewShuffleVector(vec, vec, constant.NewZeroInitializer(types.NewVector(types.I32, 8))), want: "<i32 1, i32 1, i32 1, i32 1, i32 1, i32 1, i32 1, i32 1>"},
+		{in: constant.NewShuffleVector(constant.NewSplat(constant.NewFloat(1.5, types.Double), 2), constant.NewZeroInitializer(types.NewVector(types.Double, 2)), constant.NewShuffleMask(0, 2, 1)), want: "<double 1.5, double 0.0, double 1.5>"},
+		// Splat of an inserted element.
+		{in: constant.NewShuffleVector(constant.NewInsertElement(constant.NewUndef(types.NewVector(types.I32, 4)), i32(5), i32(0)), constant.NewUndef(types.NewVector(types.I32, 4)), constant.NewShuffleMask(0, 0, 0)), want: "<i32 5, i32 5, i32 5>"},
+		// Identity shuffles of non-constant vectors.
+		{in: constant.NewShuffleVector(v, v, constant.NewShuffleMask(0, undef)), want: v.Ident()},
+		{in: constant.NewShuffleVector(constant.NewVector(i32(1), i32(2)), v, constant.NewShuffleMask(2, 3)), want: v.Ident()},
+		{in: constant.NewShuffleVector(v, v, constant.NewShuffleMask(undef, undef)), want: "<i32 undef, i32 undef>"},
+		// Elements of insertelement and shufflevector chains.
+		{in: constant.NewExtractElement(ins, i32(1)), want: "42"},
+		{in: constant.NewExtractElement(constant.NewInsertElement(ins, i32(1), i32(0)), i32(1)), want: "42"},
+		{in: constant.NewExtractElement(ins, i32(2)), want: "poison"},
+		{in: constant.NewExtractElement(constant.NewShuffleVector(v, ins, constant.NewShuffleMask(3, 0, undef)), i32(0)), want: "42"},
+		{in: constant.NewExtractElement(constant.NewShuffleVector(v, ins, constant.NewShuffleMask(3, 0, undef)), i32(2)), want: "undef"},
+		{in: constant.NewInsertElement(v, i32(1), i32(2)), want: "poison"},
+	}
+	for _, g := range golden {
+		before := g.in.Ident()
+		got := g.in.Simplify()
+		if got.Ident() != g.want {
+			t.Errorf("simplification of %q mismatch; expected %q, got %q", before, g.want, got.Ident())
+		}
+		if !got.Type().Equal(g.in.Type()) {
+			t.Errorf("type of simplified %q mismatch; expected %v, got %v", before, g.in.Type(), got.Type())
+		}
+	}
+	// Elements which are not known are returned unchanged.
+	for _, expr := range []constant.Expr{
+		constant.NewExtractElement(ins, i32(0)),
+		constant.NewShuffleVector(v, ins, constant.NewShuffleMask(1, 3)),
+	} {
+		if got := expr.Simplify(); got != expr {
+			t.Errorf("expected unchanged expression, got %T", got)
+		}
+	}
+	// Shuffle mask indices.
+	indices, ok := constant.ShuffleMaskIndices(constant.NewShuffleMask(2, undef, 0))
+	if !ok || len(indices) != 3 || indices[0] != 2 || indices[1] != undef || indices[2] != 0 {
+		t.Errorf("shuffle mask indices mismatch; expected [2 %d 0], got %v", undef, indices)
+	}
+	if _, ok := constant.ShuffleMaskIndices(v); ok {
+		t.Errorf("expected non-constant shuffle mask to have unknown indices")
+	}
+}
+
+func TestFold(t *testing.T) {
+	i32 := func(x int64) *constant.Int { return constant.NewInt(x, types.I32) }
+	i64 := func(x int64) *constant.Int { return constant.NewInt(x, types.I64) }
+	x := ir.NewGlobalDef("x", constant.NewArray(i32(1), i32(2), i32(3), i32(4)))
+	y := ir.NewGlobalDecl("y", types.I64)
+	empty := ir.NewGlobalDecl("empty", types.NewStruct())
+	st := types.NewStruct(types.I32, types.Double)
+	sizeof := constant.NewPtrToInt(constant.NewGetElementPtr(constant.NewNull(types.NewPointer(st)), i64(1)), types.I64)
+	pair := types.NewStruct(types.I1, types.Double)
+	alignof := constant.NewPtrToInt(constant.NewGetElementPtr(constant.NewNull(types.NewPointer(pair)), i64(0), i32(1)), types.I64)
+	x1 := constant.NewGetElementPtr(x, i64(0), i64(1))
+	x3 := constant.NewGetElementPtr(x1, i64(2))
+	xEnd := constant.NewGetElementPtr(x, i64(1))
+	golden := []struct {
+		in   constant.Expr
+		want string
+		// Expected result without data layout.
+		wantNoLayout string
+	}{
+		// Address computations.
+		{
+			in:           x3,
+			want:         "bitcast (i8* getelementptr (i8, i8* bitcast ([4 x i32]* @x to i8*), i64 12) to i32*)",
+			wantNoLayout: x3.Ident(),
+		},
+		{
+			in:           constant.NewGetElementPtr(constant.NewBitCast(x1, types.NewPointer(types.I32)), i64(-1)),
+			want:         "bitcast ([4 x i32]* @x to i32*)",
+			wantNoLayout: "getelementptr (i32, i32* bitcast (i32* getelementptr ([4 x i32], [4 x i32]* @x, i64 0, i64 1) to i32*), i64 -1)",
+		},
+		{
+			in:           x1,
+			want:         x1.Ident(),
+			wantNoLayout: x1.Ident(),
+		},
+		// Size and alignment idioms.
+		{in: sizeof, want: "16", wantNoLayout: sizeof.Ident()},
+		{in: alignof, want: "8", wantNoLayout: alignof.Ident()},
+		// Integer and pointer round-trips.
+		{
+			in:           constant.NewPtrToInt(constant.NewIntToPtr(i32(-1), types.NewPointer(types.I8)), types.I64),
+			want:         "4294967295",
+			wantNoLayout: "ptrtoint (i8* inttoptr (i32 -1 to i8*) to i64)",
+		},
+		{
+			in:           constant.NewIntToPtr(constant.NewPtrToInt(x, types.I64), types.NewPointer(types.I32)),
+			want:         "bitcast ([4 x i32]* @x to i32*)",
+			wantNoLayout: "inttoptr (i64 ptrtoint ([4 x i32]* @x to i64) to i32*)",
+		},
+		{
+			in:           constant.NewIntToPtr(constant.NewPtrToInt(x, types.I32), types.NewPointer(types.I32)),
+			want:         "inttoptr (i32 ptrtoint ([4 x i32]* @x to i32) to i32*)",
+			wantNoLayout: "inttoptr (i32 ptrtoint ([4 x i32]* @x to i32) to i32*)",
+		},
+		// Address comparisons.
+		{
+			in:           constant.NewICmp(constant.IntEQ, constant.NewBitCast(x, types.NewPointer(types.I64)), y),
+			want:         "false",
+			wantNoLayout: "false",
+		},
+		{
+			in:           constant.NewICmp(constant.IntNE, x, constant.NewNull(x.Typ)),
+			want:         "true",
+			wantNoLayout: "true",
+		},
+		{
+			in:           constant.NewICmp(constant.IntEQ, x3, constant.NewGetElementPtr(x, i64(0), i64(3))),
+			want:         "true",
+			wantNoLayout: constant.NewICmp(constant.IntEQ, x3, constant.NewGetElementPtr(x, i64(0), i64(3))).Ident(),
+		},
+		{
+			in:           constant.NewICmp(constant.IntEQ, x1, constant.NewGetElementPtr(x, i64(0), i64(2))),
+			want:         "false",
+			wantNoLayout: constant.NewICmp(constant.IntEQ, x1, constant.NewGetElementPtr(x, i64(0), i64(2))).Ident(),
+		},
+		// One-past-the-end addresses and zero-sized objects may coincide with
+		// the addresses of other objects.
+		{
+			in:           constant.NewICmp(constant.IntEQ, xEnd, constant.NewBitCast(y, x.Typ)),
+			want:         constant.NewICmp(constant.IntEQ, xEnd, constant.NewBitCast(y, x.Typ)).Ident(),
+			wantNoLayout: constant.NewICmp(constant.IntEQ, xEnd, constant.NewBitCast(y, x.Typ)).Ident(),
+		},
+		{
+			in:           constant.NewICmp(constant.IntEQ, empty, constant.NewBitCast(y, empty.Typ)),
+			want:         constant.NewICmp(constant.IntEQ, empty, constant.NewBitCast(y, empty.Typ)).Ident(),
+			wantNoLayout: constant.NewICmp(constant.IntEQ, empty, constant.NewBitCast(y, empty.Typ)).Ident(),
+		},
+	}
+	dl := datalayout.Default()
+	for _, g := range golden {
+		if got := constant.Fold(g.in, dl); got.Ident() != g.want {
+			t.Errorf("folding of %q mismatch; expected %q, got %q", g.in.Ident(), g.want, got.Ident())
+		}
+		if got := constant.Fold(g.in, nil); got.Ident() != g.wantNoLayout {
+			t.Errorf("folding of %q without data layout mismatch; expected %q, got %q", g.in.Ident(), g.wantNoLayout, got.Ident())
+		}
+	}
+}
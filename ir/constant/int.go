@@ -0,0 +1,282 @@
+// === [ Integer arithmetic ] ==================================================
+//
+// Integer constants are arbitrary-width two's complement integers. The
+// operations below respect the bit size of the integer type, wrapping around
+// on overflow, and interpret the integer as signed or unsigned as specified by
+// the operation.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#binary-operations
+//    http://llvm.org/docs/LangRef.html#bitwise-binary-operations
+
+package constant
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/llir/llvm/ir/types"
+)
+
+// Unsigned returns the unsigned interpretation of the integer constant x.
+func (x *Int) Unsigned() *big.Int {
+	return wrapUnsigned(x.X, x.Typ.Size)
+}
+
+// Signed returns the signed interpretation of the integer constant x.
+func (x *Int) Signed() *big.Int {
+	z := x.Unsigned()
+	if z.Bit(x.Typ.Size-1) == 1 {
+		z.Sub(z, new(big.Int).Lsh(big.NewInt(1), uint(x.Typ.Size)))
+	}
+	return z
+}
+
+// --- [ Binary operations ] ---------------------------------------------------
+
+// Add returns the sum x+y, wrapped around to the bit size of the integer type.
+func (x *Int) Add(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).Add(x.X, y.X))
+}
+
+// Sub returns the difference x-y, wrapped around to the bit size of the integer
+// type.
+func (x *Int) Sub(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).Sub(x.X, y.X))
+}
+
+// Mul returns the product x*y, wrapped around to the bit size of the integer
+// type.
+func (x *Int) Mul(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).Mul(x.X, y.X))
+}
+
+// AddOverflows reports whether the sum x+y overflows, in the unsigned and
+// signed interpretation respectively; i.e. whether the result of an add with
+// the nuw or nsw flag is poison.
+func (x *Int) AddOverflows(y *Int) (unsigned, signed bool) {
+	x.checkType(y)
+	return x.overflows(new(big.Int).Add(x.Unsigned(), y.Unsigned()), new(big.Int).Add(x.Signed(), y.Signed()))
+}
+
+// SubOverflows reports whether the difference x-y overflows, in the unsigned
+// and signed interpretation respectively; i.e. whether the result of a sub
+// with the nuw or nsw flag is poison.
+func (x *Int) SubOverflows(y *Int) (unsigned, signed bool) {
+	x.checkType(y)
+	return x.overflows(new(big.Int).Sub(x.Unsigned(), y.Unsigned()), new(big.Int).Sub(x.Signed(), y.Signed()))
+}
+
+// MulOverflows reports whether the product x*y overflows, in the unsigned and
+// signed interpretation respectively; i.e. whether the result of a mul with
+// the nuw or nsw flag is poison.
+func (x *Int) MulOverflows(y *Int) (unsigned, signed bool) {
+	x.checkType(y)
+	return x.overflows(new(big.Int).Mul(x.Unsigned(), y.Unsigned()), new(big.Int).Mul(x.Signed(), y.Signed()))
+}
+
+// UDiv returns the unsigned quotient x/y, rounded toward zero. The boolean
+// return value is false if y is zero, as division by zero is undefined
+// behaviour.
+func (x *Int) UDiv(y *Int) (*Int, bool) {
+	x.checkType(y)
+	if y.isZero() {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Quo(x.Unsigned(), y.Unsigned())), true
+}
+
+// SDiv returns the signed quotient x/y, rounded toward zero. The boolean return
+// value is false if y is zero or if the quotient overflows (e.g. -128/-1 for
+// i8), as both are undefined behaviour.
+func (x *Int) SDiv(y *Int) (*Int, bool) {
+	x.checkType(y)
+	if y.isZero() || x.isSignedDivOverflow(y) {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Quo(x.Signed(), y.Signed())), true
+}
+
+// URem returns the unsigned remainder of x/y. The boolean return value is false
+// if y is zero, as division by zero is undefined behaviour.
+func (x *Int) URem(y *Int) (*Int, bool) {
+	x.checkType(y)
+	if y.isZero() {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Rem(x.Unsigned(), y.Unsigned())), true
+}
+
+// SRem returns the signed remainder of x/y, which has the sign of x. The
+// boolean return value is false if y is zero or if the corresponding quotient
+// overflows, as both are undefined behaviour.
+func (x *Int) SRem(y *Int) (*Int, bool) {
+	x.checkType(y)
+	if y.isZero() || x.isSignedDivOverflow(y) {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Rem(x.Signed(), y.Signed())), true
+}
+
+// --- [ Bitwise binary operations ] -------------------------------------------
+
+// Shl returns x shifted left by y bits, wrapped around to the bit size of the
+// integer type. The boolean return value is false if the result is poison; i.e.
+// if the shift amount is larger than or equal to the bit size.
+func (x *Int) Shl(y *Int) (*Int, bool) {
+	n, ok := x.shiftAmount(y)
+	if !ok {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Lsh(x.X, n)), true
+}
+
+// LShr returns x logically shifted right by y bits, filling the most
+// significant bits with zero. The boolean return value is false if the result
+// is poison; i.e. if the shift amount is larger than or equal to the bit size.
+func (x *Int) LShr(y *Int) (*Int, bool) {
+	n, ok := x.shiftAmount(y)
+	if !ok {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Rsh(x.Unsigned(), n)), true
+}
+
+// AShr returns x arithmetically shifted right by y bits, filling the most
+// significant bits with the sign bit of x. The boolean return value is false
+// if the result is poison; i.e. if the shift amount is larger than or equal to
+// the bit size.
+func (x *Int) AShr(y *Int) (*Int, bool) {
+	n, ok := x.shiftAmount(y)
+	if !ok {
+		return nil, false
+	}
+	return newInt(x.Typ, new(big.Int).Rsh(x.Signed(), n)), true
+}
+
+// ShlOverflows reports whether any non-zero bits are shifted out by shifting x
+// left by y bits, in the unsigned and signed interpretation respectively; i.e.
+// whether the result of a shl with the nuw or nsw flag is poison. In the signed
+// interpretation, the shifted out bits must equal the sign bit of the result.
+func (x *Int) ShlOverflows(y *Int) (unsigned, signed bool) {
+	n, ok := x.shiftAmount(y)
+	if !ok {
+		return true, true
+	}
+	return x.overflows(new(big.Int).Lsh(x.Unsigned(), n), new(big.Int).Lsh(x.Signed(), n))
+}
+
+// ShrInexact reports whether any non-zero bits are shifted out by shifting x
+// right by y bits; i.e. whether the result of an lshr or ashr with the exact
+// flag is poison.
+func (x *Int) ShrInexact(y *Int) bool {
+	n, ok := x.shiftAmount(y)
+	if !ok {
+		return true
+	}
+	return x.Unsigned().TrailingZeroBits() < n && !x.isZero()
+}
+
+// And returns the bitwise AND of x and y.
+func (x *Int) And(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).And(x.X, y.X))
+}
+
+// Or returns the bitwise OR of x and y.
+func (x *Int) Or(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).Or(x.X, y.X))
+}
+
+// Xor returns the bitwise XOR of x and y.
+func (x *Int) Xor(y *Int) *Int {
+	x.checkType(y)
+	return newInt(x.Typ, new(big.Int).Xor(x.X, y.X))
+}
+
+// --- [ Comparisons ] ---------------------------------------------------------
+
+// Compare reports whether the comparison of x and y based on the given integer
+// predicate holds.
+func (x *Int) Compare(pred IntPred, y *Int) bool {
+	x.checkType(y)
+	return intPredHolds(pred, x.Unsigned().Cmp(y.Unsigned()), x.Signed().Cmp(y.Signed()))
+}
+
+// --- [ Conversions ] ---------------------------------------------------------
+
+// Trunc returns the integer constant x truncated to the given smaller integer
+// type.
+func (x *Int) Trunc(t *types.IntType) *Int {
+	if t.Size >= x.Typ.Size {
+		panic(fmt.Errorf("invalid trunc from %v to larger or equal integer type %v", x.Typ, t))
+	}
+	return newInt(t, x.X)
+}
+
+// ZExt returns the integer constant x zero-extended to the given larger integer
+// type.
+func (x *Int) ZExt(t *types.IntType) *Int {
+	if t.Size <= x.Typ.Size {
+		panic(fmt.Errorf("invalid zext from %v to smaller or equal integer type %v", x.Typ, t))
+	}
+	return newInt(t, x.Unsigned())
+}
+
+// SExt returns the integer constant x sign-extended to the given larger integer
+// type.
+func (x *Int) SExt(t *types.IntType) *Int {
+	if t.Size <= x.Typ.Size {
+		panic(fmt.Errorf("invalid sext from %v to smaller or equal integer type %v", x.Typ, t))
+	}
+	return newInt(t, x.Signed())
+}
+
+// ### [ Helper functions ] ####################################################
+
+// checkType panics if the integer constants x and y are of different types.
+func (x *Int) checkType(y *Int) {
+	if !x.Typ.Equal(y.Typ) {
+		panic(fmt.Errorf("integer type mismatch; %v and %v", x.Typ, y.Typ))
+	}
+}
+
+// isZero reports whether the integer constant x is zero.
+func (x *Int) isZero() bool {
+	return x.Unsigned().Sign() == 0
+}
+
+// isSignedDivOverflow reports whether the signed quotient x/y overflows; i.e.
+// whether x is the minimum signed integer and y is -1.
+func (x *Int) isSignedDivOverflow(y *Int) bool {
+	min := new(big.Int).Lsh(big.NewInt(-1), uint(x.Typ.Size-1))
+	return x.Signed().Cmp(min) == 0 && y.Signed().Cmp(big.NewInt(-1)) == 0
+}
+
+// shiftAmount returns the amount by which to shift x, as specified by y. The
+// boolean return value is false if the shift amount is larger than or equal to
+// the bit size of x.
+func (x *Int) shiftAmount(y *Int) (uint, bool) {
+	x.checkType(y)
+	n := y.Unsigned()
+	if n.Cmp(big.NewInt(int64(x.Typ.Size))) >= 0 {
+		return 0, false
+	}
+	return uint(n.Uint64()), true
+}
+
+// overflows reports whether the exact unsigned result u and signed result s of
+// an operation on integers of the type of x are out of range of the type.
+func (x *Int) overflows(u, s *big.Int) (unsigned, signed bool) {
+	size := uint(x.Typ.Size)
+	umax := new(big.Int).Lsh(big.NewInt(1), size)
+	smax := new(big.Int).Lsh(big.NewInt(1), size-1)
+	smin := new(big.Int).Neg(smax)
+	unsigned = u.Sign() < 0 || u.Cmp(umax) >= 0
+	signed = s.Cmp(smin) < 0 || s.Cmp(smax) >= 0
+	return unsigned, signed
+}
@@ -0,0 +1,136 @@
+package constant_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestIntArith(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	i128 := types.NewInt(128)
+	max128 := constant.NewIntFromString("170141183460469231731687303715884105727", i128)
+	// total returns the result of an operation defined for all operands.
+	total := func(z *constant.Int) func() (*constant.Int, bool) {
+		return func() (*constant.Int, bool) { return z, true }
+	}
+	// partial returns the result of an operation which may be undefined.
+	partial := func(op func(x, y *constant.Int) (*constant.Int, bool), x, y *constant.Int) func() (*constant.Int, bool) {
+		return func() (*constant.Int, bool) { return op(x, y) }
+	}
+	golden := []struct {
+		op   string
+		eval func() (*constant.Int, bool)
+		// Expected result; or empty if undefined or poison.
+		want string
+	}{
+		{op: "127 + 1", eval: total(i8(127).Add(i8(1))), want: "-128"},
+		{op: "0 - 1", eval: total(i8(0).Sub(i8(1))), want: "-1"},
+		{op: "16 * 16", eval: total(i8(16).Mul(i8(16))), want: "0"},
+		{op: "max128 + 1", eval: total(max128.Add(constant.NewInt(1, i128))), want: "-170141183460469231731687303715884105728"},
+		{op: "true + true", eval: total(constant.True.Add(constant.True)), want: "false"},
+		{op: "255 & 15", eval: total(i8(-1).And(i8(15))), want: "15"},
+		{op: "-128 | 1", eval: total(i8(-128).Or(i8(1))), want: "-127"},
+		{op: "-1 ^ 1", eval: total(i8(-1).Xor(i8(1))), want: "-2"},
+		{op: "255 udiv 2", eval: partial((*constant.Int).UDiv, i8(-1), i8(2)), want: "127"},
+		{op: "-7 sdiv 2", eval: partial((*constant.Int).SDiv, i8(-7), i8(2)), want: "-3"},
+		{op: "255 urem 10", eval: partial((*constant.Int).URem, i8(-1), i8(10)), want: "5"},
+		{op: "-7 srem 2", eval: partial((*constant.Int).SRem, i8(-7), i8(2)), want: "-1"},
+		{op: "1 udiv 0", eval: partial((*constant.Int).UDiv, i8(1), i8(0)), want: ""},
+		{op: "-128 sdiv -1", eval: partial((*constant.Int).SDiv, i8(-128), i8(-1)), want: ""},
+		{op: "-128 srem -1", eval: partial((*constant.Int).SRem, i8(-128), i8(-1)), want: ""},
+		{op: "1 shl 7", eval: partial((*constant.Int).Shl, i8(1), i8(7)), want: "-128"},
+		{op: "-128 lshr 7", eval: partial((*constant.Int).LShr, i8(-128), i8(7)), want: "1"},
+		{op: "-128 ashr 7", eval: partial((*constant.Int).AShr, i8(-128), i8(7)), want: "-1"},
+		{op: "1 shl 8", eval: partial((*constant.Int).Shl, i8(1), i8(8)), want: ""},
+		{op: "1 lshr 255", eval: partial((*constant.Int).LShr, i8(1), i8(-1)), want: ""},
+	}
+	for _, g := range golden {
+		z, ok := g.eval()
+		if ok != (g.want != "") {
+			t.Errorf("%s: expected defined result %v, got %v", g.op, g.want != "", ok)
+			continue
+		}
+		if ok && z.Ident() != g.want {
+			t.Errorf("%s: expected %q, got %q", g.op, g.want, z.Ident())
+		}
+	}
+}
+
+func TestIntOverflows(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	golden := []struct {
+		op               string
+		overflows        func(x, y *constant.Int) (unsigned, signed bool)
+		x, y             *constant.Int
+		wantNUW, wantNSW bool
+	}{
+		{op: "127 + 1", overflows: (*constant.Int).AddOverflows, x: i8(127), y: i8(1), wantNUW: false, wantNSW: true},
+		{op: "255 + 1", overflows: (*constant.Int).AddOverflows, x: i8(-1), y: i8(1), wantNUW: true, wantNSW: false},
+		{op: "0 - 1", overflows: (*constant.Int).SubOverflows, x: i8(0), y: i8(1), wantNUW: true, wantNSW: false},
+		{op: "-128 - 1", overflows: (*constant.Int).SubOverflows, x: i8(-128), y: i8(1), wantNUW: false, wantNSW: true},
+		{op: "16 * 8", overflows: (*constant.Int).MulOverflows, x: i8(16), y: i8(8), wantNUW: false, wantNSW: true},
+		{op: "-1 * -1", overflows: (*constant.Int).MulOverflows, x: i8(-1), y: i8(-1), wantNUW: true, wantNSW: false},
+		{op: "64 shl 1", overflows: (*constant.Int).ShlOverflows, x: i8(64), y: i8(1), wantNUW: false, wantNSW: true},
+		{op: "-1 shl 1", overflows: (*constant.Int).ShlOverflows, x: i8(-1), y: i8(1), wantNUW: true, wantNSW: false},
+		{op: "1 shl 8", overflows: (*constant.Int).ShlOverflows, x: i8(1), y: i8(8), wantNUW: true, wantNSW: true},
+	}
+	for _, g := range golden {
+		nuw, nsw := g.overflows(g.x, g.y)
+		if nuw != g.wantNUW || nsw != g.wantNSW {
+			t.Errorf("%s: expected unsigned and signed overflow %v and %v, got %v and %v", g.op, g.wantNUW, g.wantNSW, nuw, nsw)
+		}
+	}
+	if !i8(3).ShrInexact(i8(1)) {
+		t.Errorf("expected 3 >> 1 to be inexact")
+	}
+	if i8(4).ShrInexact(i8(2)) || i8(0).ShrInexact(i8(7)) {
+		t.Errorf("expected 4 >> 2 and 0 >> 7 to be exact")
+	}
+}
+
+func TestIntCompare(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	golden := []struct {
+		x    *constant.Int
+		pred constant.IntPred
+		y    *constant.Int
+		want bool
+	}{
+		{x: i8(-1), pred: constant.IntEQ, y: constant.NewInt(255, types.I8), want: true},
+		{x: i8(-1), pred: constant.IntNE, y: i8(1), want: true},
+		{x: i8(-1), pred: constant.IntULT, y: i8(1), want: false},
+		{x: i8(-1), pred: constant.IntUGT, y: i8(1), want: true},
+		{x: i8(-1), pred: constant.IntSLT, y: i8(1), want: true},
+		{x: i8(-1), pred: constant.IntSGE, y: i8(1), want: false},
+		{x: i8(1), pred: constant.IntULE, y: i8(1), want: true},
+		{x: i8(1), pred: constant.IntSLE, y: i8(1), want: true},
+		{x: constant.True, pred: constant.IntSLT, y: constant.False, want: true},
+	}
+	for _, g := range golden {
+		if got := g.x.Compare(g.pred, g.y); got != g.want {
+			t.Errorf("icmp %v %v, %v: expected %v, got %v", g.pred, g.x.Ident(), g.y.Ident(), g.want, got)
+		}
+	}
+}
+
+func TestIntConversions(t *testing.T) {
+	i8 := func(x int64) *constant.Int { return constant.NewInt(x, types.I8) }
+	golden := []struct {
+		got  *constant.Int
+		want string
+	}{
+		{got: constant.NewInt(0x1FF, types.I32).Trunc(types.I8), want: "-1"},
+		{got: constant.NewInt(2, types.I32).Trunc(types.I1), want: "false"},
+		{got: i8(-1).ZExt(types.I32), want: "255"},
+		{got: i8(-1).SExt(types.I32), want: "-1"},
+		{got: constant.True.SExt(types.I8), want: "-1"},
+		{got: constant.True.ZExt(types.I8), want: "1"},
+	}
+	for _, g := range golden {
+		if g.got.Ident() != g.want {
+			t.Errorf("conversion mismatch; expected %q, got %q", g.want, g.got.Ident())
+		}
+	}
+}
@@ -2,6 +2,7 @@
 //
 // References:
 //    http://llvm.org/docs/LangRef.html#undefined-values
+//    http://llvm.org/docs/LangRef.html#poison-values
 
 package constant
 
@@ -39,3 +40,50 @@ func (*Undef) Immutable() {}
 // MetadataNode ensures that only metadata nodes can be assigned to the
 // ir.MetadataNode interface.
 func (*Undef) MetadataNode() {}
+
+// --- [ poison ] ----------------------------------------------------
+
+// Poison represents a poison constant; i.e. a deferred undefined behaviour.
+// Unlike undef, which may take any bit pattern on each use, poison propagates
+// through most operations, and undefined behaviour only occurs when a poison
+// value is used in a way which has side effects (e.g. as a branch condition or
+// the address of a store).
+//
+// Constant folding produces poison for operations whose result is poison, such
+// as shifts by amounts larger than or equal to the bit size, and division by
+// zero. The result of an add, sub, mul or shl with the nuw or nsw flag is
+// poison if the operation overflows (as reported by Int.AddOverflows,
+// Int.SubOverflows, Int.MulOverflows and Int.ShlOverflows), and the result of
+// a udiv, sdiv, lshr or ashr with the exact flag is poison if any non-zero
+// bits are discarded (as reported by Int.ShrInexact for shifts). The freeze
+// instruction stops the propagation of poison.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#poison-values
+type Poison struct {
+	// Constant type.
+	Typ types.Type
+}
+
+// NewPoison returns a new poison value constant based on the given type.
+func NewPoison(typ types.Type) *Poison {
+	return &Poison{Typ: typ}
+}
+
+// Type returns the type of the constant.
+func (c *Poison) Type() types.Type {
+	return c.Typ
+}
+
+// Ident returns the string representation of the constant.
+func (c *Poison) Ident() string {
+	return "poison"
+}
+
+// Immutable ensures that only constants can be assigned to the
+// constant.Constant interface.
+func (*Poison) Immutable() {}
+
+// MetadataNode ensures that only metadata nodes can be assigned to the
+// ir.MetadataNode interface.
+func (*Poison) MetadataNode() {}
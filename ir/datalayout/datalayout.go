@@ -0,0 +1,444 @@
+// === [ Data layout ] =========================================================
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#data-layout
+
+// Package datalayout implements parsing of LLVM IR data layout specifications,
+// and queries of the size and alignment of types in memory.
+package datalayout
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DataLayout specifies how data is to be laid out in memory.
+//
+// Sizes and alignments of the data layout specification are in bits, while
+// sizes, alignments and offsets computed by data layout queries are in bytes.
+type DataLayout struct {
+	// BigEndian specifies whether data is laid out in big-endian form; or
+	// little-endian form otherwise.
+	BigEndian bool
+	// Natural alignment of the stack in bits; or 0 if unspecified.
+	StackAlign int64
+	// Address space of allocas.
+	AllocaAddrSpace int
+	// Address space of program memory (i.e. functions).
+	ProgramAddrSpace int
+	// Default address space of global variables.
+	GlobalsAddrSpace int
+	// Layout of pointers, sorted by address space.
+	Pointers []PointerLayout
+	// Alignment of integer types, sorted by bit width.
+	Ints []Alignment
+	// Alignment of floating-point types, sorted by bit width.
+	Floats []Alignment
+	// Alignment of vector types, sorted by bit width.
+	Vectors []Alignment
+	// Alignment of aggregate types; the bit width of which is unused.
+	Aggregate Alignment
+	// Alignment of function pointers in bits; or 0 if unspecified.
+	FuncPtrAlign int64
+	// FuncPtrAlignMultiple specifies whether the alignment of function pointers
+	// is a multiple of FuncPtrAlign and the explicit alignment of functions; or
+	// independent of the alignment of functions otherwise.
+	FuncPtrAlignMultiple bool
+	// Native integer widths of the target CPU in bits.
+	NativeInts []int64
+	// Address spaces of non-integral pointer types.
+	NonIntegralAddrSpaces []int
+	// Mangling style of symbol names in the output.
+	Mangling Mangling
+}
+
+// Alignment specifies the ABI and preferred alignment of types of a given bit
+// width.
+type Alignment struct {
+	// Bit width of the type.
+	Size int64
+	// ABI alignment in bits.
+	ABI int64
+	// Preferred alignment in bits.
+	Pref int64
+}
+
+// PointerLayout specifies the size and alignment of pointers in a given
+// address space.
+type PointerLayout struct {
+	// Address space.
+	AddrSpace int
+	// Size of pointers in bits.
+	Size int64
+	// ABI alignment in bits.
+	ABI int64
+	// Preferred alignment in bits.
+	Pref int64
+	// Size of indices used for address computations in bits.
+	IndexSize int64
+}
+
+// Mangling is a mangling style of symbol names.
+type Mangling uint8
+
+// Mangling styles.
+const (
+	// No mangling.
+	ManglingNone Mangling = iota
+	// ELF mangling (m:e); private symbols get a .L prefix.
+	ManglingELF
+	// Mips mangling (m:m); private symbols get a $ prefix.
+	ManglingMips
+	// Mach-O mangling (m:o); other symbols get a _ prefix.
+	ManglingMachO
+	// Windows x86 COFF mangling (m:x); other symbols get a _ prefix.
+	ManglingWinCOFFX86
+	// Windows COFF mangling (m:w); private symbols get a .L prefix.
+	ManglingWinCOFF
+	// GOFF mangling (m:l); private symbols get a @ prefix.
+	ManglingGOFF
+	// XCOFF mangling (m:a); private symbols get a L.. prefix.
+	ManglingXCOFF
+)
+
+// manglingChars maps from mangling styles to their data layout specification
+// characters.
+var manglingChars = map[Mangling]string{
+	ManglingELF:        "e",
+	ManglingMips:       "m",
+	ManglingMachO:      "o",
+	ManglingWinCOFFX86: "x",
+	ManglingWinCOFF:    "w",
+	ManglingGOFF:       "l",
+	ManglingXCOFF:      "a",
+}
+
+// String returns the data layout specification character of the mangling
+// style.
+func (m Mangling) String() string {
+	if c, ok := manglingChars[m]; ok {
+		return c
+	}
+	return "none"
+}
+
+// Default returns the default data layout, as used when the data layout of a
+// module is unspecified.
+func Default() *DataLayout {
+	return &DataLayout{
+		Pointers: []PointerLayout{
+			{AddrSpace: 0, Size: 64, ABI: 64, Pref: 64, IndexSize: 64},
+		},
+		Ints: []Alignment{
+			{Size: 1, ABI: 8, Pref: 8},
+			{Size: 8, ABI: 8, Pref: 8},
+			{Size: 16, ABI: 16, Pref: 16},
+			{Size: 32, ABI: 32, Pref: 32},
+			{Size: 64, ABI: 32, Pref: 64},
+		},
+		Floats: []Alignment{
+			{Size: 16, ABI: 16, Pref: 16},
+			{Size: 32, ABI: 32, Pref: 32},
+			{Size: 64, ABI: 64, Pref: 64},
+			{Size: 128, ABI: 128, Pref: 128},
+		},
+		Vectors: []Alignment{
+			{Size: 64, ABI: 64, Pref: 64},
+			{Size: 128, ABI: 128, Pref: 128},
+		},
+		Aggregate: Alignment{ABI: 0, Pref: 64},
+	}
+}
+
+// Parse parses the given data layout specification (e.g.
+// "e-m:e-i64:64-f80:128-n8:16:32:64-S128"). Unspecified properties have their
+// default values (see Default); the empty specification thus denotes the
+// default data layout.
+func Parse(s string) (*DataLayout, error) {
+	dl := Default()
+	if len(s) == 0 {
+		return dl, nil
+	}
+	for _, spec := range strings.Split(s, "-") {
+		if err := dl.parseSpec(spec); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return dl, nil
+}
+
+// parseSpec parses the given data layout specification item.
+func (dl *DataLayout) parseSpec(spec string) error {
+	if len(spec) == 0 {
+		return errors.Errorf("empty data layout specification item")
+	}
+	// Item with a single letter identifier followed by a colon separated list
+	// of fields.
+	fields := strings.Split(spec[1:], ":")
+	switch c := spec[0]; c {
+	case 'e', 'E':
+		if len(spec) != 1 {
+			return errors.Errorf("invalid endianness specification %q", spec)
+		}
+		dl.BigEndian = c == 'E'
+	case 'S':
+		align, err := parseBits(spec, spec[1:])
+		if err != nil {
+			return err
+		}
+		dl.StackAlign = align
+	case 'A', 'P', 'G':
+		addrSpace, err := parseAddrSpace(spec, spec[1:])
+		if err != nil {
+			return err
+		}
+		switch c {
+		case 'A':
+			dl.AllocaAddrSpace = addrSpace
+		case 'P':
+			dl.ProgramAddrSpace = addrSpace
+		case 'G':
+			dl.GlobalsAddrSpace = addrSpace
+		}
+	case 'p':
+		return dl.parsePointer(spec, fields)
+	case 'i', 'f', 'v', 'a':
+		return dl.parseAlignment(spec, fields)
+	case 'F':
+		if len(spec) < 2 {
+			return errors.Errorf("invalid function pointer alignment specification %q", spec)
+		}
+		switch spec[1] {
+		case 'i':
+			dl.FuncPtrAlignMultiple = false
+		case 'n':
+			dl.FuncPtrAlignMultiple = true
+		default:
+			return errors.Errorf("invalid function pointer alignment type in %q; expected 'i' or 'n'", spec)
+		}
+		align, err := parseAlign(spec, spec[2:])
+		if err != nil {
+			return err
+		}
+		dl.FuncPtrAlign = align
+	case 'm':
+		if len(fields) != 2 || len(fields[0]) != 0 {
+			return errors.Errorf("invalid mangling specification %q", spec)
+		}
+		for m, c := range manglingChars {
+			if c == fields[1] {
+				dl.Mangling = m
+				return nil
+			}
+		}
+		return errors.Errorf("unknown mangling style %q in %q", fields[1], spec)
+	case 'n':
+		if strings.HasPrefix(spec, "ni:") {
+			for _, field := range fields[1:] {
+				addrSpace, err := parseAddrSpace(spec, field)
+				if err != nil {
+					return err
+				}
+				if addrSpace == 0 {
+					return errors.Errorf("address space 0 cannot be non-integral in %q", spec)
+				}
+				dl.NonIntegralAddrSpaces = append(dl.NonIntegralAddrSpaces, addrSpace)
+			}
+			return nil
+		}
+		dl.NativeInts = nil
+		for _, field := range fields {
+			size, err := parseSize(spec, field)
+			if err != nil {
+				return err
+			}
+			dl.NativeInts = append(dl.NativeInts, size)
+		}
+	default:
+		return errors.Errorf("unknown data layout specification item %q", spec)
+	}
+	return nil
+}
+
+// parsePointer parses the given pointer layout specification item, with the
+// given colon separated fields; the first of which is the address space.
+//
+//    p[n]:<size>:<abi>[:<pref>][:<idx>]
+func (dl *DataLayout) parsePointer(spec string, fields []string) error {
+	if len(fields) < 3 || len(fields) > 5 {
+		return errors.Errorf("invalid pointer layout specification %q", spec)
+	}
+	var p PointerLayout
+	if len(fields[0]) > 0 {
+		addrSpace, err := parseAddrSpace(spec, fields[0])
+		if err != nil {
+			return err
+		}
+		p.AddrSpace = addrSpace
+	}
+	size, err := parseSize(spec, fields[1])
+	if err != nil {
+		return err
+	}
+	p.Size = size
+	if p.ABI, err = parseAlign(spec, fields[2]); err != nil {
+		return err
+	}
+	p.Pref = p.ABI
+	if len(fields) > 3 {
+		if p.Pref, err = parseAlign(spec, fields[3]); err != nil {
+			return err
+		}
+	}
+	p.IndexSize = p.Size
+	if len(fields) > 4 {
+		if p.IndexSize, err = parseSize(spec, fields[4]); err != nil {
+			return err
+		}
+		if p.IndexSize > p.Size {
+			return errors.Errorf("index size larger than pointer size in %q", spec)
+		}
+	}
+	if p.Pref < p.ABI {
+		return errors.Errorf("preferred alignment smaller than ABI alignment in %q", spec)
+	}
+	i := sort.Search(len(dl.Pointers), func(i int) bool {
+		return dl.Pointers[i].AddrSpace >= p.AddrSpace
+	})
+	if i < len(dl.Pointers) && dl.Pointers[i].AddrSpace == p.AddrSpace {
+		dl.Pointers[i] = p
+		return nil
+	}
+	dl.Pointers = append(dl.Pointers, PointerLayout{})
+	copy(dl.Pointers[i+1:], dl.Pointers[i:])
+	dl.Pointers[i] = p
+	return nil
+}
+
+// parseAlignment parses the given integer, floating-point, vector or aggregate
+// alignment specification item, with the given colon separated fields; the
+// first of which is the bit width of the type.
+//
+//    i<size>:<abi>[:<pref>]
+//    f<size>:<abi>[:<pref>]
+//    v<size>:<abi>[:<pref>]
+//    a:<abi>[:<pref>]
+func (dl *DataLayout) parseAlignment(spec string, fields []string) error {
+	if len(fields) < 2 || len(fields) > 3 {
+		return errors.Errorf("invalid alignment specification %q", spec)
+	}
+	// The bit width of aggregate types is ignored.
+	var a Alignment
+	if spec[0] != 'a' {
+		size, err := parseSize(spec, fields[0])
+		if err != nil {
+			return err
+		}
+		a.Size = size
+	}
+	abi, err := parseBits(spec, fields[1])
+	if err != nil {
+		return err
+	}
+	if abi == 0 && spec[0] != 'a' {
+		return errors.Errorf("ABI alignment of zero only valid for aggregate types in %q", spec)
+	}
+	if abi%8 != 0 || (abi != 0 && !isPowerOf2(abi/8)) {
+		return errors.Errorf("invalid ABI alignment in %q; expected power of two multiple of 8", spec)
+	}
+	a.ABI, a.Pref = abi, abi
+	if len(fields) > 2 {
+		if a.Pref, err = parseAlign(spec, fields[2]); err != nil {
+			return err
+		}
+	}
+	if a.Pref < a.ABI {
+		return errors.Errorf("preferred alignment smaller than ABI alignment in %q", spec)
+	}
+	switch spec[0] {
+	case 'i':
+		if a.Size == 8 && a.ABI != 8 {
+			return errors.Errorf("invalid ABI alignment of i8 in %q; expected 8", spec)
+		}
+		dl.Ints = setAlignment(dl.Ints, a)
+	case 'f':
+		dl.Floats = setAlignment(dl.Floats, a)
+	case 'v':
+		dl.Vectors = setAlignment(dl.Vectors, a)
+	case 'a':
+		dl.Aggregate = a
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// setAlignment sets the alignment of types of the bit width of a in the given
+// list of alignments sorted by bit width, and returns the updated list.
+func setAlignment(as []Alignment, a Alignment) []Alignment {
+	i := sort.Search(len(as), func(i int) bool {
+		return as[i].Size >= a.Size
+	})
+	if i < len(as) && as[i].Size == a.Size {
+		as[i] = a
+		return as
+	}
+	as = append(as, Alignment{})
+	copy(as[i+1:], as[i:])
+	as[i] = a
+	return as
+}
+
+// parseBits parses the given non-negative number of bits of the data layout
+// specification item spec.
+func parseBits(spec, s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n >= 1<<24 {
+		return 0, errors.Errorf("invalid number of bits %q in %q", s, spec)
+	}
+	return n, nil
+}
+
+// parseSize parses the given non-zero size in bits of the data layout
+// specification item spec.
+func parseSize(spec, s string) (int64, error) {
+	size, err := parseBits(spec, s)
+	if err != nil {
+		return 0, err
+	}
+	if size == 0 {
+		return 0, errors.Errorf("invalid zero size in %q", spec)
+	}
+	return size, nil
+}
+
+// parseAlign parses the given alignment in bits of the data layout
+// specification item spec. Alignments are power of two multiples of 8 bits.
+func parseAlign(spec, s string) (int64, error) {
+	align, err := parseBits(spec, s)
+	if err != nil {
+		return 0, err
+	}
+	if align == 0 || align%8 != 0 || !isPowerOf2(align/8) {
+		return 0, errors.Errorf("invalid alignment %q in %q; expected power of two multiple of 8", s, spec)
+	}
+	return align, nil
+}
+
+// parseAddrSpace parses the given address space of the data layout
+// specification item spec.
+func parseAddrSpace(spec, s string) (int, error) {
+	addrSpace, err := strconv.ParseUint(s, 10, 24)
+	if err != nil {
+		return 0, errors.Errorf("invalid address space %q in %q", s, spec)
+	}
+	return int(addrSpace), nil
+}
+
+// isPowerOf2 reports whether the given positive integer is a power of two.
+func isPowerOf2(n int64) bool {
+	return n > 0 && n&(n-1) == 0
+}
@@ -0,0 +1,132 @@
+package datalayout_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/datalayout"
+	"github.com/llir/llvm/ir/types"
+)
+
+// x86-64 Linux data layout.
+const x86_64 = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+
+func TestParse(t *testing.T) {
+	dl, err := datalayout.Parse(x86_64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.BigEndian {
+		t.Errorf("expected little-endian data layout")
+	}
+	if dl.Mangling != datalayout.ManglingELF {
+		t.Errorf("mangling mismatch; expected %v, got %v", datalayout.ManglingELF, dl.Mangling)
+	}
+	if dl.StackAlign != 128 {
+		t.Errorf("stack alignment mismatch; expected 128, got %d", dl.StackAlign)
+	}
+	if !dl.IsLegalInt(32) || dl.IsLegalInt(128) {
+		t.Errorf("native integer widths mismatch; got %v", dl.NativeInts)
+	}
+	if got := dl.PointerSize(270); got != 4 {
+		t.Errorf("pointer size of address space 270 mismatch; expected 4, got %d", got)
+	}
+	if got := dl.PointerSize(1); got != 8 {
+		t.Errorf("pointer size of address space 1 mismatch; expected 8, got %d", got)
+	}
+
+	// Invalid data layouts.
+	invalid := []string{
+		"e-",
+		"x",
+		"i32:24",
+		"p:64",
+		"i16:32:16",
+		"m:z",
+		"ni:0",
+		"p:32:32:32:64",
+	}
+	for _, s := range invalid {
+		if _, err := datalayout.Parse(s); err == nil {
+			t.Errorf("expected error for invalid data layout %q", s)
+		}
+	}
+}
+
+func TestTypeSize(t *testing.T) {
+	def, err := datalayout.Parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x86, err := datalayout.Parse(x86_64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i8ptr := types.NewPointer(types.I8)
+	// { i8, i32, i64 }
+	s := types.NewStruct(types.I8, types.I32, types.I64)
+	golden := []struct {
+		dl       *datalayout.DataLayout
+		typ      types.Type
+		size     int64
+		abiAlign int64
+	}{
+		{dl: def, typ: types.I1, size: 1, abiAlign: 1},
+		{dl: def, typ: types.I64, size: 8, abiAlign: 4},
+		{dl: x86, typ: types.I64, size: 8, abiAlign: 8},
+		{dl: def, typ: types.NewInt(24), size: 4, abiAlign: 4},
+		{dl: def, typ: types.NewInt(128), size: 16, abiAlign: 4},
+		{dl: def, typ: types.X86_FP80, size: 16, abiAlign: 16},
+		{dl: def, typ: types.Double, size: 8, abiAlign: 8},
+		{dl: def, typ: i8ptr, size: 8, abiAlign: 8},
+		{dl: def, typ: types.NewVector(types.I32, 4), size: 16, abiAlign: 16},
+		{dl: def, typ: types.NewVector(types.I8, 3), size: 4, abiAlign: 4},
+		{dl: def, typ: types.NewArray(types.X86_FP80, 3), size: 48, abiAlign: 16},
+		{dl: def, typ: s, size: 16, abiAlign: 4},
+		{dl: x86, typ: s, size: 16, abiAlign: 8},
+		{dl: def, typ: types.NewStruct(), size: 0, abiAlign: 1},
+	}
+	for _, g := range golden {
+		if got := g.dl.TypeSize(g.typ); got != g.size {
+			t.Errorf("size of %v mismatch; expected %d, got %d", g.typ, g.size, got)
+		}
+		if got := g.dl.ABIAlignment(g.typ); got != g.abiAlign {
+			t.Errorf("ABI alignment of %v mismatch; expected %d, got %d", g.typ, g.abiAlign, got)
+		}
+	}
+	if got := def.PrefAlignment(types.I64); got != 8 {
+		t.Errorf("preferred alignment of i64 mismatch; expected 8, got %d", got)
+	}
+	if got := def.PrefAlignment(types.NewStruct(types.I8)); got != 8 {
+		t.Errorf("preferred alignment of { i8 } mismatch; expected 8, got %d", got)
+	}
+}
+
+func TestStructLayout(t *testing.T) {
+	dl, err := datalayout.Parse(x86_64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// { i8, i16, [3 x i8], double, i8 }
+	s := types.NewStruct(types.I8, types.I16, types.NewArray(types.I8, 3), types.Double, types.I8)
+	l := dl.StructLayout(s)
+	want := []int64{0, 2, 4, 8, 16}
+	for i, offset := range l.Offsets {
+		if offset != want[i] {
+			t.Errorf("offset of field %d mismatch; expected %d, got %d", i, want[i], offset)
+		}
+	}
+	if l.Size != 24 || l.Align != 8 {
+		t.Errorf("struct size and alignment mismatch; expected 24 and 8, got %d and %d", l.Size, l.Align)
+	}
+	if got := l.FieldAt(7); got != 2 {
+		t.Errorf("field at offset 7 mismatch; expected 2, got %d", got)
+	}
+
+	// getelementptr { i8, i16, [3 x i8], double, i8 }, { ... }* %p, i64 1, i32 2, i64 1
+	if got := dl.IndexedOffset(s, []int64{1, 2, 1}); got != 24+4+1 {
+		t.Errorf("indexed offset mismatch; expected %d, got %d", 24+4+1, got)
+	}
+	if got := dl.IndexedOffset(types.I32, []int64{-3}); got != -12 {
+		t.Errorf("indexed offset mismatch; expected -12, got %d", got)
+	}
+}
@@ -0,0 +1,289 @@
+// === [ Size and alignment queries ] ==========================================
+
+package datalayout
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/llir/llvm/ir/types"
+)
+
+// TypeSizeInBits returns the number of bits needed to hold a value of the given
+// sized type.
+func (dl *DataLayout) TypeSizeInBits(t types.Type) int64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return int64(t.Size)
+	case *types.FloatType:
+		return floatSize(t.Kind)
+	case *types.PointerType:
+		return dl.Pointer(t.AddrSpace).Size
+	case *types.VectorType:
+		return t.Len * dl.TypeSizeInBits(t.Elem)
+	case *types.ArrayType:
+		return t.Len * dl.TypeSize(t.Elem) * 8
+	case *types.StructType:
+		return dl.StructLayout(t).Size * 8
+	default:
+		panic(fmt.Errorf("unable to compute size of unsized type %v", t))
+	}
+}
+
+// TypeStoreSize returns the maximum number of bytes that may be overwritten by
+// storing a value of the given sized type.
+func (dl *DataLayout) TypeStoreSize(t types.Type) int64 {
+	return (dl.TypeSizeInBits(t) + 7) / 8
+}
+
+// TypeSize returns the size in bytes of values of the given sized type in
+// memory, including alignment padding; i.e. the offset in bytes between
+// successive values of the type in arrays and allocas.
+func (dl *DataLayout) TypeSize(t types.Type) int64 {
+	return alignTo(dl.TypeStoreSize(t), dl.ABIAlignment(t))
+}
+
+// ABIAlignment returns the minimum ABI-required alignment in bytes of the given
+// sized type.
+func (dl *DataLayout) ABIAlignment(t types.Type) int64 {
+	return dl.alignment(t, true)
+}
+
+// PrefAlignment returns the preferred alignment in bytes of the given sized
+// type.
+func (dl *DataLayout) PrefAlignment(t types.Type) int64 {
+	return dl.alignment(t, false)
+}
+
+// alignment returns the ABI alignment (if abi is true) or preferred alignment
+// in bytes of the given sized type.
+func (dl *DataLayout) alignment(t types.Type, abi bool) int64 {
+	pick := func(a Alignment) int64 {
+		if abi {
+			return a.ABI / 8
+		}
+		return a.Pref / 8
+	}
+	switch t := t.(type) {
+	case *types.IntType:
+		// Use the alignment of the smallest larger integer type if the bit width
+		// is not specified, or the largest integer type if none is larger.
+		i := sort.Search(len(dl.Ints), func(i int) bool {
+			return dl.Ints[i].Size >= int64(t.Size)
+		})
+		if i == len(dl.Ints) {
+			i--
+		}
+		return pick(dl.Ints[i])
+	case *types.FloatType:
+		size := floatSize(t.Kind)
+		if a, ok := findAlignment(dl.Floats, size); ok {
+			return pick(a)
+		}
+		// Use the smallest power of two greater than or equal to the store size
+		// if the bit width is not specified.
+		return powerOf2Ceil((size + 7) / 8)
+	case *types.PointerType:
+		p := dl.Pointer(t.AddrSpace)
+		if abi {
+			return p.ABI / 8
+		}
+		return p.Pref / 8
+	case *types.VectorType:
+		if a, ok := findAlignment(dl.Vectors, dl.TypeSizeInBits(t)); ok {
+			return pick(a)
+		}
+		// Use the smallest power of two greater than or equal to the store size
+		// if the bit width is not specified.
+		return powerOf2Ceil(dl.TypeStoreSize(t))
+	case *types.ArrayType:
+		return dl.alignment(t.Elem, abi)
+	case *types.StructType:
+		align := dl.StructLayout(t).Align
+		if a := pick(dl.Aggregate); a > align {
+			return a
+		}
+		return align
+	default:
+		panic(fmt.Errorf("unable to compute alignment of unsized type %v", t))
+	}
+}
+
+// Pointer returns the layout of pointers in the given address space. The
+// layout of the default address space is used for address spaces without
+// explicit pointer layout.
+func (dl *DataLayout) Pointer(addrSpace int) PointerLayout {
+	var def PointerLayout
+	for _, p := range dl.Pointers {
+		switch p.AddrSpace {
+		case addrSpace:
+			return p
+		case 0:
+			def = p
+		}
+	}
+	def.AddrSpace = addrSpace
+	return def
+}
+
+// PointerSize returns the size in bytes of pointers in the given address
+// space.
+func (dl *DataLayout) PointerSize(addrSpace int) int64 {
+	return (dl.Pointer(addrSpace).Size + 7) / 8
+}
+
+// IndexSize returns the size in bytes of indices used for address computations
+// on pointers in the given address space.
+func (dl *DataLayout) IndexSize(addrSpace int) int64 {
+	return (dl.Pointer(addrSpace).IndexSize + 7) / 8
+}
+
+// IntPtrType returns the integer type of the same size as pointers in the
+// given address space.
+func (dl *DataLayout) IntPtrType(addrSpace int) *types.IntType {
+	return types.NewInt(int(dl.Pointer(addrSpace).Size))
+}
+
+// IsLegalInt reports whether the given bit width is a native integer width of
+// the target CPU.
+func (dl *DataLayout) IsLegalInt(size int64) bool {
+	for _, n := range dl.NativeInts {
+		if n == size {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNonIntegral reports whether pointers in the given address space are
+// non-integral.
+func (dl *DataLayout) IsNonIntegral(addrSpace int) bool {
+	for _, a := range dl.NonIntegralAddrSpaces {
+		if a == addrSpace {
+			return true
+		}
+	}
+	return false
+}
+
+// --- [ Struct layout ] -------------------------------------------------------
+
+// StructLayout specifies the layout of a struct type in memory.
+type StructLayout struct {
+	// Size of the struct in bytes, including tail padding.
+	Size int64
+	// Alignment of the struct in bytes; i.e. the maximum ABI alignment of its
+	// fields.
+	Align int64
+	// Offsets of the struct fields in bytes.
+	Offsets []int64
+}
+
+// StructLayout returns the layout of the given struct type, with each field
+// aligned to its ABI alignment. It panics if the struct type is opaque.
+func (dl *DataLayout) StructLayout(t *types.StructType) *StructLayout {
+	if t.Opaque {
+		panic(fmt.Errorf("unable to compute layout of opaque struct type %v", t))
+	}
+	l := &StructLayout{Align: 1}
+	for _, field := range t.Fields {
+		align := dl.ABIAlignment(field)
+		l.Size = alignTo(l.Size, align)
+		l.Offsets = append(l.Offsets, l.Size)
+		l.Size += dl.TypeSize(field)
+		if align > l.Align {
+			l.Align = align
+		}
+	}
+	l.Size = alignTo(l.Size, l.Align)
+	return l
+}
+
+// FieldAt returns the index of the last struct field starting at or before the
+// given byte offset; i.e. the field containing the offset, unless the offset is
+// within padding.
+func (l *StructLayout) FieldAt(offset int64) int {
+	i := sort.Search(len(l.Offsets), func(i int) bool {
+		return l.Offsets[i] > offset
+	})
+	return i - 1
+}
+
+// --- [ Address computations ] ------------------------------------------------
+
+// IndexedOffset returns the offset in bytes computed by a getelementptr
+// instruction with the given source element type and constant indices. The
+// first index steps over values of the source element type, and the remaining
+// indices index into aggregate and vector types.
+func (dl *DataLayout) IndexedOffset(elem types.Type, indices []int64) int64 {
+	if len(indices) == 0 {
+		return 0
+	}
+	offset := indices[0] * dl.TypeSize(elem)
+	for _, index := range indices[1:] {
+		switch t := elem.(type) {
+		case *types.StructType:
+			if index < 0 || index >= int64(len(t.Fields)) {
+				panic(fmt.Errorf("invalid struct field index %d of type %v with %d fields", index, t, len(t.Fields)))
+			}
+			offset += dl.StructLayout(t).Offsets[index]
+			elem = t.Fields[index]
+		case *types.ArrayType:
+			elem = t.Elem
+			offset += index * dl.TypeSize(elem)
+		case *types.VectorType:
+			elem = t.Elem
+			offset += index * dl.TypeSize(elem)
+		default:
+			panic(fmt.Errorf("unable to index into non-aggregate type %v", t))
+		}
+	}
+	return offset
+}
+
+// ### [ Helper functions ] ####################################################
+
+// findAlignment returns the alignment of types of the given bit width in the
+// given list of alignments sorted by bit width. The boolean return value
+// indicates success.
+func findAlignment(as []Alignment, size int64) (Alignment, bool) {
+	i := sort.Search(len(as), func(i int) bool {
+		return as[i].Size >= size
+	})
+	if i < len(as) && as[i].Size == size {
+		return as[i], true
+	}
+	return Alignment{}, false
+}
+
+// floatSize returns the size in bits of the given floating-point kind.
+func floatSize(kind types.FloatKind) int64 {
+	switch kind {
+	case types.FloatKindIEEE_16:
+		return 16
+	case types.FloatKindIEEE_32:
+		return 32
+	case types.FloatKindIEEE_64:
+		return 64
+	case types.FloatKindDoubleExtended_80:
+		return 80
+	case types.FloatKindIEEE_128, types.FloatKindDoubleDouble_128:
+		return 128
+	default:
+		panic(fmt.Errorf("support for floating-point kind %v not yet implemented", kind))
+	}
+}
+
+// alignTo returns n rounded up to a multiple of the given alignment.
+func alignTo(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
+
+// powerOf2Ceil returns the smallest power of two greater than or equal to n.
+func powerOf2Ceil(n int64) int64 {
+	p := int64(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -18,12 +18,9 @@ func Example_evaluator() {
 		log.Fatal(err)
 	}
 	// Evalute and print the return value of the `@main` function.
-	for _, f := range m.Funcs {
-		if f.Name == "main" {
-			e := newEvaluator(f)
-			fmt.Println("result:", e.eval())
-			break
-		}
+	if f := m.Func("main"); f != nil {
+		e := newEvaluator(f)
+		fmt.Println("result:", e.eval())
 	}
 
 	// Output:
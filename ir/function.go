@@ -8,14 +8,17 @@ package ir
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
 	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
 )
 
 // --- [ Functions ] -----------------------------------------------------------
@@ -38,12 +41,18 @@ type Function struct {
 	// Calling convention.
 	CallConv CallConv
 	// Basic blocks of the function; or nil if defined externally.
+	//
+	// The basic blocks of a lazily parsed function are not present until the
+	// body of the function is materialized (see Materialize); the function
+	// appears as a declaration to code reading Blocks directly before then.
 	Blocks []*BasicBlock
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// function.
 	Metadata map[string]*metadata.Metadata
-	// mu prevents races on assignIDs.
+	// mu prevents races on assignIDs and materialization.
 	mu sync.Mutex
+	// locals is the local symbol table of the function.
+	locals localSymtab
 }
 
 // NewFunction returns a new function based on the given function name, return
@@ -76,7 +85,13 @@ func (f *Function) GetName() string {
 
 // SetName sets the name of the function.
 func (f *Function) SetName(name string) {
+	oldName := f.Name
 	f.Name = name
+	if f.Parent == nil {
+		rename.Globals.Renamed()
+		return
+	}
+	f.Parent.renameGlobal(f, oldName)
 }
 
 // Immutable ensures that only constants can be assigned to the
@@ -88,63 +103,206 @@ func (*Function) Immutable() {}
 func (*Function) MetadataNode() {}
 
 // String returns the LLVM syntax representation of the function.
+//
+// Unique local IDs are assigned to unnamed function parameters, basic blocks
+// and local variables. Local IDs which are out of sequence (e.g. after removing
+// an instruction) are renumbered.
+//
+// If the body of a lazily parsed function could not be materialized, the
+// materialization error is written as a comment in place of the body.
 func (f *Function) String() string {
-	// Assign unique local IDs to unnamed function parameters, basic blocks and
-	// local variables.
+	buf := &bytes.Buffer{}
+	if err := f.renumberIDs(); err != nil {
+		f.writeError(newWriter(buf), err)
+		return buf.String()
+	}
+	f.write(newWriter(buf))
+	return buf.String()
+}
+
+// WriteTo writes the LLVM syntax representation of the function to w. The
+// number of bytes written and any error encountered are returned.
+//
+// As with String, local IDs which are out of sequence are renumbered.
+func (f *Function) WriteTo(w io.Writer) (n int64, err error) {
+	if err := f.renumberIDs(); err != nil {
+		return 0, err
+	}
+	fw := newWriter(w)
+	f.write(fw)
+	return fw.n, fw.err
+}
+
+// Write writes the LLVM syntax representation of the function to w.
+//
+// Contrary to String, local IDs which are out of sequence are not renumbered;
+// instead an error is returned (see AssignIDs).
+func (f *Function) Write(w io.Writer) error {
+	if err := f.AssignIDs(); err != nil {
+		return err
+	}
+	fw := newWriter(w)
+	f.write(fw)
+	return fw.err
+}
+
+// AssignIDs assigns unique local IDs to unnamed function parameters, basic
+// blocks and local variables of the function. An error is returned if the body
+// of the function could not be materialized, or if the local ID of a function
+// parameter, basic block or local variable is out of sequence.
+func (f *Function) AssignIDs() error {
+	// Materialize lazily parsed function body.
+	if err := f.Materialize(); err != nil {
+		return errors.Errorf("unable to materialize body of function %s; %v", f.Ident(), err)
+	}
 	f.mu.Lock()
-	assignIDs(f)
-	f.mu.Unlock()
+	defer f.mu.Unlock()
+	return assignIDs(f, false)
+}
+
+// renumberIDs assigns unique local IDs to unnamed function parameters, basic
+// blocks and local variables of the function, renumbering local IDs which are
+// out of sequence. An error is returned if the body of the function could not
+// be materialized.
+func (f *Function) renumberIDs() error {
+	// Materialize lazily parsed function body.
+	if err := f.Materialize(); err != nil {
+		return errors.Errorf("unable to materialize body of function %s; %v", f.Ident(), err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Renumbering local IDs never fails.
+	return assignIDs(f, true)
+}
+
+// write writes the LLVM syntax representation of the function definition or
+// declaration to w, based on the already assigned local IDs of the function.
+func (f *Function) write(w *writer) {
+	callconv, sig, md := f.header()
+
+	// Function definition.
+	if len(f.Blocks) > 0 {
+		w.Printf("define%s %s%s {\n", callconv, sig, md)
+		for _, block := range f.Blocks {
+			block.write(w)
+			w.WriteString("\n")
+		}
+		if c := f.Parent.comments(f); c != nil {
+			writeLines(w, c.Footer, "\t")
+		}
+		w.WriteString("}")
+		return
+	}
 
+	// External function declaration.
+	w.Printf("declare%s%s %s", md, callconv, sig)
+}
+
+// writeError writes the LLVM syntax representation of the function definition
+// to w, with the given error encountered while materializing the body of the
+// function written as a comment in place of the body.
+func (f *Function) writeError(w *writer, err error) {
+	callconv, sig, md := f.header()
+	w.Printf("define%s %s%s {\n", callconv, sig, md)
+	for _, line := range strings.Split(err.Error(), "\n") {
+		w.Printf("; %s\n", line)
+	}
+	w.WriteString("}")
+}
+
+// header returns the calling convention, signature and metadata attachments of
+// the function, as written in the function header.
+func (f *Function) header() (callconv, sig, md string) {
 	// Calling convention.
-	callconv := ""
 	if f.CallConv != CallConvNone {
 		callconv = fmt.Sprintf(" %s", f.CallConv)
 	}
 
 	// Function signature.
-	sig := &bytes.Buffer{}
-	fmt.Fprintf(sig, "%s %s(",
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %s(",
 		f.Sig.Ret,
 		f.Ident())
 	params := f.Params()
 	for i, param := range params {
 		if i != 0 {
-			sig.WriteString(", ")
+			buf.WriteString(", ")
 		}
 		// Use same output format as Clang. Don't output local ID for unnamed
 		// function parameters.
 		if len(param.Name) > 0 && !isLocalID(param.Name) {
-			fmt.Fprintf(sig, "%s %s",
+			fmt.Fprintf(buf, "%s %s",
 				param.Type(),
 				param.Ident())
 		} else {
-			sig.WriteString(param.Type().String())
+			buf.WriteString(param.Type().String())
 		}
 	}
 	if f.Sig.Variadic {
 		if len(params) > 0 {
-			sig.WriteString(", ")
+			buf.WriteString(", ")
 		}
-		sig.WriteString("...")
+		buf.WriteString("...")
 	}
-	sig.WriteString(")")
+	buf.WriteString(")")
 
 	// Metadata.
-	md := metadataString(f.Metadata, "")
+	md = metadataString(f.Metadata, "")
+	return callconv, buf.String(), md
+}
 
-	// Function definition.
-	if len(f.Blocks) > 0 {
-		buf := &bytes.Buffer{}
-		fmt.Fprintf(buf, "define%s %s%s {\n", callconv, sig, md)
-		for _, block := range f.Blocks {
-			fmt.Fprintln(buf, block)
-		}
-		buf.WriteString("}")
-		return buf.String()
+// SetMaterializer sets the function used to lazily materialize the body of the
+// function on first access. A nil materializer indicates that the body of the
+// function is already present.
+//
+// The basic blocks of a function with a pending materializer are not present
+// until materialized, either explicitly through Materialize or implicitly when
+// printing the function or traversing it using irutil.Walk.
+//
+// The materializer is recorded by the parent module of the function, which
+// must be set.
+func (f *Function) SetMaterializer(materialize func(f *Function) error) {
+	if f.Parent == nil {
+		panic(fmt.Errorf("unable to set materializer of function %s; missing parent module", f.Ident()))
 	}
+	f.Parent.setMaterializer(f, materialize)
+}
 
-	// External function declaration.
-	return fmt.Sprintf("declare%s%s %s", md, callconv, sig)
+// IsMaterializable reports whether the body of the function has yet to be
+// materialized.
+func (f *Function) IsMaterializable() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Parent != nil && f.Parent.materializer(f) != nil
+}
+
+// Materialize materializes the body of the function, if lazily parsed. It is a
+// no-op if the body of the function is already present.
+func (f *Function) Materialize() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Parent == nil {
+		return nil
+	}
+	materialize := f.Parent.materializer(f)
+	if materialize == nil {
+		return nil
+	}
+	f.Parent.setMaterializer(f, nil)
+	if err := materialize(f); err != nil {
+		// Allow retry after failed materialization.
+		f.Parent.setMaterializer(f, materialize)
+		return err
+	}
+	return nil
+}
+
+// MustMaterialize materializes the body of the function, if lazily parsed. It
+// panics if materialization fails.
+func (f *Function) MustMaterialize() {
+	if err := f.Materialize(); err != nil {
+		panic(fmt.Errorf("unable to materialize body of function %s; %v", f.Ident(), err))
+	}
 }
 
 // Params returns the parameters of the function.
@@ -155,12 +313,14 @@ func (f *Function) Params() []*types.Param {
 // AppendParam appends the given function parameter to the function.
 func (f *Function) AppendParam(param *types.Param) {
 	f.Sig.Params = append(f.Sig.Params, param)
+	f.indexLocal(param)
 }
 
 // NewParam appends a new function parameter to the function based on the given
-// parameter name and type.
+// parameter name and type. A numeric suffix is appended to the parameter name
+// if already present (e.g. "x.1").
 func (f *Function) NewParam(name string, typ types.Type) *types.Param {
-	param := types.NewParam(name, typ)
+	param := types.NewParam(f.uniqueLocalName(name), typ)
 	f.AppendParam(param)
 	return param
 }
@@ -169,12 +329,14 @@ func (f *Function) NewParam(name string, typ types.Type) *types.Param {
 func (f *Function) AppendBlock(block *BasicBlock) {
 	block.Parent = f
 	f.Blocks = append(f.Blocks, block)
+	f.indexLocal(block)
 }
 
 // NewBlock appends a new basic block to the function based on the given label
-// name. An empty label name indicates an unnamed basic block.
+// name. An empty label name indicates an unnamed basic block. A numeric suffix
+// is appended to the label name if already present (e.g. "loop.1").
 func (f *Function) NewBlock(name string) *BasicBlock {
-	block := NewBlock(name)
+	block := NewBlock(f.uniqueLocalName(name))
 	f.AppendBlock(block)
 	return block
 }
@@ -190,36 +352,41 @@ func NewParam(name string, typ types.Type) *types.Param {
 // ### [ Helper functions ] ####################################################
 
 // assignIDs assigns unique local IDs to unnamed basic blocks and local
-// variables of the function.
-func assignIDs(f *Function) {
+// variables of the function. If renumber is set, local IDs which are out of
+// sequence are renumbered; otherwise, an error is returned.
+func assignIDs(f *Function, renumber bool) error {
 	id := 0
-	names := make(map[string]value.Value)
-	setName := func(n value.Named) {
+	setName := func(n value.Named) error {
 		name := n.GetName()
 		switch {
 		case isUnnamed(name):
-			name := strconv.Itoa(id)
-			n.SetName(name)
-			names[name] = n
+			n.SetName(strconv.Itoa(id))
 			id++
 		case isLocalID(name):
 			want := strconv.Itoa(id)
 			if name != want {
-				//pretty.Println("names:", names)
-				panic(fmt.Errorf("invalid local ID in function %s; expected %s, got %s\n\t`%v`", enc.Global(f.Name), enc.Local(want), enc.Local(name), n))
+				if !renumber {
+					return errors.Errorf("invalid local ID in function %s; expected %s, got %s\n\t`%v`", enc.Global(f.Name), enc.Local(want), enc.Local(name), n)
+				}
+				n.SetName(want)
 			}
 			id++
 		}
+		return nil
 	}
 	for _, param := range f.Params() {
 		// Assign local IDs to unnamed parameters of function definitions.
 		if len(f.Blocks) > 0 {
-			setName(param)
+			if err := setName(param); err != nil {
+				return err
+			}
 		}
 	}
 	for _, block := range f.Blocks {
 		// Assign local IDs to unnamed basic blocks.
-		setName(block)
+		if err := setName(block); err != nil {
+			return err
+		}
 		for _, inst := range block.Insts {
 			n, ok := inst.(value.Named)
 			if !ok {
@@ -229,9 +396,12 @@ func assignIDs(f *Function) {
 				continue
 			}
 			// Assign local IDs to unnamed local variables.
-			setName(n)
+			if err := setName(n); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // isUnnamed reports whether the given identifier is unnamed.
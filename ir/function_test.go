@@ -0,0 +1,58 @@
+package ir_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestAssignIDs(t *testing.T) {
+	const src = `
+define i32 @f(i32) {
+	%2 = add i32 %0, 1
+	%3 = mul i32 %2, 2
+	%4 = sub i32 %3, 3
+	ret i32 %4
+}
+`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := m.Funcs[0]
+	entry := f.Blocks[0]
+	// Remove %3, leaving the local ID of %4 out of sequence.
+	mul := entry.Insts[1].(*ir.InstMul)
+	entry.Insts[2].(*ir.InstSub).X = mul.X
+	ir.Remove(mul)
+	if err := f.AssignIDs(); err == nil || !strings.Contains(err.Error(), "invalid local ID in function @f; expected %3, got %4") {
+		t.Errorf("local ID error mismatch; got %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err == nil {
+		t.Errorf("expected local ID error, got nil")
+	}
+	// String renumbers local IDs which are out of sequence.
+	const want = `define i32 @f(i32) {
+; <label>:1
+	%2 = add i32 %0, 1
+	%3 = sub i32 %2, 3
+	ret i32 %3
+}`
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Errorf("unexpected error after renumbering; %v", err)
+	}
+	buf.Reset()
+	if err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("function mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+}
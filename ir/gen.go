@@ -20,36 +20,41 @@ import (
 func main() {
 	binaryInsts := []*Instruction{
 		{
-			Name: "Add",
-			Desc: "an addition",
+			Name:          "Add",
+			Desc:          "an addition",
+			OverflowFlags: true,
 		},
 		{
 			Name: "FAdd",
 			Desc: "a floating-point addition",
 		},
 		{
-			Name: "Sub",
-			Desc: "a subtraction",
+			Name:          "Sub",
+			Desc:          "a subtraction",
+			OverflowFlags: true,
 		},
 		{
 			Name: "FSub",
 			Desc: "a floating-point subtraction",
 		},
 		{
-			Name: "Mul",
-			Desc: "a multiplication",
+			Name:          "Mul",
+			Desc:          "a multiplication",
+			OverflowFlags: true,
 		},
 		{
 			Name: "FMul",
 			Desc: "a floating-point multiplication",
 		},
 		{
-			Name: "UDiv",
-			Desc: "an unsigned division",
+			Name:  "UDiv",
+			Desc:  "an unsigned division",
+			Exact: true,
 		},
 		{
-			Name: "SDiv",
-			Desc: "a signed division",
+			Name:  "SDiv",
+			Desc:  "a signed division",
+			Exact: true,
 		},
 		{
 			Name: "FDiv",
@@ -70,16 +75,19 @@ func main() {
 	}
 	bitwiseInsts := []*Instruction{
 		{
-			Name: "Shl",
-			Desc: "a shift left",
+			Name:          "Shl",
+			Desc:          "a shift left",
+			OverflowFlags: true,
 		},
 		{
-			Name: "LShr",
-			Desc: "a logical shift right",
+			Name:  "LShr",
+			Desc:  "a logical shift right",
+			Exact: true,
 		},
 		{
-			Name: "AShr",
-			Desc: "an arithmetic shift right",
+			Name:  "AShr",
+			Desc:  "an arithmetic shift right",
+			Exact: true,
 		},
 		{
 			Name: "And",
@@ -222,6 +230,10 @@ type Instruction struct {
 	Name string
 	// Instruction description; e.g. `a shift left`.
 	Desc string
+	// Instruction supports the nuw and nsw overflow flags.
+	OverflowFlags bool
+	// Instruction supports the exact flag.
+	Exact bool
 }
 
 // gen generates a source file containing the instructions of the given
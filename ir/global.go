@@ -6,10 +6,10 @@
 package ir
 
 import (
-	"bytes"
-	"fmt"
+	"io"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -84,6 +84,7 @@ func (global *Global) GetName() string {
 // SetName sets the name of the global variable.
 func (global *Global) SetName(name string) {
 	global.Name = name
+	rename.Globals.Renamed()
 }
 
 // Immutable ensures that only constants can be assigned to the
@@ -96,30 +97,40 @@ func (*Global) MetadataNode() {}
 
 // String returns the LLVM syntax representation of the global variable.
 func (global *Global) String() string {
-	imm := "global"
-	if global.IsConst {
-		imm = "constant"
+	return textString(global)
+}
+
+// writeText writes the LLVM syntax representation of the global variable to w.
+func (global *Global) writeText(w *writer) {
+	w.ident(global)
+	w.WriteString(" =")
+	if global.Init == nil {
+		// External global variable declaration.
+		w.WriteString(" external")
 	}
-	md := metadataString(global.Metadata, ",")
-	addrspace := &bytes.Buffer{}
 	if global.Typ.AddrSpace != 0 {
-		fmt.Fprintf(addrspace, " addrspace(%d)", global.Typ.AddrSpace)
+		w.WriteString(" addrspace(")
+		w.int(int64(global.Typ.AddrSpace))
+		w.WriteString(")")
+	}
+	if global.IsConst {
+		w.WriteString(" constant ")
+	} else {
+		w.WriteString(" global ")
 	}
 	if global.Init != nil {
 		// Global variable definition.
-		return fmt.Sprintf("%s =%s %s %s %s%s",
-			global.Ident(),
-			addrspace,
-			imm,
-			global.Init.Type(),
-			global.Init.Ident(),
-			md)
+		w.typedIdent(global.Init)
+	} else {
+		w.typ(global.Content)
 	}
-	// External global variable declaration.
-	return fmt.Sprintf("%s = external%s %s %s%s",
-		global.Ident(),
-		addrspace,
-		imm,
-		global.Content,
-		md)
+	w.metadata(global.Metadata, ",")
+}
+
+// WriteTo writes the LLVM syntax representation of the global variable to w.
+// The number of bytes written and any error encountered are returned.
+func (global *Global) WriteTo(w io.Writer) (n int64, err error) {
+	gw := newWriter(w)
+	global.writeText(gw)
+	return gw.n, gw.err
 }
@@ -6,9 +6,6 @@
 package ir
 
 import (
-	"bytes"
-	"fmt"
-
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -71,22 +68,26 @@ func (inst *InstExtractValue) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstExtractValue) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstExtractValue) String() string {
-	indices := &bytes.Buffer{}
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstExtractValue) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = extractvalue ")
+	w.typedIdent(inst.X)
 	for _, index := range inst.Indices {
-		fmt.Fprintf(indices, ", %d", index)
+		w.WriteString(", ")
+		w.int(index)
 	}
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = extractvalue %s %s%s%s",
-		inst.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		indices,
-		md)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -99,6 +100,11 @@ func (inst *InstExtractValue) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstExtractValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
 // --- [ insertvalue ] ---------------------------------------------------------
 
 // InstInsertValue represents an insertvalue instruction.
@@ -150,24 +156,28 @@ func (inst *InstInsertValue) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstInsertValue) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstInsertValue) String() string {
-	indices := &bytes.Buffer{}
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstInsertValue) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = insertvalue ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.typedIdent(inst.Elem)
 	for _, index := range inst.Indices {
-		fmt.Fprintf(indices, ", %d", index)
+		w.WriteString(", ")
+		w.int(index)
 	}
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = insertvalue %s %s, %s %s%s%s",
-		inst.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Elem.Type(),
-		inst.Elem.Ident(),
-		indices,
-		md)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -180,6 +190,11 @@ func (inst *InstInsertValue) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstInsertValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem}
+}
+
 // ### [ Helper functions ] ####################################################
 
 // aggregateElemType returns the element type of the given aggregate type, based
@@ -8,8 +8,6 @@
 package ir
 
 import (
-	"fmt"
-
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -29,6 +27,10 @@ type InstAdd struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -61,18 +63,33 @@ func (inst *InstAdd) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstAdd) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstAdd) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = add %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstAdd) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = add")
+	if inst.NUW {
+		w.WriteString(" nuw")
+	}
+	if inst.NSW {
+		w.WriteString(" nsw")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -85,6 +102,11 @@ func (inst *InstAdd) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ fadd ] ----------------------------------------------------------------
 
 // InstFAdd represents a floating-point addition instruction.
@@ -130,18 +152,26 @@ func (inst *InstFAdd) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFAdd) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFAdd) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fadd %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFAdd) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fadd ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -154,6 +184,11 @@ func (inst *InstFAdd) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ sub ] -----------------------------------------------------------------
 
 // InstSub represents a subtraction instruction.
@@ -167,6 +202,10 @@ type InstSub struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -199,18 +238,33 @@ func (inst *InstSub) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSub) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSub) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = sub %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSub) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = sub")
+	if inst.NUW {
+		w.WriteString(" nuw")
+	}
+	if inst.NSW {
+		w.WriteString(" nsw")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -223,6 +277,11 @@ func (inst *InstSub) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ fsub ] ----------------------------------------------------------------
 
 // InstFSub represents a floating-point subtraction instruction.
@@ -268,18 +327,26 @@ func (inst *InstFSub) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFSub) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFSub) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fsub %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFSub) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fsub ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -292,6 +359,11 @@ func (inst *InstFSub) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ mul ] -----------------------------------------------------------------
 
 // InstMul represents a multiplication instruction.
@@ -305,6 +377,10 @@ type InstMul struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -337,18 +413,33 @@ func (inst *InstMul) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstMul) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstMul) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = mul %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstMul) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = mul")
+	if inst.NUW {
+		w.WriteString(" nuw")
+	}
+	if inst.NSW {
+		w.WriteString(" nsw")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -361,6 +452,11 @@ func (inst *InstMul) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ fmul ] ----------------------------------------------------------------
 
 // InstFMul represents a floating-point multiplication instruction.
@@ -406,18 +502,26 @@ func (inst *InstFMul) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFMul) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFMul) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fmul %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFMul) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fmul ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -430,6 +534,11 @@ func (inst *InstFMul) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ udiv ] ----------------------------------------------------------------
 
 // InstUDiv represents an unsigned division instruction.
@@ -443,6 +552,8 @@ type InstUDiv struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -475,18 +586,30 @@ func (inst *InstUDiv) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstUDiv) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstUDiv) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = udiv %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstUDiv) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = udiv")
+	if inst.Exact {
+		w.WriteString(" exact")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -499,6 +622,11 @@ func (inst *InstUDiv) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstUDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ sdiv ] ----------------------------------------------------------------
 
 // InstSDiv represents a signed division instruction.
@@ -512,6 +640,8 @@ type InstSDiv struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -544,18 +674,30 @@ func (inst *InstSDiv) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSDiv) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSDiv) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = sdiv %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSDiv) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = sdiv")
+	if inst.Exact {
+		w.WriteString(" exact")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -568,6 +710,11 @@ func (inst *InstSDiv) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ fdiv ] ----------------------------------------------------------------
 
 // InstFDiv represents a floating-point division instruction.
@@ -613,18 +760,26 @@ func (inst *InstFDiv) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFDiv) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFDiv) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fdiv %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFDiv) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fdiv ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -637,6 +792,11 @@ func (inst *InstFDiv) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ urem ] ----------------------------------------------------------------
 
 // InstURem represents an unsigned remainder instruction.
@@ -682,18 +842,26 @@ func (inst *InstURem) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstURem) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstURem) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = urem %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstURem) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = urem ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -706,6 +874,11 @@ func (inst *InstURem) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstURem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ srem ] ----------------------------------------------------------------
 
 // InstSRem represents a signed remainder instruction.
@@ -751,18 +924,26 @@ func (inst *InstSRem) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSRem) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSRem) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = srem %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSRem) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = srem ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -775,6 +956,11 @@ func (inst *InstSRem) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ frem ] ----------------------------------------------------------------
 
 // InstFRem represents a floating-point remainder instruction.
@@ -820,18 +1006,26 @@ func (inst *InstFRem) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFRem) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFRem) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = frem %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFRem) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = frem ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -843,3 +1037,8 @@ func (inst *InstFRem) GetParent() *BasicBlock {
 func (inst *InstFRem) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
@@ -8,8 +8,6 @@
 package ir
 
 import (
-	"fmt"
-
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -29,6 +27,10 @@ type InstShl struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// No unsigned wrap; the result is a poison value on unsigned overflow.
+	NUW bool
+	// No signed wrap; the result is a poison value on signed overflow.
+	NSW bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -61,18 +63,33 @@ func (inst *InstShl) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstShl) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstShl) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = shl %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstShl) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = shl")
+	if inst.NUW {
+		w.WriteString(" nuw")
+	}
+	if inst.NSW {
+		w.WriteString(" nsw")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -85,6 +102,11 @@ func (inst *InstShl) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstShl) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ lshr ] ----------------------------------------------------------------
 
 // InstLShr represents a logical shift right instruction.
@@ -98,6 +120,8 @@ type InstLShr struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -130,18 +154,30 @@ func (inst *InstLShr) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstLShr) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstLShr) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = lshr %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstLShr) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = lshr")
+	if inst.Exact {
+		w.WriteString(" exact")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -154,6 +190,11 @@ func (inst *InstLShr) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstLShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ ashr ] ----------------------------------------------------------------
 
 // InstAShr represents an arithmetic shift right instruction.
@@ -167,6 +208,8 @@ type InstAShr struct {
 	Name string
 	// Operands.
 	X, Y value.Value
+	// Exact; the result is a poison value if the result is inexact.
+	Exact bool
 	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
 	// instruction.
 	Metadata map[string]*metadata.Metadata
@@ -199,18 +242,30 @@ func (inst *InstAShr) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstAShr) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstAShr) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = ashr %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstAShr) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = ashr")
+	if inst.Exact {
+		w.WriteString(" exact")
+	}
+	w.WriteString(" ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -223,6 +278,11 @@ func (inst *InstAShr) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstAShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ and ] -----------------------------------------------------------------
 
 // InstAnd represents an AND instruction.
@@ -268,18 +328,26 @@ func (inst *InstAnd) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstAnd) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstAnd) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = and %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstAnd) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = and ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -292,6 +360,11 @@ func (inst *InstAnd) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstAnd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ or ] ------------------------------------------------------------------
 
 // InstOr represents an OR instruction.
@@ -337,18 +410,26 @@ func (inst *InstOr) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstOr) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstOr) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = or %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstOr) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = or ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -361,6 +442,11 @@ func (inst *InstOr) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstOr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // --- [ xor ] -----------------------------------------------------------------
 
 // InstXor represents an exclusive-OR instruction.
@@ -406,18 +492,26 @@ func (inst *InstXor) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstXor) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstXor) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = xor %s %s, %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstXor) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = xor ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
+	w.ident(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -429,3 +523,8 @@ func (inst *InstXor) GetParent() *BasicBlock {
 func (inst *InstXor) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstXor) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
@@ -8,8 +8,6 @@
 package ir
 
 import (
-	"fmt"
-
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -63,18 +61,24 @@ func (inst *InstTrunc) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstTrunc) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstTrunc) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = trunc %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstTrunc) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = trunc ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -87,6 +91,11 @@ func (inst *InstTrunc) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ zext ] ----------------------------------------------------------------
 
 // InstZExt represents a zero extension instruction.
@@ -134,18 +143,24 @@ func (inst *InstZExt) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstZExt) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstZExt) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = zext %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstZExt) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = zext ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -158,6 +173,11 @@ func (inst *InstZExt) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstZExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ sext ] ----------------------------------------------------------------
 
 // InstSExt represents a sign extension instruction.
@@ -205,18 +225,24 @@ func (inst *InstSExt) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSExt) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSExt) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = sext %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSExt) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = sext ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -229,6 +255,11 @@ func (inst *InstSExt) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ fptrunc ] -------------------------------------------------------------
 
 // InstFPTrunc represents a floating-point truncation instruction.
@@ -276,18 +307,24 @@ func (inst *InstFPTrunc) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFPTrunc) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFPTrunc) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fptrunc %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFPTrunc) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fptrunc ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -300,6 +337,11 @@ func (inst *InstFPTrunc) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFPTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ fpext ] ---------------------------------------------------------------
 
 // InstFPExt represents a floating-point extension instruction.
@@ -347,18 +389,24 @@ func (inst *InstFPExt) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFPExt) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFPExt) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fpext %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFPExt) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fpext ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -371,6 +419,11 @@ func (inst *InstFPExt) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFPExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ fptoui ] --------------------------------------------------------------
 
 // InstFPToUI represents a floating-point to unsigned integer conversion instruction.
@@ -418,18 +471,24 @@ func (inst *InstFPToUI) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFPToUI) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFPToUI) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fptoui %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFPToUI) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fptoui ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -442,6 +501,11 @@ func (inst *InstFPToUI) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFPToUI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ fptosi ] --------------------------------------------------------------
 
 // InstFPToSI represents a floating-point to signed integer conversion instruction.
@@ -489,18 +553,24 @@ func (inst *InstFPToSI) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFPToSI) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFPToSI) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fptosi %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFPToSI) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fptosi ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -513,6 +583,11 @@ func (inst *InstFPToSI) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFPToSI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ uitofp ] --------------------------------------------------------------
 
 // InstUIToFP represents an unsigned integer to floating-point conversion instruction.
@@ -560,18 +635,24 @@ func (inst *InstUIToFP) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstUIToFP) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstUIToFP) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = uitofp %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstUIToFP) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = uitofp ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -584,6 +665,11 @@ func (inst *InstUIToFP) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstUIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ sitofp ] --------------------------------------------------------------
 
 // InstSIToFP represents a signed integer to floating-point conversion instruction.
@@ -631,18 +717,24 @@ func (inst *InstSIToFP) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSIToFP) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSIToFP) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = sitofp %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSIToFP) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = sitofp ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -655,6 +747,11 @@ func (inst *InstSIToFP) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ ptrtoint ] ------------------------------------------------------------
 
 // InstPtrToInt represents a pointer to integer conversion instruction.
@@ -702,18 +799,24 @@ func (inst *InstPtrToInt) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstPtrToInt) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstPtrToInt) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = ptrtoint %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstPtrToInt) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = ptrtoint ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -726,6 +829,11 @@ func (inst *InstPtrToInt) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstPtrToInt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ inttoptr ] ------------------------------------------------------------
 
 // InstIntToPtr represents an integer to pointer conversion instruction.
@@ -773,18 +881,24 @@ func (inst *InstIntToPtr) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstIntToPtr) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstIntToPtr) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = inttoptr %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstIntToPtr) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = inttoptr ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -797,6 +911,11 @@ func (inst *InstIntToPtr) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstIntToPtr) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ bitcast ] -------------------------------------------------------------
 
 // InstBitCast represents a bitcast instruction.
@@ -844,18 +963,24 @@ func (inst *InstBitCast) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstBitCast) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstBitCast) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = bitcast %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstBitCast) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = bitcast ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -868,6 +993,11 @@ func (inst *InstBitCast) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstBitCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
 // --- [ addrspacecast ] -------------------------------------------------------
 
 // InstAddrSpaceCast represents an address space cast instruction.
@@ -915,18 +1045,24 @@ func (inst *InstAddrSpaceCast) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstAddrSpaceCast) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstAddrSpaceCast) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = addrspacecast %s %s to %s%s",
-		inst.Ident(),
-		inst.From.Type(),
-		inst.From.Ident(),
-		inst.To,
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstAddrSpaceCast) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = addrspacecast ")
+	w.typedIdent(inst.From)
+	w.WriteString(" to ")
+	w.typ(inst.To)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -938,3 +1074,8 @@ func (inst *InstAddrSpaceCast) GetParent() *BasicBlock {
 func (inst *InstAddrSpaceCast) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstAddrSpaceCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
@@ -6,7 +6,6 @@
 package ir
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/llir/llvm/internal/enc"
@@ -66,24 +65,26 @@ func (inst *InstAlloca) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstAlloca) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstAlloca) String() string {
-	md := metadataString(inst.Metadata, ",")
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstAlloca) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = alloca ")
+	w.typ(inst.Elem)
 	if inst.NElems != nil {
-		return fmt.Sprintf("%s = alloca %s, %s %s%s",
-			inst.Ident(),
-			inst.Elem,
-			inst.NElems.Type(),
-			inst.NElems.Ident(),
-			md)
+		w.WriteString(", ")
+		w.typedIdent(inst.NElems)
 	}
-	return fmt.Sprintf("%s = alloca %s%s",
-		inst.Ident(),
-		inst.Elem,
-		md)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -96,6 +97,14 @@ func (inst *InstAlloca) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstAlloca) Operands() []*value.Value {
+	if inst.NElems != nil {
+		return []*value.Value{&inst.NElems}
+	}
+	return nil
+}
+
 // --- [ load ] ----------------------------------------------------------------
 
 // InstLoad represents a load instruction.
@@ -147,18 +156,24 @@ func (inst *InstLoad) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstLoad) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstLoad) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = load %s, %s %s%s",
-		inst.Ident(),
-		inst.Type(),
-		inst.Src.Type(),
-		inst.Src.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstLoad) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = load ")
+	w.typ(inst.Type())
+	w.WriteString(", ")
+	w.typedIdent(inst.Src)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -171,6 +186,11 @@ func (inst *InstLoad) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstLoad) Operands() []*value.Value {
+	return []*value.Value{&inst.Src}
+}
+
 // --- [ store ] ---------------------------------------------------------------
 
 // InstStore represents a store instruction.
@@ -201,13 +221,16 @@ func NewStore(src, dst value.Value) *InstStore {
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstStore) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("store %s %s, %s %s%s",
-		inst.Src.Type(),
-		inst.Src.Ident(),
-		inst.Dst.Type(),
-		inst.Dst.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstStore) writeText(w *writer) {
+	w.WriteString("store ")
+	w.typedIdent(inst.Src)
+	w.WriteString(", ")
+	w.typedIdent(inst.Dst)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -220,6 +243,11 @@ func (inst *InstStore) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstStore) Operands() []*value.Value {
+	return []*value.Value{&inst.Src, &inst.Dst}
+}
+
 // --- [ fence ] ---------------------------------------------------------------
 
 // --- [ cmpxchg ] -------------------------------------------------------------
@@ -273,6 +301,8 @@ func NewGetElementPtr(src value.Value, indices ...value.Value) *InstGetElementPt
 			panic("unable to index into element of pointer type; for more information, see http://llvm.org/docs/GetElementPtr.html#what-is-dereferenced-by-gep")
 		case *types.ArrayType:
 			e = t.Elem
+		case *types.VectorType:
+			e = t.Elem
 		case *types.StructType:
 			idx, ok := index.(*constant.Int)
 			if !ok {
@@ -311,25 +341,28 @@ func (inst *InstGetElementPtr) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstGetElementPtr) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstGetElementPtr) String() string {
-	indices := &bytes.Buffer{}
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstGetElementPtr) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = getelementptr ")
+	w.typ(inst.Elem)
+	w.WriteString(", ")
+	w.typedIdent(inst.Src)
 	for _, index := range inst.Indices {
-		fmt.Fprintf(indices, ", %s %s",
-			index.Type(),
-			index.Ident())
+		w.WriteString(", ")
+		w.typedIdent(index)
 	}
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = getelementptr %s, %s %s%s%s",
-		inst.Ident(),
-		inst.Elem,
-		inst.Src.Type(),
-		inst.Src.Ident(),
-		indices,
-		md)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -341,3 +374,12 @@ func (inst *InstGetElementPtr) GetParent() *BasicBlock {
 func (inst *InstGetElementPtr) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstGetElementPtr) Operands() []*value.Value {
+	operands := []*value.Value{&inst.Src}
+	for i := range inst.Indices {
+		operands = append(operands, &inst.Indices[i])
+	}
+	return operands
+}
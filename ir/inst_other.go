@@ -6,7 +6,6 @@
 package ir
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/llir/llvm/internal/enc"
@@ -71,19 +70,26 @@ func (inst *InstICmp) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstICmp) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstICmp) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = icmp %s %s %s, %s%s",
-		inst.Ident(),
-		inst.Pred,
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstICmp) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = icmp ")
+	w.WriteString(inst.Pred.String())
+	w.WriteString(" ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -96,6 +102,11 @@ func (inst *InstICmp) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstICmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // IntPred represents the set of integer predicates of the icmp instruction.
 type IntPred int
 
@@ -189,19 +200,26 @@ func (inst *InstFCmp) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstFCmp) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstFCmp) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = fcmp %s %s %s, %s%s",
-		inst.Ident(),
-		inst.Pred,
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFCmp) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = fcmp ")
+	w.WriteString(inst.Pred.String())
+	w.WriteString(" ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.ident(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -214,6 +232,11 @@ func (inst *InstFCmp) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFCmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
 // FloatPred represents the set of floating-point predicates of the fcmp
 // instruction.
 type FloatPred int
@@ -316,26 +339,33 @@ func (inst *InstPhi) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstPhi) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstPhi) String() string {
-	incs := &bytes.Buffer{}
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstPhi) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = phi ")
+	w.typ(inst.Type())
+	w.WriteString(" ")
 	for i, inc := range inst.Incs {
 		if i != 0 {
-			incs.WriteString(", ")
+			w.WriteString(", ")
 		}
-		fmt.Fprintf(incs, "[ %s, %s ]",
-			inc.X.Ident(),
-			inc.Pred.Ident())
+		w.WriteString("[ ")
+		w.ident(inc.X)
+		w.WriteString(", ")
+		w.ident(inc.Pred)
+		w.WriteString(" ]")
 	}
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = phi %s %s%s",
-		inst.Ident(),
-		inst.Type(),
-		incs,
-		md)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -348,6 +378,15 @@ func (inst *InstPhi) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstPhi) Operands() []*value.Value {
+	var operands []*value.Value
+	for _, inc := range inst.Incs {
+		operands = append(operands, &inc.X)
+	}
+	return operands
+}
+
 // Incoming represents an incoming value of a phi instruction.
 type Incoming struct {
 	// Incoming value.
@@ -414,21 +453,26 @@ func (inst *InstSelect) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstSelect) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstSelect) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = select %s %s, %s %s, %s %s%s",
-		inst.Ident(),
-		inst.Cond.Type(),
-		inst.Cond.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Y.Type(),
-		inst.Y.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstSelect) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = select ")
+	w.typedIdent(inst.Cond)
+	w.WriteString(", ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.typedIdent(inst.Y)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -441,6 +485,90 @@ func (inst *InstSelect) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstSelect) Operands() []*value.Value {
+	return []*value.Value{&inst.Cond, &inst.X, &inst.Y}
+}
+
+// --- [ freeze ] --------------------------------------------------------------
+
+// InstFreeze represents a freeze instruction, which stops the propagation of
+// undef and poison values. The result is the operand if it is neither undef nor
+// poison; and otherwise an arbitrary but fixed value of the operand type.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#freeze-instruction
+type InstFreeze struct {
+	// Parent basic block.
+	Parent *BasicBlock
+	// Name of the local variable associated with the instruction.
+	Name string
+	// Operand.
+	X value.Value
+	// Map from metadata identifier (e.g. !dbg) to metadata associated with the
+	// instruction.
+	Metadata map[string]*metadata.Metadata
+}
+
+// NewFreeze returns a new freeze instruction based on the given operand.
+func NewFreeze(x value.Value) *InstFreeze {
+	return &InstFreeze{
+		X:        x,
+		Metadata: make(map[string]*metadata.Metadata),
+	}
+}
+
+// Type returns the type of the instruction.
+func (inst *InstFreeze) Type() types.Type {
+	return inst.X.Type()
+}
+
+// Ident returns the identifier associated with the instruction.
+func (inst *InstFreeze) Ident() string {
+	return enc.Local(inst.Name)
+}
+
+// GetName returns the name of the local variable associated with the
+// instruction.
+func (inst *InstFreeze) GetName() string {
+	return inst.Name
+}
+
+// SetName sets the name of the local variable associated with the instruction.
+func (inst *InstFreeze) SetName(name string) {
+	oldName := inst.Name
+	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
+}
+
+// String returns the LLVM syntax representation of the instruction.
+func (inst *InstFreeze) String() string {
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstFreeze) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = freeze ")
+	w.typedIdent(inst.X)
+	w.metadata(inst.Metadata, ",")
+}
+
+// GetParent returns the parent basic block of the instruction.
+func (inst *InstFreeze) GetParent() *BasicBlock {
+	return inst.Parent
+}
+
+// SetParent sets the parent basic block of the instruction.
+func (inst *InstFreeze) SetParent(parent *BasicBlock) {
+	inst.Parent = parent
+}
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstFreeze) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
 // --- [ call ] ----------------------------------------------------------------
 
 // InstCall represents a call instruction.
@@ -518,42 +646,45 @@ func (inst *InstCall) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstCall) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstCall) String() string {
-	ident := &bytes.Buffer{}
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstCall) writeText(w *writer) {
 	if !inst.Type().Equal(types.Void) {
-		fmt.Fprintf(ident, "%s = ", inst.Ident())
+		w.ident(inst)
+		w.WriteString(" = ")
 	}
-	// Print callee signature instead of return type for variadic callees.
-	callconv := &bytes.Buffer{}
+	w.WriteString("call")
 	if inst.CallConv != CallConvNone {
-		fmt.Fprintf(callconv, " %s", inst.CallConv)
+		w.WriteString(" ")
+		w.WriteString(inst.CallConv.String())
 	}
-	sig := inst.Sig
-	ret := sig.Ret.String()
-	if sig.Variadic {
-		ret = sig.String()
+	w.WriteString(" ")
+	// Print callee signature instead of return type for variadic callees.
+	if sig := inst.Sig; sig.Variadic {
+		w.WriteString(sig.String())
+	} else {
+		w.typ(sig.Ret)
 	}
-	args := &bytes.Buffer{}
+	w.WriteString(" ")
+	w.ident(inst.Callee)
+	w.WriteString("(")
 	for i, arg := range inst.Args {
 		if i != 0 {
-			args.WriteString(", ")
+			w.WriteString(", ")
 		}
-		fmt.Fprintf(args, "%s %s",
-			arg.Type(),
-			arg.Ident())
+		w.typedIdent(arg)
 	}
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%scall%s %s %s(%s)%s",
-		ident,
-		callconv,
-		ret,
-		inst.Callee.Ident(),
-		args,
-		md)
+	w.WriteString(")")
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -566,6 +697,15 @@ func (inst *InstCall) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstCall) Operands() []*value.Value {
+	operands := []*value.Value{&inst.Callee}
+	for i := range inst.Args {
+		operands = append(operands, &inst.Args[i])
+	}
+	return operands
+}
+
 // --- [ va_arg ] --------------------------------------------------------------
 
 // --- [ landingpad ] ----------------------------------------------------------
@@ -69,19 +69,24 @@ func (inst *InstExtractElement) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstExtractElement) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstExtractElement) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = extractelement %s %s, %s %s%s",
-		inst.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Index.Type(),
-		inst.Index.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstExtractElement) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = extractelement ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.typedIdent(inst.Index)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -94,6 +99,11 @@ func (inst *InstExtractElement) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstExtractElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Index}
+}
+
 // --- [ insertelement ] -------------------------------------------------------
 
 // InstInsertElement represents an insertelement instruction.
@@ -145,21 +155,26 @@ func (inst *InstInsertElement) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstInsertElement) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstInsertElement) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = insertelement %s %s, %s %s, %s %s%s",
-		inst.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Elem.Type(),
-		inst.Elem.Ident(),
-		inst.Index.Type(),
-		inst.Index.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstInsertElement) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = insertelement ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.typedIdent(inst.Elem)
+	w.WriteString(", ")
+	w.typedIdent(inst.Index)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -172,6 +187,11 @@ func (inst *InstInsertElement) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstInsertElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem, &inst.Index}
+}
+
 // --- [ shufflevector ] -------------------------------------------------------
 
 // InstShuffleVector represents an shufflevector instruction.
@@ -183,6 +203,8 @@ type InstShuffleVector struct {
 	Parent *BasicBlock
 	// Name of the local variable associated with the instruction.
 	Name string
+	// Type of the instruction.
+	Typ *types.VectorType
 	// Vector 1.
 	X value.Value
 	// Vector 2.
@@ -197,7 +219,16 @@ type InstShuffleVector struct {
 // NewShuffleVector returns a new shufflevector instruction based on the given
 // vectors and shuffle mask.
 func NewShuffleVector(x, y, mask value.Value) *InstShuffleVector {
+	xt, ok := x.Type().(*types.VectorType)
+	if !ok {
+		panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", x.Type()))
+	}
+	mt, ok := mask.Type().(*types.VectorType)
+	if !ok {
+		panic(fmt.Errorf("invalid shuffle mask type; expected *types.VectorType, got %T", mask.Type()))
+	}
 	return &InstShuffleVector{
+		Typ:      types.NewVector(xt.Elem, mt.Len),
 		X:        x,
 		Y:        y,
 		Mask:     mask,
@@ -207,7 +238,7 @@ func NewShuffleVector(x, y, mask value.Value) *InstShuffleVector {
 
 // Type returns the type of the instruction.
 func (inst *InstShuffleVector) Type() types.Type {
-	return inst.Mask.Type()
+	return inst.Typ
 }
 
 // Ident returns the identifier associated with the instruction.
@@ -223,21 +254,26 @@ func (inst *InstShuffleVector) GetName() string {
 
 // SetName sets the name of the local variable associated with the instruction.
 func (inst *InstShuffleVector) SetName(name string) {
+	oldName := inst.Name
 	inst.Name = name
+	renameLocal(inst.Parent, inst, oldName)
 }
 
 // String returns the LLVM syntax representation of the instruction.
 func (inst *InstShuffleVector) String() string {
-	md := metadataString(inst.Metadata, ",")
-	return fmt.Sprintf("%s = shufflevector %s %s, %s %s, %s %s%s",
-		inst.Ident(),
-		inst.X.Type(),
-		inst.X.Ident(),
-		inst.Y.Type(),
-		inst.Y.Ident(),
-		inst.Mask.Type(),
-		inst.Mask.Ident(),
-		md)
+	return textString(inst)
+}
+
+// writeText writes the LLVM syntax representation of the instruction to w.
+func (inst *InstShuffleVector) writeText(w *writer) {
+	w.ident(inst)
+	w.WriteString(" = shufflevector ")
+	w.typedIdent(inst.X)
+	w.WriteString(", ")
+	w.typedIdent(inst.Y)
+	w.WriteString(", ")
+	w.typedIdent(inst.Mask)
+	w.metadata(inst.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the instruction.
@@ -249,3 +285,8 @@ func (inst *InstShuffleVector) GetParent() *BasicBlock {
 func (inst *InstShuffleVector) SetParent(parent *BasicBlock) {
 	inst.Parent = parent
 }
+
+// Operands returns a mutable list of the operands of the instruction.
+func (inst *InstShuffleVector) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y, &inst.Mask}
+}
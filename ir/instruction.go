@@ -5,7 +5,11 @@
 
 package ir
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/value"
+)
 
 // An Instruction represents a non-branching LLVM IR instruction.
 //
@@ -93,6 +97,7 @@ import "fmt"
 //    *ir.InstFCmp     (https://godoc.org/github.com/llir/llvm/ir#InstFCmp)
 //    *ir.InstPhi      (https://godoc.org/github.com/llir/llvm/ir#InstPhi)
 //    *ir.InstSelect   (https://godoc.org/github.com/llir/llvm/ir#InstSelect)
+//    *ir.InstFreeze   (https://godoc.org/github.com/llir/llvm/ir#InstFreeze)
 //    *ir.InstCall     (https://godoc.org/github.com/llir/llvm/ir#InstCall)
 type Instruction interface {
 	fmt.Stringer
@@ -100,4 +105,8 @@ type Instruction interface {
 	GetParent() *BasicBlock
 	// SetParent sets the parent basic block of the instruction.
 	SetParent(parent *BasicBlock)
+	// Operands returns a mutable list of the non-nil value operands of the
+	// instruction. Basic block operands (e.g. the predecessors of phi
+	// instructions and the targets of terminators) are not included.
+	Operands() []*value.Value
 }
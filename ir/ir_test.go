@@ -66,6 +66,7 @@ var (
 	_ ir.Instruction = &ir.InstFCmp{}
 	_ ir.Instruction = &ir.InstPhi{}
 	_ ir.Instruction = &ir.InstSelect{}
+	_ ir.Instruction = &ir.InstFreeze{}
 	_ ir.Instruction = &ir.InstCall{}
 )
 
@@ -134,6 +135,7 @@ var (
 	_ value.Named = &ir.InstFCmp{}
 	_ value.Named = &ir.InstPhi{}
 	_ value.Named = &ir.InstSelect{}
+	_ value.Named = &ir.InstFreeze{}
 	_ value.Named = &ir.InstCall{}
 )
 
@@ -0,0 +1,184 @@
+// === [ Use-def and def-use chains ] ==========================================
+
+package irutil
+
+import (
+	"sync"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+// A Use represents the use of a value as an operand of a user.
+type Use struct {
+	// Used value; an instruction, function parameter, global variable, function
+	// or basic block.
+	Val value.Named
+	// User of the value; an ir.Instruction, ir.Terminator or *ir.Global.
+	User interface{}
+	// Operand index of the use, within the operands of the user.
+	//
+	// The operands of a user consist of its non-nil value operands in field
+	// order (e.g. the incoming values of phi instructions, or the callee
+	// followed by the arguments of call instructions), followed by its basic
+	// block operands (e.g. the predecessors of phi instructions, or the default
+	// target followed by the case targets of switch terminators). The
+	// initializer of a global variable is its only operand. Case comparands of
+	// switch terminators are not considered operands.
+	Index int
+}
+
+// A UseMap records the uses of the instructions, function parameters, global
+// variables, functions and basic blocks of a module or function.
+//
+// Use lists are computed on demand on first query and cached thereafter; the
+// use map must thus be invalidated (see Invalidate) after the module or
+// function has been modified.
+//
+// Uses within constant expressions are attributed to the instruction,
+// terminator or global variable using the constant expression, with the
+// operand index of the constant expression.
+type UseMap struct {
+	// Module or function of the use map.
+	m *ir.Module
+	f *ir.Function
+	// mu prevents races on uses.
+	mu sync.Mutex
+	// uses maps from values to their uses, in order of occurrence; or nil if
+	// not yet computed.
+	uses map[value.Named][]*Use
+}
+
+// NewUseMap returns a new use map of the values of the given module.
+func NewUseMap(m *ir.Module) *UseMap {
+	return &UseMap{m: m}
+}
+
+// NewFuncUseMap returns a new use map of the values of the given function. Only
+// uses within the function are recorded.
+func NewFuncUseMap(f *ir.Function) *UseMap {
+	return &UseMap{f: f}
+}
+
+// Uses returns the uses of the given value, in order of occurrence.
+func (um *UseMap) Uses(v value.Named) []*Use {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+	if um.uses == nil {
+		um.compute()
+	}
+	return um.uses[v]
+}
+
+// Users returns the unique users of the given value, in order of first use.
+func (um *UseMap) Users(v value.Named) []interface{} {
+	var users []interface{}
+	seen := make(map[interface{}]bool)
+	for _, use := range um.Uses(v) {
+		if !seen[use.User] {
+			seen[use.User] = true
+			users = append(users, use.User)
+		}
+	}
+	return users
+}
+
+// Invalidate invalidates the cached use lists of the use map, which are
+// recomputed on next query.
+func (um *UseMap) Invalidate() {
+	um.mu.Lock()
+	um.uses = nil
+	um.mu.Unlock()
+}
+
+// compute computes the use lists of the use map.
+func (um *UseMap) compute() {
+	um.uses = make(map[value.Named][]*Use)
+	if um.f != nil {
+		um.computeFunc(um.f)
+		return
+	}
+	for _, global := range um.m.Globals {
+		if global.Init != nil {
+			um.addUse(global.Init, global, 0)
+		}
+	}
+	for _, f := range um.m.Funcs {
+		um.computeFunc(f)
+	}
+}
+
+// computeFunc records the uses within the body of the given function.
+func (um *UseMap) computeFunc(f *ir.Function) {
+	// Materialize lazily parsed function body.
+	f.MustMaterialize()
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			um.addUses(inst)
+		}
+		if block.Term != nil {
+			um.addUses(block.Term)
+		}
+	}
+}
+
+// addUses records the uses of the operands of the given user.
+func (um *UseMap) addUses(user ir.Instruction) {
+	for i, operand := range operands(user) {
+		um.addUse(operand, user, i)
+	}
+}
+
+// addUse records the use of the given operand by user at the given operand
+// index, including uses within constant expressions.
+func (um *UseMap) addUse(operand value.Value, user interface{}, index int) {
+	switch v := operand.(type) {
+	case value.Named:
+		um.uses[v] = append(um.uses[v], &Use{Val: v, User: user, Index: index})
+	case interface{ Operands() []*constant.Constant }:
+		// Constant expressions and aggregate constants.
+		for _, c := range v.Operands() {
+			um.addUse(*c, user, index)
+		}
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// operands returns the operands of the given user, as specified by Use.Index.
+func operands(user ir.Instruction) []value.Value {
+	var vs []value.Value
+	for _, operand := range user.Operands() {
+		vs = append(vs, *operand)
+	}
+	for _, block := range blockOperands(user) {
+		if block != nil {
+			vs = append(vs, block)
+		}
+	}
+	return vs
+}
+
+// blockOperands returns the basic block operands of the given user.
+func blockOperands(user ir.Instruction) []*ir.BasicBlock {
+	switch user := user.(type) {
+	case *ir.InstPhi:
+		var blocks []*ir.BasicBlock
+		for _, inc := range user.Incs {
+			blocks = append(blocks, inc.Pred)
+		}
+		return blocks
+	case *ir.TermBr:
+		return []*ir.BasicBlock{user.Target}
+	case *ir.TermCondBr:
+		return []*ir.BasicBlock{user.TargetTrue, user.TargetFalse}
+	case *ir.TermSwitch:
+		blocks := []*ir.BasicBlock{user.TargetDefault}
+		for _, c := range user.Cases {
+			blocks = append(blocks, c.Target)
+		}
+		return blocks
+	}
+	return nil
+}
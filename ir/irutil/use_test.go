@@ -0,0 +1,111 @@
+package irutil_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/irutil"
+)
+
+const useSrc = `
+@g = global i32 0
+@p = global i32* @g
+
+define i32 @f(i32 %x, i1 %c) {
+entry:
+	%y = add i32 %x, %x
+	%z = load i32, i32* getelementptr (i32, i32* @g, i64 1)
+	br i1 %c, label %a, label %b
+a:
+	br label %b
+b:
+	%w = phi i32 [ %y, %entry ], [ %z, %a ]
+	ret i32 %w
+}
+`
+
+func TestUseMap(t *testing.T) {
+	m, err := asm.ParseString(useSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, p, f := m.Globals[0], m.Globals[1], m.Funcs[0]
+	x := f.Params()[0]
+	entry, a, b := f.Blocks[0], f.Blocks[1], f.Blocks[2]
+	y := entry.Insts[0].(*ir.InstAdd)
+	z := entry.Insts[1].(*ir.InstLoad)
+	w := b.Insts[0].(*ir.InstPhi)
+	um := irutil.NewUseMap(m)
+
+	// Uses of parameter.
+	uses := um.Uses(x)
+	if len(uses) != 2 {
+		t.Fatalf("number of uses of %s mismatch; expected 2, got %d", x.Ident(), len(uses))
+	}
+	for i, use := range uses {
+		if use.User != y || use.Index != i {
+			t.Errorf("use %d of %s mismatch; expected operand %d of %s, got operand %d of %v", i, x.Ident(), i, y.Ident(), use.Index, use.User)
+		}
+	}
+	if users := um.Users(x); len(users) != 1 || users[0] != y {
+		t.Errorf("users of %s mismatch; expected [%s], got %v", x.Ident(), y.Ident(), users)
+	}
+
+	// Uses of global, within a global initializer and a constant expression.
+	users := um.Users(g)
+	if len(users) != 2 || users[0] != p || users[1] != z {
+		t.Errorf("users of %s mismatch; expected [%s %s], got %v", g.Ident(), p.Ident(), z.Ident(), users)
+	}
+
+	// Uses of instructions and basic blocks as operands of a phi instruction.
+	for _, c := range []struct {
+		uses  []*irutil.Use
+		user  interface{}
+		index int
+	}{
+		{uses: um.Uses(y), user: w, index: 0},
+		{uses: um.Uses(z), user: w, index: 1},
+		{uses: um.Uses(entry), user: w, index: 2},
+		{uses: um.Uses(a), user: w, index: 3},
+		{uses: um.Uses(w), user: b.Term, index: 0},
+	} {
+		// The last use is the use by the user.
+		if len(c.uses) == 0 {
+			t.Errorf("uses mismatch; expected operand %d of %v, got none", c.index, c.user)
+			continue
+		}
+		use := c.uses[len(c.uses)-1]
+		if use.User != c.user || use.Index != c.index {
+			t.Errorf("use mismatch; expected operand %d of %v, got operand %d of %v", c.index, c.user, use.Index, use.User)
+		}
+	}
+
+	// Uses of basic block as branch targets.
+	if users := um.Users(b); len(users) != 2 || users[0] != entry.Term || users[1] != a.Term {
+		t.Errorf("users of %s mismatch; expected [%v %v], got %v", b.Ident(), entry.Term, a.Term, users)
+	}
+
+	// Invalidate use map after modification.
+	b.Term.(*ir.TermRet).X = y
+	if uses := um.Uses(w); len(uses) != 1 {
+		t.Errorf("number of cached uses of %s mismatch; expected 1, got %d", w.Ident(), len(uses))
+	}
+	um.Invalidate()
+	if uses := um.Uses(w); len(uses) != 0 {
+		t.Errorf("number of uses of %s mismatch; expected 0, got %d", w.Ident(), len(uses))
+	}
+}
+
+func TestFuncUseMap(t *testing.T) {
+	m, err := asm.ParseString(useSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, f := m.Globals[0], m.Funcs[0]
+	um := irutil.NewFuncUseMap(f)
+	// Uses outside of the function are not recorded.
+	if users := um.Users(g); len(users) != 1 || users[0] != f.Blocks[0].Insts[1] {
+		t.Errorf("users of %s mismatch; expected [%s], got %v", g.Ident(), "%z", users)
+	}
+}
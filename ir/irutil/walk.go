@@ -58,6 +58,8 @@ func WalkFuncBeforeAfter(f *ir.Function, before, after func(interface{})) {
 	// Traverse child nodes of function, instead of f directly, as *ir.Function
 	// nodes are not traversed when staying within the scope of the function.
 	w.walkBeforeAfter(&f.Sig, before, after)
+	// Materialize lazily parsed function body.
+	f.MustMaterialize()
 	if f.Blocks != nil {
 		w.walkBeforeAfter(&f.Blocks, before, after)
 	}
@@ -155,12 +157,16 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(*n, before, after)
 	case **constant.Array:
 		w.walkBeforeAfter(*n, before, after)
+	case **constant.CharArray:
+		w.walkBeforeAfter(*n, before, after)
 	case **constant.Struct:
 		w.walkBeforeAfter(*n, before, after)
 	case **constant.ZeroInitializer:
 		w.walkBeforeAfter(*n, before, after)
 	case **constant.Undef:
 		w.walkBeforeAfter(*n, before, after)
+	case **constant.Poison:
+		w.walkBeforeAfter(*n, before, after)
 	// Constant expressions
 	case **constant.ExprAdd:
 		w.walkBeforeAfter(*n, before, after)
@@ -336,6 +342,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(*n, before, after)
 	case **ir.InstSelect:
 		w.walkBeforeAfter(*n, before, after)
+	case **ir.InstFreeze:
+		w.walkBeforeAfter(*n, before, after)
 	case **ir.InstCall:
 		w.walkBeforeAfter(*n, before, after)
 	// Terminators
@@ -407,6 +415,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		}
 	case *ir.Function:
 		w.walkBeforeAfter(&n.Sig, before, after)
+		// Materialize lazily parsed function body.
+		n.MustMaterialize()
 		if n.Blocks != nil {
 			w.walkBeforeAfter(&n.Blocks, before, after)
 		}
@@ -471,6 +481,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		if n.Elems != nil {
 			w.walkBeforeAfter(&n.Elems, before, after)
 		}
+	case *constant.CharArray:
+		w.walkBeforeAfter(&n.Typ, before, after)
 	case *constant.Struct:
 		w.walkBeforeAfter(&n.Typ, before, after)
 		if n.Fields != nil {
@@ -480,6 +492,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Typ, before, after)
 	case *constant.Undef:
 		w.walkBeforeAfter(&n.Typ, before, after)
+	case *constant.Poison:
+		w.walkBeforeAfter(&n.Typ, before, after)
 	// Constant expressions
 	case *constant.ExprAdd:
 		w.walkBeforeAfter(&n.X, before, after)
@@ -544,6 +558,7 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Elem, before, after)
 		w.walkBeforeAfter(&n.Index, before, after)
 	case *constant.ExprShuffleVector:
+		w.walkBeforeAfter(&n.Typ, before, after)
 		w.walkBeforeAfter(&n.X, before, after)
 		w.walkBeforeAfter(&n.Y, before, after)
 		w.walkBeforeAfter(&n.Mask, before, after)
@@ -689,6 +704,7 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Elem, before, after)
 		w.walkBeforeAfter(&n.Index, before, after)
 	case *ir.InstShuffleVector:
+		w.walkBeforeAfter(&n.Typ, before, after)
 		w.walkBeforeAfter(&n.X, before, after)
 		w.walkBeforeAfter(&n.Y, before, after)
 		w.walkBeforeAfter(&n.Mask, before, after)
@@ -776,6 +792,8 @@ func (w *walker) walkBeforeAfter(x interface{}, before, after func(interface{}))
 		w.walkBeforeAfter(&n.Cond, before, after)
 		w.walkBeforeAfter(&n.X, before, after)
 		w.walkBeforeAfter(&n.Y, before, after)
+	case *ir.InstFreeze:
+		w.walkBeforeAfter(&n.X, before, after)
 	case *ir.InstCall:
 		w.walkBeforeAfter(&n.Callee, before, after)
 		w.walkBeforeAfter(&n.Sig, before, after)
@@ -9,13 +9,17 @@
 package ir
 
 import (
+	"bufio"
 	"bytes"
-	"fmt"
+	"io"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/datalayout"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
 )
 
 // A Module represents an LLVM IR module, which consists of top-level type
@@ -35,6 +39,19 @@ type Module struct {
 	NamedMetadata []*metadata.Named
 	// Metadata of the module.
 	Metadata []*metadata.Metadata
+	// Comments attached to the module and its entities (type definitions, global
+	// variables, functions, basic blocks, instructions, terminators, named
+	// metadata and metadata); or nil if not retained.
+	Comments map[interface{}]*Comments
+	// Source positions of the entities of the module (type definitions, global
+	// variables, functions, basic blocks, instructions, terminators, named
+	// metadata and metadata); or nil if not retained.
+	Positions map[interface{}]Position
+	// symtab is a symbol table of the global identifiers and type definitions
+	// of the module.
+	symtab symtab
+	// consts is the constant pool of the module; or nil if not yet used.
+	consts *constant.Pool
 }
 
 // NewModule returns a new LLVM IR module.
@@ -43,84 +60,183 @@ func NewModule() *Module {
 }
 
 // String returns the LLVM syntax representation of the module.
+//
+// If the body of a lazily parsed function could not be materialized, the
+// materialization error is written as a comment in place of the body.
 func (m *Module) String() string {
 	buf := &bytes.Buffer{}
+	w := newWriter(buf)
+	w.writeErrors = true
+	// Writes to buf never fail, and materialization errors are written.
+	_ = m.write(w)
+	return buf.String()
+}
+
+// WriteTo writes the LLVM syntax representation of the module to w. The number
+// of bytes written and any error encountered are returned.
+//
+// Contrary to String, the output is streamed to w one top-level entity at a
+// time, and errors are returned rather than causing a panic (e.g. when unable
+// to materialize the body of a lazily parsed function).
+func (m *Module) WriteTo(w io.Writer) (n int64, err error) {
+	return m.writeTo(w, nil)
+}
+
+// writeTo writes the LLVM syntax representation of the module to w, annotating
+// instructions with the given use counts if non-nil. The number of bytes
+// written and any error encountered are returned.
+func (m *Module) writeTo(w io.Writer, uses map[value.Named]int) (n int64, err error) {
+	cw := newWriter(w)
+	bw := bufio.NewWriter(cw)
+	mw := newWriter(bw)
+	mw.uses = uses
+	if err := m.write(mw); err != nil {
+		return cw.n, err
+	}
+	if mw.err != nil {
+		return cw.n, mw.err
+	}
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// write writes the LLVM syntax representation of the module to w. An error is
+// returned if unable to materialize the body of a lazily parsed function,
+// unless materialization errors are written to w (see writer.writeErrors).
+func (m *Module) write(w *writer) error {
+	mc := m.comments(m)
+	if mc != nil {
+		writeLines(w, mc.Leading, "")
+	}
 	if len(m.DataLayout) > 0 {
-		fmt.Fprintf(buf, "target datalayout = %q\n", m.DataLayout)
+		w.Printf("target datalayout = %q\n", m.DataLayout)
 	}
 	if len(m.TargetTriple) > 0 {
-		fmt.Fprintf(buf, "target triple = %q\n", m.TargetTriple)
+		w.Printf("target triple = %q\n", m.TargetTriple)
 	}
 	for _, typ := range m.Types {
-		if len(buf.Bytes()) > 0 {
-			buf.WriteString("\n")
-		}
+		c := m.writeLeading(w, typ)
 		name := enc.Local(typ.GetName())
-		fmt.Fprintf(buf, "%s = type %s\n", name, typ.Def())
+		w.Printf("%s = type %s", name, typ.Def())
+		writeTrailing(w, c)
+		w.WriteString("\n")
 	}
 	for _, global := range m.Globals {
-		if len(buf.Bytes()) > 0 {
-			buf.WriteString("\n")
-		}
-		fmt.Fprintln(buf, global)
+		c := m.writeLeading(w, global)
+		global.writeText(w)
+		writeTrailing(w, c)
+		w.WriteString("\n")
 	}
 	for _, f := range m.Funcs {
-		if len(buf.Bytes()) > 0 {
-			buf.WriteString("\n")
+		err := f.renumberIDs()
+		if err != nil && !w.writeErrors {
+			return err
+		}
+		c := m.writeLeading(w, f)
+		if err != nil {
+			f.writeError(w, err)
+		} else {
+			f.write(w)
 		}
-		fmt.Fprintln(buf, f)
+		writeTrailing(w, c)
+		w.WriteString("\n")
 	}
 	for _, md := range m.NamedMetadata {
-		if len(buf.Bytes()) > 0 {
-			buf.WriteString("\n")
-		}
+		c := m.writeLeading(w, md)
 		name := enc.Metadata(md.Name)
-		fmt.Fprintf(buf, "%s = %s\n", name, md.Def())
+		w.Printf("%s = %s", name, md.Def())
+		writeTrailing(w, c)
+		w.WriteString("\n")
 	}
 	for _, md := range m.Metadata {
-		if len(buf.Bytes()) > 0 {
-			buf.WriteString("\n")
-		}
+		c := m.writeLeading(w, md)
 		id := enc.Metadata(md.ID)
-		fmt.Fprintf(buf, "%s = %s\n", id, md.Def())
+		w.Printf("%s = %s", id, md.Def())
+		writeTrailing(w, c)
+		w.WriteString("\n")
 	}
-	return buf.String()
+	if mc != nil {
+		writeLines(w, mc.Footer, "")
+	}
+	return nil
+}
+
+// writeLeading writes the leading comments of the given top-level entity to w,
+// and returns the comments of the entity. If the entity has no attached
+// comments, a blank line is written to separate it from the preceding entity.
+func (m *Module) writeLeading(w *writer, v interface{}) *Comments {
+	c := m.comments(v)
+	if c != nil {
+		writeLines(w, c.Leading, "")
+	} else if w.n > 0 {
+		w.WriteString("\n")
+	}
+	return c
+}
+
+// Layout returns the data layout of the module, as parsed from its data layout
+// string. The default data layout is returned if unspecified.
+func (m *Module) Layout() (*datalayout.DataLayout, error) {
+	return datalayout.Parse(m.DataLayout)
+}
+
+// ConstantPool returns the constant pool of the module, which maps structurally
+// equal constants to canonical instances. The pool is created on first use.
+func (m *Module) ConstantPool() *constant.Pool {
+	if m.consts == nil {
+		m.consts = constant.NewPool()
+	}
+	return m.consts
 }
 
 // AppendFunction appends the given function to the module.
 func (m *Module) AppendFunction(f *Function) {
 	f.Parent = m
 	m.Funcs = append(m.Funcs, f)
+	m.indexGlobal(f)
 }
 
 // NewType appends a new type definition to the module based on the given type
-// name and underlying type definition.
+// name and underlying type definition. A numeric suffix is appended to the type
+// name if already present (e.g. "T.1").
 func (m *Module) NewType(name string, typ types.Type) types.Type {
-	typ.SetName(name)
+	// Naming the new type definition leaves the type names of the module
+	// intact.
+	gen := rename.Types.Generation()
+	typ.SetName(m.uniqueTypeName(name))
 	m.Types = append(m.Types, typ)
+	m.indexType(typ, gen)
 	return typ
 }
 
 // NewGlobalDecl appends a new external global variable declaration to the
-// module based on the given global variable name and content type.
+// module based on the given global variable name and content type. A numeric
+// suffix is appended to the global variable name if already present (e.g.
+// "x.1").
 func (m *Module) NewGlobalDecl(name string, content types.Type) *Global {
-	global := NewGlobalDecl(name, content)
+	global := NewGlobalDecl(m.uniqueGlobalName(name), content)
 	m.Globals = append(m.Globals, global)
+	m.indexGlobal(global)
 	return global
 }
 
 // NewGlobalDef appends a new global variable definition to the module based on
-// the given global variable name and initial value.
+// the given global variable name and initial value. A numeric suffix is
+// appended to the global variable name if already present (e.g. "x.1").
 func (m *Module) NewGlobalDef(name string, init constant.Constant) *Global {
-	global := NewGlobalDef(name, init)
+	global := NewGlobalDef(m.uniqueGlobalName(name), init)
 	m.Globals = append(m.Globals, global)
+	m.indexGlobal(global)
 	return global
 }
 
 // NewFunction appends a new function to the module based on the given function
-// name, return type and parameters.
+// name, return type and parameters. A numeric suffix is appended to the
+// function name if already present (e.g. "f.1").
 func (m *Module) NewFunction(name string, ret types.Type, params ...*types.Param) *Function {
-	f := NewFunction(name, ret, params...)
+	f := NewFunction(m.uniqueGlobalName(name), ret, params...)
 	m.AppendFunction(f)
 	return f
 }
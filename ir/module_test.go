@@ -0,0 +1,118 @@
+package ir_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestModuleWriteTo(t *testing.T) {
+	m := newLargeModule(10, 10)
+	want := m.String()
+	buf := &bytes.Buffer{}
+	n, err := m.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("module mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("number of bytes written mismatch; expected %d, got %d", len(want), n)
+	}
+	// Write errors are reported.
+	w := &limitedWriter{limit: 100}
+	if _, err := m.WriteTo(w); err != errLimit {
+		t.Errorf("write error mismatch; expected %v, got %v", errLimit, err)
+	}
+	// Materialization errors are reported.
+	errMaterialize := errors.New("materialization failed")
+	m.Funcs[0].SetMaterializer(func(f *ir.Function) error {
+		return errMaterialize
+	})
+	if _, err := m.WriteTo(ioutil.Discard); err == nil {
+		t.Errorf("expected materialization error, got nil")
+	}
+}
+
+func BenchmarkModuleString(b *testing.B) {
+	m := newLargeModule(100, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.String()
+	}
+}
+
+func BenchmarkModuleWriteTo(b *testing.B) {
+	m := newLargeModule(100, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.WriteTo(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkModuleInstStrings prints the instructions and terminators of the
+// module through their String methods, for comparison with
+// BenchmarkModuleWriteTo which writes them without intermediate strings.
+func BenchmarkModuleInstStrings(b *testing.B) {
+	m := newLargeModule(100, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, f := range m.Funcs {
+			for _, block := range f.Blocks {
+				for _, inst := range block.Insts {
+					io.WriteString(ioutil.Discard, inst.String())
+				}
+				io.WriteString(ioutil.Discard, block.Term.String())
+			}
+		}
+	}
+}
+
+// newLargeModule returns a new module with the given number of functions, each
+// of which contains the given number of instructions.
+func newLargeModule(nfuncs, ninsts int) *ir.Module {
+	m := ir.NewModule()
+	g := m.NewGlobalDef("g", constant.NewInt(0, types.I64))
+	for i := 0; i < nfuncs; i++ {
+		f := m.NewFunction(fmt.Sprintf("f%d", i), types.I64, ir.NewParam("x", types.I64))
+		b := ir.NewBuilder(f.NewBlock("entry"))
+		var x = b.NewLoad(g)
+		for j := 0; j < ninsts; j++ {
+			x = b.NewLoad(g)
+			b.NewStore(b.NewAdd(x, f.Params()[0]), g)
+		}
+		b.NewRet(x)
+	}
+	return m
+}
+
+// errLimit is returned by limitedWriter when exceeding its limit.
+var errLimit = errors.New("write limit exceeded")
+
+// limitedWriter is an io.Writer which fails after writing limit bytes.
+type limitedWriter struct {
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errLimit
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
@@ -0,0 +1,25 @@
+// === [ Source positions ] ====================================================
+
+package ir
+
+import "fmt"
+
+// Position represents the source position of an LLVM IR entity, as retained
+// when parsing LLVM IR assembly (see asm.ParseOptions.KeepPositions).
+type Position struct {
+	// File name; or empty if not known.
+	FileName string
+	// Line number, starting at 1.
+	Line int
+	// Column number in bytes, starting at 1.
+	Column int
+}
+
+// String returns the string representation of the source position, in the
+// form "file:line:column", or "line:column" if the file name is not known.
+func (pos Position) String() string {
+	if len(pos.FileName) == 0 {
+		return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", pos.FileName, pos.Line, pos.Column)
+}
@@ -0,0 +1,612 @@
+// === [ Printer options ] =====================================================
+
+package ir
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// PrintOptions specifies the output style of the LLVM syntax representation
+// of modules. The zero value specifies the output style of Module.String.
+//
+// Printing with options does not modify the module; local names are assigned
+// to a copy of the module (see Module.Clone), and named types are inlined by
+// substituting unnamed copies of their type definitions within the copy.
+type PrintOptions struct {
+	// RenumberLocals specifies whether to discard the names of function
+	// parameters, basic blocks and local variables, and number them
+	// canonically in order of occurrence.
+	RenumberLocals bool
+	// ReadableNames specifies whether to assign readable names to unnamed
+	// function parameters, basic blocks and local variables (e.g. "arg",
+	// "bb.1", "add.2") instead of local IDs. When combined with
+	// RenumberLocals, every local is given a readable name.
+	ReadableNames bool
+	// InlineNamedTypes specifies whether to print named types structurally
+	// (e.g. "{ i32, i8* }") rather than by name (e.g. "%T"). The type
+	// definitions of inlined types are omitted. Recursive types and opaque
+	// struct types are always printed by name.
+	InlineNamedTypes bool
+	// UseCounts specifies whether to annotate instructions producing values
+	// with a trailing comment holding the number of uses of the value within
+	// its function (e.g. "; uses = 2").
+	UseCounts bool
+	// Sort specifies whether to sort type definitions, global variables,
+	// functions and named metadata by name, so that the output is independent
+	// of the order in which entities were added to the module.
+	Sort bool
+}
+
+// StringOptions returns the LLVM syntax representation of the module, using
+// the output style specified by opts.
+func (m *Module) StringOptions(opts PrintOptions) string {
+	buf := &bytes.Buffer{}
+	if _, err := m.WriteToOptions(buf, opts); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// WriteToOptions writes the LLVM syntax representation of the module to w,
+// using the output style specified by opts. The number of bytes written and
+// any error encountered are returned.
+func (m *Module) WriteToOptions(w io.Writer, opts PrintOptions) (n int64, err error) {
+	if opts == (PrintOptions{}) {
+		return m.WriteTo(w)
+	}
+	// Materialize lazily parsed function bodies before copying the module.
+	for _, f := range m.Funcs {
+		if err := f.Materialize(); err != nil {
+			return 0, errors.Errorf("unable to materialize body of function %s; %v", f.Ident(), err)
+		}
+	}
+	orig := m
+	m = m.Clone(nil)
+	for _, f := range m.Funcs {
+		if err := f.renumberIDs(); err != nil {
+			return 0, err
+		}
+	}
+	if opts.InlineNamedTypes {
+		inlineNamedTypes(orig, m)
+	}
+	if opts.Sort {
+		sortEntities(m)
+	}
+	for _, f := range m.Funcs {
+		if opts.RenumberLocals {
+			clearLocalNames(f)
+		}
+		if opts.ReadableNames {
+			assignReadableNames(f)
+		}
+	}
+	var uses map[value.Named]int
+	if opts.UseCounts {
+		uses = make(map[value.Named]int)
+		for _, f := range m.Funcs {
+			countUses(f, uses)
+		}
+	}
+	return m.writeTo(w, uses)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// sortEntities sorts the type definitions, global variables, functions and
+// named metadata of the module by name.
+func sortEntities(m *Module) {
+	sort.SliceStable(m.Types, func(i, j int) bool {
+		return m.Types[i].GetName() < m.Types[j].GetName()
+	})
+	sort.SliceStable(m.Globals, func(i, j int) bool {
+		return m.Globals[i].Name < m.Globals[j].Name
+	})
+	sort.SliceStable(m.Funcs, func(i, j int) bool {
+		return m.Funcs[i].Name < m.Funcs[j].Name
+	})
+	sort.SliceStable(m.NamedMetadata, func(i, j int) bool {
+		return m.NamedMetadata[i].Name < m.NamedMetadata[j].Name
+	})
+}
+
+// clearLocalNames discards the names of the function parameters, basic blocks
+// and local variables of the given function.
+func clearLocalNames(f *Function) {
+	for _, n := range locals(f) {
+		n.SetName("")
+	}
+}
+
+// assignReadableNames assigns readable names to the unnamed function
+// parameters, basic blocks and local variables of the given function. Local
+// IDs are considered unnamed.
+func assignReadableNames(f *Function) {
+	ns := locals(f)
+	inUse := make(map[string]bool)
+	for _, n := range ns {
+		if name := n.GetName(); !isUnnamed(name) && !isLocalID(name) {
+			inUse[name] = true
+		}
+	}
+	for _, n := range ns {
+		if name := n.GetName(); !isUnnamed(name) && !isLocalID(name) {
+			continue
+		}
+		name := uniqueName(readableName(n), func(name string) bool {
+			return inUse[name]
+		})
+		inUse[name] = true
+		n.SetName(name)
+	}
+}
+
+// readableName returns a readable base name of the given unnamed function
+// parameter, basic block or local variable.
+func readableName(n value.Named) string {
+	switch n.(type) {
+	case *types.Param:
+		return "arg"
+	case *BasicBlock:
+		return "bb"
+	default:
+		// Use the opcode of the instruction (e.g. "add" of *ir.InstAdd).
+		name := reflect.TypeOf(n).Elem().Name()
+		return strings.ToLower(strings.TrimPrefix(name, "Inst"))
+	}
+}
+
+// locals returns the function parameters, basic blocks and local variables of
+// the given function definition, in order of occurrence.
+func locals(f *Function) []value.Named {
+	if len(f.Blocks) == 0 {
+		return nil
+	}
+	var ns []value.Named
+	for _, param := range f.Params() {
+		ns = append(ns, param)
+	}
+	for _, block := range f.Blocks {
+		ns = append(ns, block)
+		for _, inst := range block.Insts {
+			if n, ok := inst.(value.Named); ok && !types.IsVoid(n.Type()) {
+				ns = append(ns, n)
+			}
+		}
+	}
+	return ns
+}
+
+// inlineNamedTypes substitutes the non-recursive type definitions of the
+// module copy m by unnamed copies, so that they are printed structurally, and
+// removes their type definitions from m. Opaque struct types are kept as well.
+// The types, constants and metadata of orig, which are shared with m, are left
+// unmodified.
+func inlineNamedTypes(orig, m *Module) {
+	ti := &typeInliner{
+		inlined: make(map[string]bool),
+		tmap:    make(map[types.Type]types.Type),
+		cmap:    make(map[constant.Constant]constant.Constant),
+		mdmap:   make(map[*metadata.Metadata]*metadata.Metadata),
+		globals: make(map[constant.Constant]constant.Constant),
+	}
+	var defs []types.Type
+	for _, typ := range m.Types {
+		if s, ok := typ.(*types.StructType); ok && s.Opaque || isRecursive(typ) {
+			defs = append(defs, typ)
+			continue
+		}
+		ti.inlined[typ.GetName()] = true
+	}
+	m.Types = nil
+	for _, typ := range defs {
+		newType := ti.typ(typ)
+		m.Types = append(m.Types, newType)
+		m.rekey(typ, newType)
+	}
+	// Map references from shared metadata to the copied global variables and
+	// functions.
+	for i, global := range orig.Globals {
+		ti.globals[global] = m.Globals[i]
+	}
+	for i, f := range orig.Funcs {
+		ti.globals[f] = m.Funcs[i]
+	}
+	for _, global := range m.Globals {
+		ti.fields(global)
+		if global.Init != nil {
+			global.Init = ti.constant(global.Init)
+		}
+	}
+	for _, f := range m.Funcs {
+		// Substitute types of the function signature in place, as the function
+		// parameters are referred to by the instructions of the function.
+		f.Sig.Ret = ti.typ(f.Sig.Ret)
+		for _, param := range f.Sig.Params {
+			param.Typ = ti.typ(param.Typ)
+		}
+		ti.attachments(f.Metadata)
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				ti.inst(inst)
+			}
+			if block.Term != nil {
+				ti.inst(block.Term)
+			}
+		}
+	}
+	for i, md := range m.Metadata {
+		newMD := ti.metadata(md)
+		m.Metadata[i] = newMD
+		m.rekey(md, newMD)
+	}
+}
+
+// typeInliner substitutes inlined named types by unnamed copies within a copy
+// of a module. Types, constants and metadata are copied on substitution, as
+// they are shared with the original module.
+type typeInliner struct {
+	// inlined specifies the names of the type definitions to inline. Named
+	// types are identified by name, as distinct type values may share the same
+	// type name (e.g. when parsed).
+	inlined map[string]bool
+	// tmap maps from original types to their substituted types.
+	tmap map[types.Type]types.Type
+	// cmap maps from original constants to their substituted constants.
+	cmap map[constant.Constant]constant.Constant
+	// mdmap maps from original metadata to their substituted metadata.
+	mdmap map[*metadata.Metadata]*metadata.Metadata
+	// globals maps from the global variables and functions of the original
+	// module to their copies.
+	globals map[constant.Constant]constant.Constant
+}
+
+// typ returns the substituted type of t.
+func (ti *typeInliner) typ(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	if u, ok := ti.tmap[t]; ok {
+		return u
+	}
+	if !ti.refersInlined(t) {
+		ti.tmap[t] = t
+		return t
+	}
+	var u types.Type
+	switch t := t.(type) {
+	case *types.VoidType:
+		c := *t
+		u = &c
+	case *types.FuncType:
+		c := *t
+		c.Params = append([]*types.Param(nil), t.Params...)
+		u = &c
+	case *types.IntType:
+		c := *t
+		u = &c
+	case *types.FloatType:
+		c := *t
+		u = &c
+	case *types.PointerType:
+		c := *t
+		u = &c
+	case *types.VectorType:
+		c := *t
+		u = &c
+	case *types.LabelType:
+		c := *t
+		u = &c
+	case *types.MetadataType:
+		c := *t
+		u = &c
+	case *types.ArrayType:
+		c := *t
+		u = &c
+	case *types.StructType:
+		c := *t
+		c.Fields = append([]types.Type(nil), t.Fields...)
+		u = &c
+	default:
+		panic(fmt.Errorf("support for type %T not yet implemented", t))
+	}
+	if ti.isInlined(t) {
+		u.SetName("")
+	}
+	// Record the copy before substituting its contained types, as named types
+	// may refer to themselves.
+	ti.tmap[t] = u
+	switch u := u.(type) {
+	case *types.FuncType:
+		u.Ret = ti.typ(u.Ret)
+		for i, param := range u.Params {
+			u.Params[i] = types.NewParam(param.Name, ti.typ(param.Typ))
+		}
+	case *types.PointerType:
+		u.Elem = ti.typ(u.Elem)
+	case *types.VectorType:
+		u.Elem = ti.typ(u.Elem)
+	case *types.ArrayType:
+		u.Elem = ti.typ(u.Elem)
+	case *types.StructType:
+		for i, field := range u.Fields {
+			u.Fields[i] = ti.typ(field)
+		}
+	}
+	return u
+}
+
+// refersInlined reports whether the given type is, or refers to, an inlined
+// type definition.
+func (ti *typeInliner) refersInlined(t types.Type) bool {
+	visited := make(map[types.Type]bool)
+	var refers func(u types.Type) bool
+	refers = func(u types.Type) bool {
+		if ti.isInlined(u) {
+			return true
+		}
+		if visited[u] {
+			return false
+		}
+		visited[u] = true
+		for _, elem := range elemTypes(u) {
+			if refers(elem) {
+				return true
+			}
+		}
+		return false
+	}
+	return refers(t)
+}
+
+// isInlined reports whether the given type is an inlined named type.
+func (ti *typeInliner) isInlined(t types.Type) bool {
+	name := t.GetName()
+	return len(name) > 0 && ti.inlined[name]
+}
+
+// constant returns the substituted constant of c.
+func (ti *typeInliner) constant(c constant.Constant) constant.Constant {
+	if mapped, ok := ti.cmap[c]; ok {
+		return mapped
+	}
+	newConst := ti.substConstant(c)
+	ti.cmap[c] = newConst
+	return newConst
+}
+
+// substConstant returns a copy of c with substituted types and operands, or c
+// itself if unchanged.
+func (ti *typeInliner) substConstant(c constant.Constant) constant.Constant {
+	switch c.(type) {
+	case *Global, *Function:
+		if mapped, ok := ti.globals[c]; ok {
+			return mapped
+		}
+		return c
+	}
+	user, ok := c.(interface {
+		Operands() []*constant.Constant
+	})
+	if !ok {
+		// Shallow copy of simple constant.
+		newConst := reflect.New(reflect.TypeOf(c).Elem())
+		newConst.Elem().Set(reflect.ValueOf(c).Elem())
+		if !ti.fields(newConst.Interface()) {
+			return c
+		}
+		return newConst.Interface().(constant.Constant)
+	}
+	var newOperands []constant.Constant
+	changed := false
+	for _, operand := range user.Operands() {
+		newOperand := ti.constant(*operand)
+		if newOperand != *operand {
+			changed = true
+		}
+		newOperands = append(newOperands, newOperand)
+	}
+	newConst := cloneConstant(c)
+	if ti.fields(newConst) {
+		changed = true
+	}
+	if !changed {
+		return c
+	}
+	for i, operand := range newConst.(interface {
+		Operands() []*constant.Constant
+	}).Operands() {
+		*operand = newOperands[i]
+	}
+	return newConst
+}
+
+// inst substitutes the types, constant operands and attached metadata of the
+// given copied instruction or terminator in place.
+func (ti *typeInliner) inst(inst Instruction) {
+	ti.fields(inst)
+	for _, operand := range inst.Operands() {
+		if c, ok := (*operand).(constant.Constant); ok {
+			*operand = ti.constant(c)
+		}
+	}
+	if term, ok := inst.(*TermSwitch); ok {
+		for _, c := range term.Cases {
+			c.X = ti.constant(c.X).(*constant.Int)
+		}
+	}
+}
+
+// fields substitutes the types and attached metadata held by the exported
+// fields of the struct pointed to by x in place. It reports whether any type
+// was substituted.
+func (ti *typeInliner) fields(x interface{}) bool {
+	v := reflect.ValueOf(x).Elem()
+	changed := false
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() || isNil(field) {
+			continue
+		}
+		switch t := field.Interface().(type) {
+		case types.Type:
+			if u := ti.typ(t); u != t {
+				field.Set(reflect.ValueOf(u))
+				changed = true
+			}
+		case map[string]*metadata.Metadata:
+			ti.attachments(t)
+		}
+	}
+	return changed
+}
+
+// attachments substitutes the given attached metadata of a copied entity in
+// place.
+func (ti *typeInliner) attachments(mds map[string]*metadata.Metadata) {
+	for key, md := range mds {
+		mds[key] = ti.metadata(md)
+	}
+}
+
+// metadata returns the substituted metadata of md.
+func (ti *typeInliner) metadata(md *metadata.Metadata) *metadata.Metadata {
+	if mapped, ok := ti.mdmap[md]; ok {
+		return mapped
+	}
+	// Record the copy before substituting its nodes, as metadata may refer to
+	// itself.
+	newMD := &metadata.Metadata{ID: md.ID, Nodes: make([]metadata.Node, len(md.Nodes))}
+	ti.mdmap[md] = newMD
+	changed := false
+	for i, node := range md.Nodes {
+		newNode := node
+		switch n := node.(type) {
+		case *metadata.Metadata:
+			newNode = ti.metadata(n)
+		case *metadata.Value:
+			if c, ok := n.X.(constant.Constant); ok {
+				if newConst := ti.constant(c); newConst != c {
+					newNode = &metadata.Value{X: newConst}
+				}
+			}
+		case constant.Constant:
+			newNode = ti.constant(n).(metadata.Node)
+		}
+		if newNode != node {
+			changed = true
+		}
+		newMD.Nodes[i] = newNode
+	}
+	if !changed {
+		// Metadata referred to by ID is printed by ID; keep the copy for cyclic
+		// references only.
+		ti.mdmap[md] = md
+		return md
+	}
+	return newMD
+}
+
+// rekey moves the comments and source position of the given original entity
+// of the module copy to its substituted entity.
+func (m *Module) rekey(oldKey, newKey interface{}) {
+	if oldKey == newKey {
+		return
+	}
+	if c, ok := m.Comments[oldKey]; ok {
+		delete(m.Comments, oldKey)
+		m.Comments[newKey] = c
+	}
+	if pos, ok := m.Positions[oldKey]; ok {
+		delete(m.Positions, oldKey)
+		m.Positions[newKey] = pos
+	}
+}
+
+// isNil reports whether the given pointer, interface or map value is nil.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// isRecursive reports whether the given type refers to itself.
+func isRecursive(t types.Type) bool {
+	visited := make(map[types.Type]bool)
+	var refers func(u types.Type) bool
+	refers = func(u types.Type) bool {
+		for _, elem := range elemTypes(u) {
+			if elem == t {
+				return true
+			}
+			if visited[elem] {
+				continue
+			}
+			visited[elem] = true
+			if refers(elem) {
+				return true
+			}
+		}
+		return false
+	}
+	return refers(t)
+}
+
+// elemTypes returns the types directly contained within the given type.
+func elemTypes(t types.Type) []types.Type {
+	switch t := t.(type) {
+	case *types.PointerType:
+		return []types.Type{t.Elem}
+	case *types.VectorType:
+		return []types.Type{t.Elem}
+	case *types.ArrayType:
+		return []types.Type{t.Elem}
+	case *types.StructType:
+		return t.Fields
+	case *types.FuncType:
+		ts := []types.Type{t.Ret}
+		for _, param := range t.Params {
+			ts = append(ts, param.Typ)
+		}
+		return ts
+	default:
+		return nil
+	}
+}
+
+// countUses adds the number of uses of the local variables of the given
+// function to uses.
+func countUses(f *Function, uses map[value.Named]int) {
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			countOperandUses(inst.Operands(), uses)
+		}
+		if block.Term != nil {
+			countOperandUses(block.Term.Operands(), uses)
+		}
+	}
+}
+
+// countOperandUses adds the uses of local variables by the given operands to
+// uses.
+func countOperandUses(operands []*value.Value, uses map[value.Named]int) {
+	for _, operand := range operands {
+		if n, ok := (*operand).(value.Named); ok {
+			uses[n]++
+		}
+	}
+}
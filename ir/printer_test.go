@@ -0,0 +1,164 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+)
+
+const printerSrc = `
+%T = type { i32, i8* }
+%list = type { i32, %list* }
+
+@y = global %T zeroinitializer
+@x = global %list zeroinitializer
+
+define i32 @g(%T* %p) {
+entry:
+	%a = getelementptr %T, %T* %p, i32 0, i32 0
+	%0 = load i32, i32* %a
+	%1 = add i32 %0, %0
+	br label %2
+; <label>:2
+	ret i32 %1
+}
+
+define void @f(i32) {
+	ret void
+}
+`
+
+func TestPrintOptions(t *testing.T) {
+	golden := []struct {
+		opts ir.PrintOptions
+		want string
+	}{
+		{
+			opts: ir.PrintOptions{Sort: true, RenumberLocals: true},
+			want: `%T = type { i32, i8* }
+
+%list = type { i32, %list* }
+
+@x = global %list zeroinitializer
+
+@y = global %T zeroinitializer
+
+define void @f(i32) {
+; <label>:1
+	ret void
+}
+
+define i32 @g(%T*) {
+; <label>:1
+	%2 = getelementptr %T, %T* %0, i32 0, i32 0
+	%3 = load i32, i32* %2
+	%4 = add i32 %3, %3
+	br label %5
+; <label>:5
+	ret i32 %4
+}
+`,
+		},
+		{
+			opts: ir.PrintOptions{ReadableNames: true, InlineNamedTypes: true, UseCounts: true},
+			want: `%list = type { i32, %list* }
+
+@y = global { i32, i8* } zeroinitializer
+
+@x = global %list zeroinitializer
+
+define i32 @g({ i32, i8* }* %p) {
+entry:
+	%a = getelementptr { i32, i8* }, { i32, i8* }* %p, i32 0, i32 0 ; uses = 1
+	%load = load i32, i32* %a ; uses = 2
+	%add = add i32 %load, %load ; uses = 1
+	br label %bb
+bb:
+	ret i32 %add
+}
+
+define void @f(i32 %arg) {
+bb:
+	ret void
+}
+`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString(printerSrc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := m.String()
+		if got := m.StringOptions(g.opts); got != g.want {
+			t.Errorf("output mismatch for options %+v; expected\n%s\ngot\n%s", g.opts, g.want, got)
+		}
+		// The module is left unmodified.
+		if got := m.String(); got != want {
+			t.Errorf("module modified by printing with options %+v; expected\n%s\ngot\n%s", g.opts, want, got)
+		}
+	}
+}
+
+func TestPrintOptionsInlineNamedTypes(t *testing.T) {
+	const src = `
+%T = type { i32, i8* }
+%node = type { %T, %node* }
+
+@x = global %T { i32 1, i8* null }
+@y = global %node* null
+@z = global i32* getelementptr (%T, %T* @x, i64 0, i32 0)
+
+!md = !{!0}
+!0 = !{%T zeroinitializer}
+`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := m.String()
+	const wantInlined = `%node = type { { i32, i8* }, %node* }
+
+@x = global { i32, i8* } { i32 1, i8* null }
+
+@y = global %node* null
+
+@z = global i32* getelementptr ({ i32, i8* }, { i32, i8* }* @x, i64 0, i32 0)
+
+!md = !{!0}
+
+!0 = !{{ i32, i8* } zeroinitializer}
+`
+	if got := m.StringOptions(ir.PrintOptions{InlineNamedTypes: true}); got != wantInlined {
+		t.Errorf("output mismatch; expected\n%s\ngot\n%s", wantInlined, got)
+	}
+	// The type definitions of the module are left unmodified.
+	if got := m.String(); got != want {
+		t.Errorf("module modified by printing; expected\n%s\ngot\n%s", want, got)
+	}
+	if typ := m.NamedType("T"); typ == nil || typ.GetName() != "T" {
+		t.Errorf("type definition mismatch; expected %%T, got %v", typ)
+	}
+}
+
+func TestPrintOptionsLocalIDs(t *testing.T) {
+	m := ir.NewModule()
+	param := ir.NewParam("", types.I32)
+	f := m.NewFunction("f", types.I32, param)
+	block := f.NewBlock("")
+	block.NewRet(param)
+	const want = `define i32 @f(i32 %arg) {
+bb:
+	ret i32 %arg
+}
+`
+	if got := m.StringOptions(ir.PrintOptions{ReadableNames: true}); got != want {
+		t.Errorf("output mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	// Local IDs are assigned to the copy of the module only.
+	if param.Name != "" || block.Name != "" {
+		t.Errorf("local names assigned to module; expected unnamed locals, got %q and %q", param.Name, block.Name)
+	}
+}
@@ -0,0 +1,156 @@
+// === [ Replace all uses ] ====================================================
+
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+// ReplaceAllUsesWith replaces all uses of old with new within the global
+// variable initializers and function bodies of the module, including uses
+// within constant expressions.
+//
+// If old is a basic block, new must also be a basic block, and the targets of
+// terminators and the predecessors of phi instructions are updated.
+func (m *Module) ReplaceAllUsesWith(old, new value.Value) {
+	for _, global := range m.Globals {
+		if global.Init != nil {
+			global.Init, _ = replaceConstant(global.Init, old, new)
+		}
+	}
+	for _, f := range m.Funcs {
+		f.ReplaceAllUsesWith(old, new)
+	}
+}
+
+// ReplaceAllUsesWith replaces all uses of old with new within the function
+// body, including uses within constant expressions.
+//
+// If old is a basic block, new must also be a basic block, and the targets of
+// terminators and the predecessors of phi instructions are updated.
+func (f *Function) ReplaceAllUsesWith(old, new value.Value) {
+	// Materialize lazily parsed function body.
+	f.MustMaterialize()
+	oldBlock, isBlock := old.(*BasicBlock)
+	var newBlock *BasicBlock
+	if isBlock {
+		b, ok := new.(*BasicBlock)
+		if !ok {
+			panic(fmt.Errorf("invalid replacement of basic block %s; expected *ir.BasicBlock, got %T", oldBlock.Ident(), new))
+		}
+		newBlock = b
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			replaceOperands(inst, old, new)
+			if phi, ok := inst.(*InstPhi); ok && isBlock {
+				for _, inc := range phi.Incs {
+					if inc.Pred == oldBlock {
+						inc.Pred = newBlock
+					}
+				}
+			}
+		}
+		if block.Term != nil {
+			replaceOperands(block.Term, old, new)
+			if isBlock {
+				replaceTargets(block.Term, oldBlock, newBlock)
+			}
+		}
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// replaceOperands replaces the value operands of the given instruction equal
+// to old with new, including uses within constant expressions.
+func replaceOperands(inst Instruction, old, new value.Value) {
+	for _, operand := range inst.Operands() {
+		if *operand == old {
+			*operand = new
+			continue
+		}
+		if c, ok := (*operand).(constant.Constant); ok {
+			if newConst, ok := replaceConstant(c, old, new); ok {
+				*operand = newConst
+			}
+		}
+	}
+}
+
+// replaceConstant returns the given constant with the operands (or the
+// operands of its nested constant expressions and aggregate constants) equal
+// to old replaced with new. Constant expressions and aggregate constants are
+// copied rather than modified in place, as they may be shared with other users.
+// The boolean return value indicates whether a replacement took place.
+func replaceConstant(c constant.Constant, old, new value.Value) (constant.Constant, bool) {
+	if c == old {
+		v, ok := new.(constant.Constant)
+		if !ok {
+			panic(fmt.Errorf("invalid replacement of %s within constant; expected constant.Constant, got %T", old.Ident(), new))
+		}
+		return v, true
+	}
+	// Global variables and functions are not traversed, as their operands
+	// are not constant operands.
+	user, ok := c.(interface {
+		Operands() []*constant.Constant
+	})
+	if !ok {
+		return c, false
+	}
+	var newOperands []constant.Constant
+	replaced := false
+	for _, operand := range user.Operands() {
+		newOperand, ok := replaceConstant(*operand, old, new)
+		if ok {
+			replaced = true
+		}
+		newOperands = append(newOperands, newOperand)
+	}
+	if !replaced {
+		return c, false
+	}
+	newConst := cloneConstant(c)
+	for i, operand := range newConst.(interface {
+		Operands() []*constant.Constant
+	}).Operands() {
+		*operand = newOperands[i]
+	}
+	return newConst, true
+}
+
+// replaceTargets replaces the targets of the given terminator equal to old
+// with new, and updates its successor basic blocks.
+func replaceTargets(term Terminator, old, new *BasicBlock) {
+	switch term := term.(type) {
+	case *TermBr:
+		if term.Target == old {
+			term.Target = new
+		}
+		term.Successors = []*BasicBlock{term.Target}
+	case *TermCondBr:
+		if term.TargetTrue == old {
+			term.TargetTrue = new
+		}
+		if term.TargetFalse == old {
+			term.TargetFalse = new
+		}
+		term.Successors = []*BasicBlock{term.TargetTrue, term.TargetFalse}
+	case *TermSwitch:
+		if term.TargetDefault == old {
+			term.TargetDefault = new
+		}
+		successors := []*BasicBlock{term.TargetDefault}
+		for _, c := range term.Cases {
+			if c.Target == old {
+				c.Target = new
+			}
+			successors = append(successors, c.Target)
+		}
+		term.Successors = successors
+	}
+}
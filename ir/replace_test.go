@@ -0,0 +1,101 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestReplaceAllUsesWith(t *testing.T) {
+	const src = `
+@g = global i32 0
+@h = global i32 1
+@p = global i32* getelementptr (i32, i32* @g, i64 1)
+
+define i32 @f(i32 %x, i32 %y, i1 %c) {
+entry:
+	%a = add i32 %x, %x
+	%b = load i32, i32* @g
+	br i1 %c, label %then, label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ %a, %entry ], [ %b, %then ]
+	ret i32 %r
+}
+`
+	const want = `@g = global i32 0
+
+@h = global i32 1
+
+@p = global i32* getelementptr (i32, i32* @h, i64 1)
+
+define i32 @f(i32 %x, i32 %y, i1 %c) {
+entry:
+	%a = add i32 %y, %y
+	%b = load i32, i32* @h
+	br i1 %c, label %exit, label %exit
+then:
+	br label %exit
+exit:
+	%r = phi i32 [ %a, %entry ], [ %b, %exit ]
+	ret i32 %r
+}
+`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, h := m.Globals[0], m.Globals[1]
+	f := m.Funcs[0]
+	params := f.Params()
+	entry, then, exit := f.Blocks[0], f.Blocks[1], f.Blocks[2]
+	m.ReplaceAllUsesWith(g, h)
+	f.ReplaceAllUsesWith(params[0], params[1])
+	f.ReplaceAllUsesWith(then, exit)
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	succs := entry.Term.Succs()
+	if len(succs) != 2 || succs[0] != exit || succs[1] != exit {
+		t.Errorf("successors mismatch; expected [%s %s], got %v", exit.Ident(), exit.Ident(), succs)
+	}
+}
+
+func TestReplaceAllUsesWithSharedConstant(t *testing.T) {
+	m := ir.NewModule()
+	a := m.NewGlobalDef("a", constant.NewInt(0, types.I32))
+	b := m.NewGlobalDef("b", constant.NewInt(1, types.I32))
+	addr := constant.NewPtrToInt(a, types.I64)
+	m.NewGlobalDef("p", addr)
+	f := m.NewFunction("f", types.I64)
+	f.NewBlock("entry").NewRet(addr)
+	g := m.NewFunction("g", types.I64)
+	g.NewBlock("entry").NewRet(addr)
+	f.ReplaceAllUsesWith(a, b)
+	const want = `@a = global i32 0
+
+@b = global i32 1
+
+@p = global i64 ptrtoint (i32* @a to i64)
+
+define i64 @f() {
+entry:
+	ret i64 ptrtoint (i32* @b to i64)
+}
+
+define i64 @g() {
+entry:
+	ret i64 ptrtoint (i32* @a to i64)
+}
+`
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected\n%s\ngot\n%s", want, got)
+	}
+	if addr.From != a {
+		t.Errorf("shared constant expression modified; expected operand %s, got %s", a.Ident(), addr.From.Ident())
+	}
+}
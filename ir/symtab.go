@@ -0,0 +1,418 @@
+// === [ Symbol tables ] =======================================================
+
+package ir
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/llir/llvm/internal/rename"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Func returns the function of the module with the given name, or nil if not
+// present.
+func (m *Module) Func(name string) *Function {
+	f, _ := m.lookupGlobal(name).(*Function)
+	return f
+}
+
+// Global returns the global variable of the module with the given name, or nil
+// if not present.
+func (m *Module) Global(name string) *Global {
+	global, _ := m.lookupGlobal(name).(*Global)
+	return global
+}
+
+// NamedType returns the type definition of the module with the given type name,
+// or nil if not present.
+func (m *Module) NamedType(name string) types.Type {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	return m.findType(name)
+}
+
+// Block returns the basic block of the function with the given label name, or
+// nil if not present.
+func (f *Function) Block(name string) *BasicBlock {
+	block, _ := f.Local(name).(*BasicBlock)
+	return block
+}
+
+// Local returns the function parameter, basic block or local variable of the
+// function with the given name, or nil if not present or if the body of the
+// function could not be materialized.
+func (f *Function) Local(name string) value.Named {
+	// Materialize lazily parsed function body.
+	if err := f.Materialize(); err != nil {
+		return nil
+	}
+	f.locals.mu.Lock()
+	defer f.locals.mu.Unlock()
+	return f.findLocal(name)
+}
+
+// symtab is a symbol table of the global identifiers and type definitions of a
+// module.
+//
+// The symbol table is built on first lookup, and maintained as entities are
+// appended to the module through its methods (e.g. NewFunction) or renamed. It
+// is rebuilt when the number of global variables, functions or type
+// definitions of the module changes otherwise, or when entities which may not
+// be located through a parent are renamed (see package internal/rename). As
+// entities may have been renamed by assigning their name directly, the names
+// of located entities are validated, and the symbol table is rebuilt if stale.
+type symtab struct {
+	// mu prevents races on the symbol table.
+	mu sync.Mutex
+	// globals maps from global identifiers to global variables and functions.
+	globals map[string]value.Named
+	// Number of indexed global variables and functions.
+	nglobals, nfuncs int
+	// Rename generation of the indexed global variables and functions.
+	globalsGen uint64
+	// types maps from type names to type definitions.
+	types map[string]types.Type
+	// Number of indexed type definitions.
+	ntypes int
+	// Rename generation of the indexed type definitions.
+	typesGen uint64
+	// materializers maps from lazily parsed functions of the module to the
+	// functions used to materialize their bodies (see
+	// Function.SetMaterializer). Materializers are kept alongside the symbol
+	// table, rather than in the function, as they are located through the
+	// module.
+	materializers map[*Function]func(f *Function) error
+}
+
+// localSymtab is a local symbol table of the function parameters, basic blocks
+// and local variables of a function. It is maintained like the symbol table of
+// a module, based on the number of function parameters and basic blocks.
+type localSymtab struct {
+	// mu prevents races on the local symbol table.
+	mu sync.Mutex
+	// names maps from local identifiers to the named function parameters, basic
+	// blocks and local variables of the function; or nil if not yet indexed.
+	names map[string]value.Named
+	// Number of indexed function parameters and basic blocks.
+	nparams, nblocks int
+	// Rename generation of the indexed locals.
+	gen uint64
+}
+
+// lookupGlobal returns the global variable or function of the module with the
+// given name, or nil if not present.
+func (m *Module) lookupGlobal(name string) value.Named {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	return m.findGlobal(name)
+}
+
+// findGlobal returns the global variable or function of the module with the
+// given name, or nil if not present. The caller must hold the lock of the
+// symbol table.
+func (m *Module) findGlobal(name string) value.Named {
+	st := &m.symtab
+	if st.globals == nil || st.nglobals != len(m.Globals) || st.nfuncs != len(m.Funcs) || st.globalsGen != rename.Globals.Generation() {
+		m.indexGlobals()
+	}
+	v, ok := st.globals[name]
+	if !ok {
+		return nil
+	}
+	if v.GetName() != name {
+		// Reindex global variables and functions renamed without SetName.
+		m.indexGlobals()
+		return st.globals[name]
+	}
+	return v
+}
+
+// findType returns the type definition of the module with the given type name,
+// or nil if not present. The caller must hold the lock of the symbol table.
+func (m *Module) findType(name string) types.Type {
+	st := &m.symtab
+	if st.types == nil || st.ntypes != len(m.Types) || st.typesGen != rename.Types.Generation() {
+		m.indexTypes()
+	}
+	typ, ok := st.types[name]
+	if !ok {
+		return nil
+	}
+	if typ.GetName() != name {
+		// Reindex type definitions renamed without SetName.
+		m.indexTypes()
+		return st.types[name]
+	}
+	return typ
+}
+
+// findLocal returns the function parameter, basic block or local variable of
+// the function with the given name, or nil if not present. The caller must
+// hold the lock of the local symbol table.
+func (f *Function) findLocal(name string) value.Named {
+	lt := &f.locals
+	if lt.names == nil || lt.nparams != len(f.Params()) || lt.nblocks != len(f.Blocks) || lt.gen != rename.Locals.Generation() {
+		f.indexLocals()
+	}
+	v, ok := lt.names[name]
+	if !ok {
+		return nil
+	}
+	if v.GetName() != name {
+		// Reindex locals renamed without SetName.
+		f.indexLocals()
+		return lt.names[name]
+	}
+	return v
+}
+
+// uniqueGlobalName returns a global identifier based on the given name, which
+// is unique within the module. A numeric suffix is appended to the name (e.g.
+// "foo.1") if already present.
+func (m *Module) uniqueGlobalName(name string) string {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	return uniqueName(name, func(name string) bool {
+		return m.findGlobal(name) != nil
+	})
+}
+
+// uniqueTypeName returns a type name based on the given name, which is unique
+// within the module. A numeric suffix is appended to the name (e.g. "T.1") if
+// already present.
+func (m *Module) uniqueTypeName(name string) string {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	return uniqueName(name, func(name string) bool {
+		return m.findType(name) != nil
+	})
+}
+
+// uniqueLocalName returns a local identifier based on the given name, which is
+// unique within the function. A numeric suffix is appended to the name (e.g.
+// "loop.1") if already present. The empty name of unnamed locals is returned
+// as is.
+func (f *Function) uniqueLocalName(name string) string {
+	if isUnnamed(name) {
+		return name
+	}
+	f.locals.mu.Lock()
+	defer f.locals.mu.Unlock()
+	return uniqueName(name, func(name string) bool {
+		return f.findLocal(name) != nil
+	})
+}
+
+// indexGlobal adds the given global variable or function, which was just
+// appended to the module, to the symbol table of the module.
+func (m *Module) indexGlobal(v value.Named) {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	st := &m.symtab
+	// Only update a symbol table which is otherwise up to date.
+	if st.globals == nil || st.nglobals+st.nfuncs+1 != len(m.Globals)+len(m.Funcs) {
+		return
+	}
+	st.globals[v.GetName()] = v
+	st.nglobals = len(m.Globals)
+	st.nfuncs = len(m.Funcs)
+}
+
+// indexType adds the given type definition, which was just appended to the
+// module, to the symbol table of the module. gen is the rename generation of
+// types before naming the type definition.
+func (m *Module) indexType(typ types.Type, gen uint64) {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	st := &m.symtab
+	// Only update a symbol table which is otherwise up to date.
+	if st.types == nil || st.ntypes+1 != len(m.Types) {
+		return
+	}
+	st.types[typ.GetName()] = typ
+	st.ntypes = len(m.Types)
+	// Retain the rename generation of the symbol table, unless other types were
+	// renamed in the meantime.
+	if st.typesGen == gen && rename.Types.Generation() == gen+1 {
+		st.typesGen = gen + 1
+	}
+}
+
+// indexLocal adds the given function parameter or basic block, which was just
+// appended to the function, to the local symbol table of the function. The
+// local variables of basic blocks are added likewise.
+func (f *Function) indexLocal(v value.Named) {
+	f.locals.mu.Lock()
+	defer f.locals.mu.Unlock()
+	lt := &f.locals
+	// Only update a symbol table which is otherwise up to date.
+	if lt.names == nil || lt.nparams+lt.nblocks+1 != len(f.Params())+len(f.Blocks) {
+		return
+	}
+	lt.add(v)
+	if block, ok := v.(*BasicBlock); ok {
+		for _, inst := range block.Insts {
+			if n, ok := inst.(value.Named); ok {
+				lt.add(n)
+			}
+		}
+	}
+	lt.nparams = len(f.Params())
+	lt.nblocks = len(f.Blocks)
+}
+
+// indexInst adds the given instruction, which was just inserted into the basic
+// block, to the local symbol table of the parent function of the basic block,
+// if any.
+func indexInst(block *BasicBlock, inst Instruction) {
+	n, ok := inst.(value.Named)
+	if !ok || block.Parent == nil {
+		return
+	}
+	f := block.Parent
+	f.locals.mu.Lock()
+	defer f.locals.mu.Unlock()
+	if f.locals.names != nil {
+		f.locals.add(n)
+	}
+}
+
+// renameGlobal updates the symbol table of the module after renaming the given
+// global variable or function, previously named oldName.
+func (m *Module) renameGlobal(v value.Named, oldName string) {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	st := &m.symtab
+	if st.globals == nil {
+		return
+	}
+	if st.globals[oldName] == v {
+		delete(st.globals, oldName)
+	}
+	st.globals[v.GetName()] = v
+}
+
+// renameLocal updates the local symbol table of the function after renaming
+// the given basic block or local variable, previously named oldName.
+func (f *Function) renameLocal(v value.Named, oldName string) {
+	f.locals.mu.Lock()
+	defer f.locals.mu.Unlock()
+	lt := &f.locals
+	if lt.names == nil {
+		return
+	}
+	if lt.names[oldName] == v {
+		delete(lt.names, oldName)
+	}
+	lt.add(v)
+}
+
+// renameLocal updates the local symbol table of the parent function of the
+// given basic block after renaming the given local variable, previously named
+// oldName. The basic block may be nil.
+func renameLocal(block *BasicBlock, v value.Named, oldName string) {
+	if block == nil || block.Parent == nil {
+		rename.Locals.Renamed()
+		return
+	}
+	block.Parent.renameLocal(v, oldName)
+}
+
+// indexGlobals indexes the global variables and functions of the module.
+func (m *Module) indexGlobals() {
+	st := &m.symtab
+	st.globalsGen = rename.Globals.Generation()
+	st.globals = make(map[string]value.Named)
+	for _, global := range m.Globals {
+		st.globals[global.Name] = global
+	}
+	for _, f := range m.Funcs {
+		st.globals[f.Name] = f
+	}
+	st.nglobals = len(m.Globals)
+	st.nfuncs = len(m.Funcs)
+}
+
+// indexTypes indexes the type definitions of the module.
+func (m *Module) indexTypes() {
+	st := &m.symtab
+	st.typesGen = rename.Types.Generation()
+	st.types = make(map[string]types.Type)
+	for _, typ := range m.Types {
+		st.types[typ.GetName()] = typ
+	}
+	st.ntypes = len(m.Types)
+}
+
+// indexLocals indexes the named function parameters, basic blocks and local
+// variables of the function.
+func (f *Function) indexLocals() {
+	lt := &f.locals
+	lt.gen = rename.Locals.Generation()
+	lt.names = make(map[string]value.Named)
+	for _, param := range f.Params() {
+		lt.add(param)
+	}
+	for _, block := range f.Blocks {
+		lt.add(block)
+		for _, inst := range block.Insts {
+			if n, ok := inst.(value.Named); ok {
+				lt.add(n)
+			}
+		}
+	}
+	lt.nparams = len(f.Params())
+	lt.nblocks = len(f.Blocks)
+}
+
+// add adds the given named local to the local symbol table. Unnamed locals are
+// ignored.
+func (lt *localSymtab) add(v value.Named) {
+	if name := v.GetName(); !isUnnamed(name) {
+		lt.names[name] = v
+	}
+}
+
+// materializer returns the function used to materialize the body of the given
+// function of the module, or nil if already present.
+func (m *Module) materializer(f *Function) func(f *Function) error {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	return m.symtab.materializers[f]
+}
+
+// setMaterializer sets the function used to materialize the body of the given
+// function of the module. A nil materializer indicates that the body of the
+// function is already present.
+func (m *Module) setMaterializer(f *Function, materialize func(f *Function) error) {
+	m.symtab.mu.Lock()
+	defer m.symtab.mu.Unlock()
+	st := &m.symtab
+	if materialize == nil {
+		delete(st.materializers, f)
+		return
+	}
+	if st.materializers == nil {
+		st.materializers = make(map[*Function]func(f *Function) error)
+	}
+	st.materializers[f] = materialize
+}
+
+// ### [ Helper functions ] ####################################################
+
+// uniqueName returns a name based on the given name which is not in use, as
+// reported by inUse. A numeric suffix is appended to the name (e.g. "foo.1") if
+// already in use.
+func uniqueName(name string, inUse func(name string) bool) string {
+	if !inUse(name) {
+		return name
+	}
+	for i := 1; ; i++ {
+		newName := name + "." + strconv.Itoa(i)
+		if !inUse(newName) {
+			return newName
+		}
+	}
+}
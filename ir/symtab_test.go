@@ -0,0 +1,146 @@
+package ir_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestModuleLookup(t *testing.T) {
+	const src = `
+%T = type { i32 }
+
+@g = global i32 0
+
+define i32 @main() {
+entry:
+	%x = load i32, i32* @g
+	ret i32 %x
+}
+`
+	m, err := asm.ParseString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := m.Func("main"); f != m.Funcs[0] {
+		t.Errorf("function mismatch; expected %v, got %v", m.Funcs[0].Ident(), f)
+	}
+	if global := m.Global("g"); global != m.Globals[0] {
+		t.Errorf("global variable mismatch; expected %v, got %v", m.Globals[0].Ident(), global)
+	}
+	if typ := m.NamedType("T"); typ != m.Types[0] {
+		t.Errorf("type definition mismatch; expected %v, got %v", m.Types[0], typ)
+	}
+	if f := m.Func("g"); f != nil {
+		t.Errorf("unexpected function %v", f.Ident())
+	}
+	if global := m.Global("h"); global != nil {
+		t.Errorf("unexpected global variable %v", global.Ident())
+	}
+	// Locate renamed entities.
+	m.Globals[0].SetName("h")
+	if global := m.Global("g"); global != nil {
+		t.Errorf("unexpected global variable %v", global.Ident())
+	}
+	if global := m.Global("h"); global != m.Globals[0] {
+		t.Errorf("global variable mismatch; expected %v, got %v", m.Globals[0].Ident(), global)
+	}
+
+	// Locals.
+	f := m.Funcs[0]
+	entry := f.Blocks[0]
+	if block := f.Block("entry"); block != entry {
+		t.Errorf("basic block mismatch; expected %v, got %v", entry.Ident(), block)
+	}
+	if v := f.Local("x"); v != entry.Insts[0].(value.Named) {
+		t.Errorf("local variable mismatch; expected %%x, got %v", v)
+	}
+	if block := f.Block("x"); block != nil {
+		t.Errorf("unexpected basic block %v", block.Ident())
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	m := ir.NewModule()
+	g1 := m.NewGlobalDef("x", constant.NewInt(1, types.I32))
+	g2 := m.NewGlobalDef("x", constant.NewInt(2, types.I32))
+	f := m.NewFunction("x", types.Void)
+	typ1 := m.NewType("T", types.NewStruct())
+	typ2 := m.NewType("T", types.NewStruct())
+	for _, c := range []struct {
+		got, want string
+	}{
+		{got: g1.Name, want: "x"},
+		{got: g2.Name, want: "x.1"},
+		{got: f.Name, want: "x.2"},
+		{got: typ1.GetName(), want: "T"},
+		{got: typ2.GetName(), want: "T.1"},
+	} {
+		if c.got != c.want {
+			t.Errorf("name mismatch; expected %q, got %q", c.want, c.got)
+		}
+	}
+	if global := m.Global("x.1"); global != g2 {
+		t.Errorf("global variable mismatch; expected %v, got %v", g2.Ident(), global)
+	}
+	p := f.NewParam("a", types.I32)
+	b1 := f.NewBlock("a")
+	b2 := f.NewBlock("a")
+	b3 := f.NewBlock("")
+	for _, c := range []struct {
+		got, want string
+	}{
+		{got: p.Name, want: "a"},
+		{got: b1.Name, want: "a.1"},
+		{got: b2.Name, want: "a.2"},
+		{got: b3.Name, want: ""},
+	} {
+		if c.got != c.want {
+			t.Errorf("name mismatch; expected %q, got %q", c.want, c.got)
+		}
+	}
+	if block := f.Block("a.2"); block != b2 {
+		t.Errorf("basic block mismatch; expected %v, got %v", b2.Ident(), block)
+	}
+	// Names of entities renamed after being indexed are in use.
+	g1.SetName("y")
+	if g3 := m.NewGlobalDef("y", constant.NewInt(3, types.I32)); g3.Name != "y.1" {
+		t.Errorf("name mismatch; expected %q, got %q", "y.1", g3.Name)
+	}
+	typ1.SetName("U")
+	if typ3 := m.NewType("U", types.NewStruct()); typ3.GetName() != "U.1" {
+		t.Errorf("name mismatch; expected %q, got %q", "U.1", typ3.GetName())
+	}
+	b1.SetName("b")
+	if b4 := f.NewBlock("b"); b4.Name != "b.1" {
+		t.Errorf("name mismatch; expected %q, got %q", "b.1", b4.Name)
+	}
+	inst := b2.NewAlloca(types.I32)
+	inst.SetName("c")
+	if b5 := f.NewBlock("c"); b5.Name != "c.1" {
+		t.Errorf("name mismatch; expected %q, got %q", "c.1", b5.Name)
+	}
+	if v := f.Local("c"); v != inst {
+		t.Errorf("local variable mismatch; expected %v, got %v", inst.Ident(), v)
+	}
+}
+
+func TestLocalMaterializeError(t *testing.T) {
+	m := ir.NewModule()
+	f := m.NewFunction("f", types.Void)
+	f.SetMaterializer(func(f *ir.Function) error {
+		return errors.New("materialization failed")
+	})
+	// Locals of functions which could not be materialized are not present.
+	if v := f.Local("x"); v != nil {
+		t.Errorf("unexpected local %v", v.Ident())
+	}
+	if !f.IsMaterializable() {
+		t.Errorf("expected function %v to remain materializable", f.Ident())
+	}
+}
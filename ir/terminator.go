@@ -6,9 +6,6 @@
 package ir
 
 import (
-	"bytes"
-	"fmt"
-
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/value"
@@ -60,14 +57,18 @@ func NewRet(x value.Value) *TermRet {
 
 // String returns the LLVM syntax representation of the terminator.
 func (term *TermRet) String() string {
-	md := metadataString(term.Metadata, ",")
+	return textString(term)
+}
+
+// writeText writes the LLVM syntax representation of the terminator to w.
+func (term *TermRet) writeText(w *writer) {
+	w.WriteString("ret ")
 	if term.X != nil {
-		return fmt.Sprintf("ret %s %s%s",
-			term.X.Type(),
-			term.X.Ident(),
-			md)
+		w.typedIdent(term.X)
+	} else {
+		w.WriteString("void")
 	}
-	return fmt.Sprintf("ret void%s", md)
+	w.metadata(term.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the terminator.
@@ -80,6 +81,14 @@ func (term *TermRet) SetParent(parent *BasicBlock) {
 	term.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the terminator.
+func (term *TermRet) Operands() []*value.Value {
+	if term.X != nil {
+		return []*value.Value{&term.X}
+	}
+	return nil
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermRet) Succs() []*BasicBlock {
 	// ret terminators have no successors.
@@ -117,10 +126,14 @@ func NewBr(target *BasicBlock) *TermBr {
 
 // String returns the LLVM syntax representation of the terminator.
 func (term *TermBr) String() string {
-	md := metadataString(term.Metadata, ",")
-	return fmt.Sprintf("br label %s%s",
-		term.Target.Ident(),
-		md)
+	return textString(term)
+}
+
+// writeText writes the LLVM syntax representation of the terminator to w.
+func (term *TermBr) writeText(w *writer) {
+	w.WriteString("br label ")
+	w.ident(term.Target)
+	w.metadata(term.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the terminator.
@@ -133,6 +146,12 @@ func (term *TermBr) SetParent(parent *BasicBlock) {
 	term.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the terminator.
+func (term *TermBr) Operands() []*value.Value {
+	// br terminators have no value operands.
+	return nil
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermBr) Succs() []*BasicBlock {
 	return term.Successors
@@ -175,12 +194,18 @@ func NewCondBr(cond value.Value, targetTrue, targetFalse *BasicBlock) *TermCondB
 
 // String returns the LLVM syntax representation of the terminator.
 func (term *TermCondBr) String() string {
-	md := metadataString(term.Metadata, ",")
-	return fmt.Sprintf("br i1 %s, label %s, label %s%s",
-		term.Cond.Ident(),
-		term.TargetTrue.Ident(),
-		term.TargetFalse.Ident(),
-		md)
+	return textString(term)
+}
+
+// writeText writes the LLVM syntax representation of the terminator to w.
+func (term *TermCondBr) writeText(w *writer) {
+	w.WriteString("br i1 ")
+	w.ident(term.Cond)
+	w.WriteString(", label ")
+	w.ident(term.TargetTrue)
+	w.WriteString(", label ")
+	w.ident(term.TargetFalse)
+	w.metadata(term.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the terminator.
@@ -193,6 +218,11 @@ func (term *TermCondBr) SetParent(parent *BasicBlock) {
 	term.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the terminator.
+func (term *TermCondBr) Operands() []*value.Value {
+	return []*value.Value{&term.Cond}
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermCondBr) Succs() []*BasicBlock {
 	return term.Successors
@@ -241,20 +271,25 @@ func NewSwitch(x value.Value, targetDefault *BasicBlock, cases ...*Case) *TermSw
 
 // String returns the LLVM syntax representation of the terminator.
 func (term *TermSwitch) String() string {
-	cases := &bytes.Buffer{}
+	return textString(term)
+}
+
+// writeText writes the LLVM syntax representation of the terminator to w.
+func (term *TermSwitch) writeText(w *writer) {
+	w.WriteString("switch ")
+	w.typedIdent(term.X)
+	w.WriteString(", label ")
+	w.ident(term.TargetDefault)
+	w.WriteString(" [\n")
 	for _, c := range term.Cases {
-		fmt.Fprintf(cases, "\t\t%s %s, label %s\n",
-			c.X.Type(),
-			c.X.Ident(),
-			c.Target.Ident())
+		w.WriteString("\t\t")
+		w.typedIdent(c.X)
+		w.WriteString(", label ")
+		w.ident(c.Target)
+		w.WriteString("\n")
 	}
-	md := metadataString(term.Metadata, ",")
-	return fmt.Sprintf("switch %s %s, label %s [\n%s\t]%s",
-		term.X.Type(),
-		term.X.Ident(),
-		term.TargetDefault.Ident(),
-		cases,
-		md)
+	w.WriteString("\t]")
+	w.metadata(term.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the terminator.
@@ -267,6 +302,11 @@ func (term *TermSwitch) SetParent(parent *BasicBlock) {
 	term.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the terminator.
+func (term *TermSwitch) Operands() []*value.Value {
+	return []*value.Value{&term.X}
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermSwitch) Succs() []*BasicBlock {
 	return term.Successors
@@ -327,8 +367,13 @@ func NewUnreachable() *TermUnreachable {
 
 // String returns the LLVM syntax representation of the terminator.
 func (term *TermUnreachable) String() string {
-	md := metadataString(term.Metadata, ",")
-	return fmt.Sprintf("unreachable%s", md)
+	return textString(term)
+}
+
+// writeText writes the LLVM syntax representation of the terminator to w.
+func (term *TermUnreachable) writeText(w *writer) {
+	w.WriteString("unreachable")
+	w.metadata(term.Metadata, ",")
 }
 
 // GetParent returns the parent basic block of the terminator.
@@ -341,6 +386,12 @@ func (term *TermUnreachable) SetParent(parent *BasicBlock) {
 	term.Parent = parent
 }
 
+// Operands returns a mutable list of the operands of the terminator.
+func (term *TermUnreachable) Operands() []*value.Value {
+	// unreachable terminators have no operands.
+	return nil
+}
+
 // Succs returns the successor basic blocks of the terminator.
 func (term *TermUnreachable) Succs() []*BasicBlock {
 	// unreachable terminators have no successors.
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 )
 
 // --- [ array ] ---------------------------------------------------------------
@@ -64,6 +65,7 @@ func (t *ArrayType) GetName() string {
 // SetName sets the name of the type.
 func (t *ArrayType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // --- [ struct ] --------------------------------------------------------------
@@ -154,6 +156,7 @@ func (t *StructType) GetName() string {
 // SetName sets the name of the type.
 func (t *StructType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // Identified reports whether t is an identified struct type.
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 )
 
 // --- [ void ] ----------------------------------------------------------------
@@ -47,6 +48,7 @@ func (t *VoidType) GetName() string {
 // SetName sets the name of the type.
 func (t *VoidType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // --- [ function ] ------------------------------------------------------------
@@ -128,6 +130,7 @@ func (t *FuncType) GetName() string {
 // SetName sets the name of the type.
 func (t *FuncType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // NewParam appends a new function parameter to the function type based on the
@@ -173,6 +176,7 @@ func (param *Param) GetName() string {
 // SetName sets the name of the function parameter.
 func (param *Param) SetName(name string) {
 	param.Name = name
+	rename.Locals.Renamed()
 }
 
 // --- [ label ] ---------------------------------------------------------------
@@ -213,6 +217,7 @@ func (t *LabelType) GetName() string {
 // SetName sets the name of the type.
 func (t *LabelType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // --- [ metadata ] ------------------------------------------------------------
@@ -253,4 +258,5 @@ func (t *MetadataType) GetName() string {
 // SetName sets the name of the type.
 func (t *MetadataType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/internal/rename"
 )
 
 // --- [ integer ] -------------------------------------------------------------
@@ -58,6 +59,7 @@ func (t *IntType) GetName() string {
 // SetName sets the name of the type.
 func (t *IntType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // --- [ floating-point ] ------------------------------------------------------
@@ -102,6 +104,7 @@ func (t *FloatType) GetName() string {
 // SetName sets the name of the type.
 func (t *FloatType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // FloatKind represents the set of floating-point kinds.
@@ -188,6 +191,7 @@ func (t *PointerType) GetName() string {
 // SetName sets the name of the type.
 func (t *PointerType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
 
 // --- [ vector ] --------------------------------------------------------------
@@ -242,4 +246,5 @@ func (t *VectorType) GetName() string {
 // SetName sets the name of the type.
 func (t *VectorType) SetName(name string) {
 	t.Name = name
+	rename.Types.Renamed()
 }
@@ -0,0 +1,169 @@
+// === [ Output writer ] =======================================================
+
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// writer wraps an io.Writer to keep track of the number of bytes written and
+// the first error encountered while writing the LLVM syntax representation of
+// IR entities. Once an error has occurred, subsequent writes are no-ops.
+type writer struct {
+	// Underlying writer.
+	w io.Writer
+	// Number of bytes written.
+	n int64
+	// First error encountered; or nil if none.
+	err error
+	// Use counts of local variables, with which instructions are annotated; or
+	// nil if not annotated.
+	uses map[value.Named]int
+	// writeErrors reports whether errors encountered while materializing the
+	// body of lazily parsed functions are written as comments in place of the
+	// body, rather than returned.
+	writeErrors bool
+	// Scratch buffer used to format integers.
+	buf []byte
+}
+
+// newWriter returns a new writer which writes to w.
+func newWriter(w io.Writer) *writer {
+	return &writer{w: w}
+}
+
+// Write writes p to the underlying writer, unless a previous write failed.
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+// WriteString writes s to the underlying writer, unless a previous write
+// failed.
+func (w *writer) WriteString(s string) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := io.WriteString(w.w, s)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
+
+// Printf formats according to the given format specifier and writes to the
+// underlying writer, unless a previous write failed.
+func (w *writer) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(w, format, a...)
+}
+
+// --- [ Text writers ] --------------------------------------------------------
+
+// textWriter is implemented by instructions, terminators and global variables,
+// which write their LLVM syntax representation directly to a writer without
+// building intermediate strings.
+type textWriter interface {
+	// writeText writes the LLVM syntax representation of the entity to w.
+	writeText(w *writer)
+}
+
+// textString returns the LLVM syntax representation of the given entity.
+func textString(t textWriter) string {
+	buf := &strings.Builder{}
+	t.writeText(newWriter(buf))
+	return buf.String()
+}
+
+// text writes the LLVM syntax representation of the given entity to w.
+func (w *writer) text(v fmt.Stringer) {
+	if t, ok := v.(textWriter); ok {
+		t.writeText(w)
+		return
+	}
+	w.WriteString(v.String())
+}
+
+// ident writes the identifier of the given value to w. The identifiers of
+// global variables, functions, function parameters, basic blocks and local
+// variables are written without building intermediate strings.
+func (w *writer) ident(v value.Value) {
+	switch u := v.(type) {
+	case *Global:
+		w.name("@", u.Name)
+		return
+	case *Function:
+		w.name("@", u.Name)
+		return
+	case *types.Param:
+		w.name("%", u.Name)
+		return
+	case *BasicBlock:
+		w.name("%", u.Name)
+		return
+	case Instruction:
+		if n, ok := u.(value.Named); ok {
+			w.name("%", n.GetName())
+			return
+		}
+	}
+	w.WriteString(v.Ident())
+}
+
+// name writes the given name to w, prefixed by the given sigil (e.g. "%").
+func (w *writer) name(sigil, name string) {
+	w.WriteString(sigil)
+	w.WriteString(enc.EscapeIdent(name))
+}
+
+// typ writes the given type to w. Unnamed integer and pointer types are written
+// without building intermediate strings.
+func (w *writer) typ(t types.Type) {
+	switch t := t.(type) {
+	case *types.IntType:
+		if len(t.Name) == 0 {
+			w.buf = strconv.AppendInt(append(w.buf[:0], 'i'), int64(t.Size), 10)
+			w.Write(w.buf)
+			return
+		}
+	case *types.PointerType:
+		if len(t.Name) == 0 && t.AddrSpace == 0 {
+			w.typ(t.Elem)
+			w.WriteString("*")
+			return
+		}
+	}
+	w.WriteString(t.String())
+}
+
+// typedIdent writes the type and identifier of the given value to w, separated
+// by a space.
+func (w *writer) typedIdent(v value.Value) {
+	w.typ(v.Type())
+	w.WriteString(" ")
+	w.ident(v)
+}
+
+// int writes the given integer to w.
+func (w *writer) int(x int64) {
+	w.buf = strconv.AppendInt(w.buf[:0], x, 10)
+	w.Write(w.buf)
+}
+
+// metadata writes the given attached metadata to w, each entry preceded by sep.
+func (w *writer) metadata(m map[string]*metadata.Metadata, sep string) {
+	if len(m) > 0 {
+		w.WriteString(metadataString(m, sep))
+	}
+}
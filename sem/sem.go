@@ -242,7 +242,7 @@ func (sem *sem) checkConst(c constant.Constant) {
 	case *constant.Float:
 		// c.Typ is validated when later traversed.
 		// Validate floating-point value.
-		if c.X == nil {
+		if c.X == nil && !c.NaN {
 			sem.Errorf("floating-point constant value missing")
 		}
 	case *constant.Null:
@@ -270,14 +270,21 @@ func (sem *sem) checkConst(c constant.Constant) {
 		}
 		// Validate array element types.
 		want := c.Typ.Elem
-		if c.CharArray && !want.Equal(types.I8) {
-			sem.Errorf("invalid character array element type; expected `i8`, got `%v`", want)
-		}
 		for _, elem := range c.Elems {
 			if got := elem.Type(); !got.Equal(want) {
 				sem.Errorf("array element type `%v` and element type `%v` mismatch", want, got)
 			}
 		}
+	case *constant.CharArray:
+		// c.Typ is validated when later traversed.
+		// Validate number of array elements.
+		if c.Typ.Len != int64(len(c.X)) {
+			sem.Errorf("number of character array elements mismatch for type `%v`; expected %d, got %d", c.Typ, c.Typ.Len, len(c.X))
+		}
+		// Validate array element type.
+		if !c.Typ.Elem.Equal(types.I8) {
+			sem.Errorf("invalid character array element type; expected `i8`, got `%v`", c.Typ.Elem)
+		}
 	case *constant.Struct:
 		// c.Typ is validated when later traversed.
 		// Validate number of struct fields.
@@ -725,6 +732,8 @@ func (sem *sem) checkInst(inst ir.Instruction) {
 		panic("not yet implemented")
 	case *ir.InstSelect:
 		panic("not yet implemented")
+	case *ir.InstFreeze:
+		panic("not yet implemented")
 	case *ir.InstCall:
 		panic("not yet implemented")
 	default: